@@ -51,6 +51,7 @@ func main() {
 			commands.Connect(),
 			commands.Server(),
 			commands.Intercept(),
+			commands.SSHProxy(),
 		},
 	}
 
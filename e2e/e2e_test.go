@@ -26,6 +26,10 @@ func (s *E2ESuite) SetupSuite() {
 
 	s.ctx, s.cancel = context.WithTimeout(context.Background(), 5*time.Minute)
 
+	if _, err := selectRuntime(s.ctx); err != nil {
+		s.T().Skipf("skipping e2e test: %v", err)
+	}
+
 	var err error
 	s.sandbox, err = NewSandbox(s.ctx, SandboxConfig{
 		Command: []string{"bridge", "server", "--name", "test-sandbox"},
@@ -219,6 +223,109 @@ func (s *E2ESuite) TestMutagenSync() {
 	s.Contains(fileContent, "hello from devcontainer", "file content mismatch")
 }
 
+// TestMutagenSyncPreservesModesAndSymlinks stresses the sandbox's SFTP
+// subsystem with the tree shape that previously thrashed mutagen's
+// stat-driven reconciliation: symlinks and files at 0400/0700/0755 with
+// mtimes in the past. It asserts the sandbox's copy matches the
+// devcontainer's source after a single sync round.
+func (s *E2ESuite) TestMutagenSyncPreservesModesAndSymlinks() {
+	network, err := NewTestNetwork(s.ctx, "mutagen-modes-test")
+	s.Require().NoError(err, "failed to create network")
+	defer network.Terminate(s.ctx)
+
+	sandbox, err := NewSandbox(s.ctx, SandboxConfig{
+		Command: []string{"bridge", "server", "--name", "test-sandbox"},
+		Network: network.Name,
+	})
+	s.Require().NoError(err, "failed to create sandbox")
+	defer func() {
+		logs, _ := sandbox.Logs(s.ctx)
+		s.T().Logf("Sandbox logs:\n%s", logs)
+		sandbox.Terminate(s.ctx)
+	}()
+
+	sandboxIP, err := sandbox.ContainerIP(s.ctx, network.Name)
+	s.Require().NoError(err, "failed to get sandbox IP")
+
+	devcontainer, err := NewDevcontainer(s.ctx, DevcontainerConfig{
+		Network:    network.Name,
+		Privileged: true,
+	})
+	s.Require().NoError(err, "failed to create devcontainer")
+	defer func() {
+		logs, _ := devcontainer.Logs(s.ctx)
+		s.T().Logf("Devcontainer logs:\n%s", logs)
+		devcontainer.Terminate(s.ctx)
+	}()
+
+	sandboxURL := "http://" + sandboxIP + ":3000"
+	interceptCmd := []string{
+		"sh", "-c",
+		"bridge intercept " +
+			"--sandbox-url " + sandboxURL + " " +
+			"--function-url http://localhost:9999 " +
+			"--name test-sandbox " +
+			"> /tmp/intercept.log 2>&1 &",
+	}
+	exitCode, _, err := devcontainer.Exec(s.ctx, interceptCmd)
+	s.Require().NoError(err, "failed to start bridge intercept")
+	s.Require().Equal(0, exitCode, "failed to start bridge intercept")
+
+	// Build a tree with the mode/symlink/mtime combinations that used to
+	// make mutagen's reconciliation thrash against the sandbox's SFTP
+	// subsystem.
+	buildTreeCmd := []string{
+		"sh", "-c", `set -e
+			echo 'read-only' > readonly.txt
+			chmod 0400 readonly.txt
+			mkdir private-dir
+			echo 'private' > private-dir/secret.txt
+			chmod 0700 private-dir
+			chmod 0600 private-dir/secret.txt
+			echo '#!/bin/sh' > run.sh
+			chmod 0755 run.sh
+			ln -s run.sh run-link.sh
+			touch -d '2020-01-02 03:04:05' readonly.txt run.sh
+		`,
+	}
+	exitCode, output, err := devcontainer.Exec(s.ctx, buildTreeCmd)
+	s.Require().NoError(err, "failed to build source tree")
+	s.Require().Equal(0, exitCode, "failed to build source tree: %s", output)
+
+	// Wait for sync
+	time.Sleep(2 * time.Second)
+
+	_, interceptLogs, _ := devcontainer.Exec(s.ctx, []string{"cat", "/tmp/intercept.log"})
+	s.T().Logf("Intercept logs:\n%s", interceptLogs)
+
+	// Compare stat output for every path in the tree between source and
+	// target: mode bits and mtime must match exactly, and the symlink must
+	// still point at its original target.
+	statScript := `stat -c '%n %a %Y' readonly.txt run.sh private-dir private-dir/secret.txt && readlink run-link.sh`
+
+	exitCode, sandboxStat, err := sandbox.Exec(s.ctx, []string{"sh", "-c", "cd /vercel/sandbox && " + statScript})
+	s.Require().NoError(err, "failed to stat synced tree in sandbox")
+	s.Require().Equal(0, exitCode, "failed to stat synced tree in sandbox: %s", sandboxStat)
+
+	_, sourceStat, err := devcontainer.Exec(s.ctx, []string{"sh", "-c", statScript})
+	s.Require().NoError(err, "failed to stat source tree in devcontainer")
+
+	s.Equal(sourceStat, sandboxStat, "synced tree's modes/mtimes/symlink target diverged from source")
+}
+
+// TestManifestSandbox verifies that bridge create -f bridge.yaml's path
+// (pkg/admin/manifest) produces a running sandbox, by applying a fixture
+// manifest against a real cluster and waiting for the resulting pod via
+// kube.WaitForPod. It's skipped for now: unlike the rest of E2ESuite, which
+// only needs a container runtime, this path needs a real Kubernetes API server to apply
+// against, and this harness doesn't stand one up (e2e/testutil/kubeconfig.go
+// has rewriting helpers for a k3s container but nothing currently creates
+// one). Wiring that up is tracked separately; this stub keeps the gap
+// visible instead of silently omitting coverage.
+func (s *E2ESuite) TestManifestSandbox() {
+	s.T().Skip("no Kubernetes cluster available in this harness yet; see e2e/testutil/kubeconfig.go")
+}
+
 // TestE2ESuite runs the e2e test suite
 func TestE2ESuite(t *testing.T) {
 	suite.Run(t, new(E2ESuite))
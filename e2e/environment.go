@@ -28,6 +28,17 @@ type EnvironmentConfig struct {
 	AppPort int
 	// DevcontainerPrivileged runs the devcontainer in privileged mode for iptables (default: false)
 	DevcontainerPrivileged bool
+	// NetworkBackend is the packet-filtering backend bridge intercept should
+	// configure inside the devcontainer (default: IPTables). Passed through
+	// as BRIDGE_NETWORK_BACKEND so intercept can pick a backend per Scenario.
+	NetworkBackend NetworkBackend
+	// IPFamily is passed to the devcontainer as BRIDGE_IP_FAMILY so bridge
+	// intercept's rewriting covers IPv4, IPv6, or both (default: IPv4). The
+	// container network itself is created the same way for every family.
+	IPFamily IPFamily
+	// MutagenDisabled passes --no-sync to bridge intercept, skipping the
+	// mutagen sync session (default: false, i.e. sync is on).
+	MutagenDisabled bool
 }
 
 func (cfg *EnvironmentConfig) setDefaults() {
@@ -37,6 +48,12 @@ func (cfg *EnvironmentConfig) setDefaults() {
 	if cfg.AppPort == 0 {
 		cfg.AppPort = 3000
 	}
+	if cfg.NetworkBackend == "" {
+		cfg.NetworkBackend = IPTables
+	}
+	if cfg.IPFamily == "" {
+		cfg.IPFamily = IPv4
+	}
 }
 
 const (
@@ -126,6 +143,10 @@ func SetupEnvironment(ctx context.Context, cfg EnvironmentConfig) (*Environment,
 	devcontainer, err := NewDevcontainer(ctx, DevcontainerConfig{
 		Network:    network.Name,
 		Privileged: cfg.DevcontainerPrivileged,
+		Env: map[string]string{
+			"BRIDGE_NETWORK_BACKEND": string(cfg.NetworkBackend),
+			"BRIDGE_IP_FAMILY":       string(cfg.IPFamily),
+		},
 	})
 	if err != nil {
 		env.TearDown(ctx, nil)
@@ -134,6 +155,30 @@ func SetupEnvironment(ctx context.Context, cfg EnvironmentConfig) (*Environment,
 	env.Devcontainer = devcontainer
 
 	// 4. Start bridge intercept in the background.
+	if err := startIntercept(ctx, devcontainer, sandboxURL, functionURL, cfg); err != nil {
+		env.TearDown(ctx, nil)
+		return nil, err
+	}
+
+	// 5. Wait for bridge intercept to connect to the sandbox.
+	if err := waitForInterceptReady(ctx, env.Devcontainer); err != nil {
+		env.TearDown(ctx, nil)
+		return nil, fmt.Errorf("bridge intercept not ready: %w", err)
+	}
+
+	return env, nil
+}
+
+// startIntercept execs bridge intercept in the background inside dc, pointed
+// at sandboxURL/functionURL, honoring cfg.MutagenDisabled. It's shared by
+// SetupEnvironment and Fixture.AddDevcontainer so a Scenario's mutagen axis
+// is handled in exactly one place.
+func startIntercept(ctx context.Context, dc *DevcontainerContainer, sandboxURL, functionURL string, cfg EnvironmentConfig) error {
+	noSync := ""
+	if cfg.MutagenDisabled {
+		noSync = "--no-sync "
+	}
+
 	interceptCmd := []string{
 		"sh", "-c",
 		fmt.Sprintf(
@@ -142,31 +187,24 @@ func SetupEnvironment(ctx context.Context, cfg EnvironmentConfig) (*Environment,
 				"--function-url %s "+
 				"--name %s "+
 				"--app-port %d "+
+				"%s"+
 				"> /tmp/intercept.log 2>&1 &",
 			sandboxURL,
 			functionURL,
 			cfg.SandboxName,
 			cfg.AppPort,
+			noSync,
 		),
 	}
 
-	exitCode, _, err := devcontainer.Exec(ctx, interceptCmd)
+	exitCode, _, err := dc.Exec(ctx, interceptCmd)
 	if err != nil {
-		env.TearDown(ctx, nil)
-		return nil, fmt.Errorf("failed to exec bridge intercept: %w", err)
+		return fmt.Errorf("failed to exec bridge intercept: %w", err)
 	}
 	if exitCode != 0 {
-		env.TearDown(ctx, nil)
-		return nil, fmt.Errorf("bridge intercept exited with code %d", exitCode)
+		return fmt.Errorf("bridge intercept exited with code %d", exitCode)
 	}
-
-	// 5. Wait for bridge intercept to connect to the sandbox.
-	if err := waitForInterceptReady(ctx, env.Devcontainer); err != nil {
-		env.TearDown(ctx, nil)
-		return nil, fmt.Errorf("bridge intercept not ready: %w", err)
-	}
-
-	return env, nil
+	return nil
 }
 
 // waitForInterceptReady polls the bridge intercept log until it shows the tunnel is connected.
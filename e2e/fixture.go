@@ -0,0 +1,101 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// Fixture is a lazily-built Environment shared across every subtest under a
+// single top-level test, instead of each subtest standing up its own network
+// plus three containers. Building an Environment from scratch is what makes
+// broad E2E coverage slow, so a package that wants many small assertions
+// against the same topology should share one Fixture rather than calling
+// SetupEnvironment per case.
+//
+// Create a Fixture with NewFixture, call Get from each subtest to obtain the
+// shared Environment, and optionally AddDevcontainer to attach extra
+// devcontainers to the same sandbox for multi-client intercept scenarios.
+// Teardown happens automatically via t.Cleanup on the test passed to
+// NewFixture.
+type Fixture struct {
+	cfg EnvironmentConfig
+
+	mu    sync.Mutex
+	env   *Environment
+	err   error
+	extra []*DevcontainerContainer
+}
+
+// NewFixture registers a Fixture whose Environment is built lazily on the
+// first call to Get and torn down via t.Cleanup once t (and all its
+// subtests) finish.
+func NewFixture(t *testing.T, cfg EnvironmentConfig) *Fixture {
+	f := &Fixture{cfg: cfg}
+	t.Cleanup(func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for _, dc := range f.extra {
+			dc.Terminate(context.Background())
+		}
+		if f.env != nil {
+			f.env.TearDown(context.Background(), t)
+		}
+	})
+	return f
+}
+
+// Get returns the shared Environment, building it on the first call. Safe to
+// call concurrently from parallel subtests — later callers block until the
+// first build finishes and then share its result (or its error).
+func (f *Fixture) Get(ctx context.Context, t *testing.T) *Environment {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.env == nil && f.err == nil {
+		f.env, f.err = SetupEnvironment(ctx, f.cfg)
+	}
+	if f.err != nil {
+		t.Fatalf("failed to set up shared fixture: %v", f.err)
+	}
+	return f.env
+}
+
+// AddDevcontainer starts an additional devcontainer on the fixture's network
+// and points bridge intercept at the same sandbox, so a test can exercise
+// multiple concurrent intercept clients against one sandbox. It builds the
+// base Environment first if this is the first caller. The devcontainer is
+// torn down along with the rest of the fixture.
+func (f *Fixture) AddDevcontainer(ctx context.Context, t *testing.T) *DevcontainerContainer {
+	env := f.Get(ctx, t)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	dc, err := NewDevcontainer(ctx, DevcontainerConfig{
+		Network:    env.Network.Name,
+		Privileged: f.cfg.DevcontainerPrivileged,
+		Env: map[string]string{
+			"BRIDGE_NETWORK_BACKEND": string(f.cfg.NetworkBackend),
+			"BRIDGE_IP_FAMILY":       string(f.cfg.IPFamily),
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to start additional devcontainer: %v", err)
+	}
+
+	sandboxURL := fmt.Sprintf("http://%s:3000", sandboxAlias)
+	functionURL := fmt.Sprintf("http://%s:8080", dispatcherAlias)
+	if err := startIntercept(ctx, dc, sandboxURL, functionURL, f.cfg); err != nil {
+		dc.Terminate(ctx)
+		t.Fatalf("failed to start intercept in additional devcontainer: %v", err)
+	}
+	if err := waitForInterceptReady(ctx, dc); err != nil {
+		dc.Terminate(ctx)
+		t.Fatalf("additional devcontainer's intercept not ready: %v", err)
+	}
+
+	f.extra = append(f.extra, dc)
+	return dc
+}
@@ -4,33 +4,37 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/testcontainers/testcontainers-go"
-	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/vercel/bridge/e2e/testutil/runtime"
 )
 
-// TestNetwork represents a Docker network for e2e tests
+// TestNetwork represents a container network for e2e tests
 type TestNetwork struct {
-	Network *testcontainers.DockerNetwork
+	Network runtime.Network
 	Name    string
 }
 
-// NewTestNetwork creates a new Docker network for container-to-container communication
+// NewTestNetwork creates a new network for container-to-container communication
 func NewTestNetwork(ctx context.Context, name string) (*TestNetwork, error) {
-	net, err := network.New(ctx, network.WithDriver("bridge"))
+	rt, err := selectRuntime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	net, err := rt.CreateNetwork(ctx, name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create network: %w", err)
 	}
 
 	return &TestNetwork{
 		Network: net,
-		Name:    net.Name,
+		Name:    net.Name(),
 	}, nil
 }
 
 // Terminate removes the network
 func (n *TestNetwork) Terminate(ctx context.Context) error {
 	if n.Network != nil {
-		return n.Network.Remove(ctx)
+		return n.Network.Terminate(ctx)
 	}
 	return nil
 }
@@ -0,0 +1,24 @@
+package e2e
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vercel/bridge/e2e/testutil/runtime"
+)
+
+var (
+	runtimeOnce sync.Once
+	rt          runtime.Runtime
+	runtimeErr  error
+)
+
+// selectRuntime picks, and caches for the life of the process, the
+// container runtime the suite drives. BRIDGE_E2E_RUNTIME=docker|podman
+// forces a backend; by default it autodetects whichever is available.
+func selectRuntime(ctx context.Context) (runtime.Runtime, error) {
+	runtimeOnce.Do(func() {
+		rt, runtimeErr = runtime.Select(ctx)
+	})
+	return rt, runtimeErr
+}
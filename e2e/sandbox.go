@@ -8,9 +8,7 @@ import (
 	"path/filepath"
 	"time"
 
-	"github.com/testcontainers/testcontainers-go"
-	"github.com/testcontainers/testcontainers-go/exec"
-	"github.com/testcontainers/testcontainers-go/wait"
+	"github.com/vercel/bridge/e2e/testutil/runtime"
 )
 
 const (
@@ -20,7 +18,7 @@ const (
 
 // SandboxContainer represents a running sandbox container with the bridge CLI
 type SandboxContainer struct {
-	Container testcontainers.Container
+	Container runtime.Container
 	Host      string
 	Port      string
 }
@@ -33,7 +31,7 @@ type SandboxConfig struct {
 	Env map[string]string
 	// ExposedPorts are additional ports to expose
 	ExposedPorts []string
-	// Network is the Docker network to join
+	// Network is the container network to join
 	Network string
 	// NetworkAliases are DNS aliases for this container on the network
 	NetworkAliases []string
@@ -42,69 +40,59 @@ type SandboxConfig struct {
 // NewSandbox creates and starts a new sandbox container running the bridge CLI.
 // The caller is responsible for calling Terminate() when done.
 func NewSandbox(ctx context.Context, cfg SandboxConfig) (*SandboxContainer, error) {
+	rt, err := selectRuntime(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build the bridge binary for Linux
 	binaryPath, err := BuildBridge()
 	if err != nil {
 		return nil, fmt.Errorf("failed to build bridge: %w", err)
 	}
 
-	// Create a temp directory for the Docker build context
+	// Create a temp directory for the build context
 	buildCtx, err := createBuildContext(binaryPath, "Dockerfile.sandbox")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create build context: %w", err)
 	}
+	defer os.RemoveAll(buildCtx)
 
 	exposedPorts := []string{SandboxPort}
 	exposedPorts = append(exposedPorts, cfg.ExposedPorts...)
 
-	req := testcontainers.ContainerRequest{
-		FromDockerfile: testcontainers.FromDockerfile{
-			Context:    buildCtx,
-			Dockerfile: "Dockerfile",
-		},
-		ExposedPorts: exposedPorts,
-		Cmd:          cfg.Command,
-		Env:          cfg.Env,
-		User:         "vercel-sandbox", // Explicitly run as vercel-sandbox user
-		WaitingFor:   wait.ForHTTP("/health").WithPort(SandboxPort).WithStartupTimeout(60 * time.Second),
-	}
-
-	if cfg.Network != "" {
-		req.Networks = []string{cfg.Network}
-		if len(cfg.NetworkAliases) > 0 {
-			req.NetworkAliases = map[string][]string{
-				cfg.Network: cfg.NetworkAliases,
-			}
-		}
-	}
-
-	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
-		ContainerRequest: req,
-		Started:          true,
+	container, err := rt.CreateContainer(ctx, runtime.ContainerSpec{
+		BuildContext:   buildCtx,
+		ExposedPorts:   exposedPorts,
+		Cmd:            cfg.Command,
+		Env:            cfg.Env,
+		User:           "vercel-sandbox", // Explicitly run as vercel-sandbox user
+		Network:        cfg.Network,
+		NetworkAliases: cfg.NetworkAliases,
+		WaitHTTPPath:   "/health",
+		WaitHTTPPort:   SandboxPort,
+		StartupTimeout: 60 * time.Second,
 	})
 	if err != nil {
-		os.RemoveAll(buildCtx)
 		return nil, fmt.Errorf("failed to start sandbox container: %w", err)
 	}
 
 	host, err := container.Host(ctx)
 	if err != nil {
 		container.Terminate(ctx)
-		os.RemoveAll(buildCtx)
 		return nil, fmt.Errorf("failed to get container host: %w", err)
 	}
 
 	mappedPort, err := container.MappedPort(ctx, SandboxPort)
 	if err != nil {
 		container.Terminate(ctx)
-		os.RemoveAll(buildCtx)
 		return nil, fmt.Errorf("failed to get mapped port: %w", err)
 	}
 
 	return &SandboxContainer{
 		Container: container,
 		Host:      host,
-		Port:      mappedPort.Port(),
+		Port:      mappedPort,
 	}, nil
 }
 
@@ -128,61 +116,22 @@ func (s *SandboxContainer) Terminate(ctx context.Context) error {
 
 // Logs returns the container logs
 func (s *SandboxContainer) Logs(ctx context.Context) (string, error) {
-	reader, err := s.Container.Logs(ctx)
-	if err != nil {
-		return "", err
-	}
-	defer reader.Close()
-
-	data, err := io.ReadAll(reader)
-	if err != nil {
-		return "", err
-	}
-	return string(data), nil
+	return s.Container.Logs(ctx)
 }
 
 // ContainerIP returns the container's IP address on the given network
 func (s *SandboxContainer) ContainerIP(ctx context.Context, network string) (string, error) {
-	inspect, err := s.Container.Inspect(ctx)
-	if err != nil {
-		return "", err
-	}
-
-	if network == "" {
-		// Return the first available IP
-		for _, net := range inspect.NetworkSettings.Networks {
-			return net.IPAddress, nil
-		}
-		return "", fmt.Errorf("no network found")
-	}
-
-	net, ok := inspect.NetworkSettings.Networks[network]
-	if !ok {
-		return "", fmt.Errorf("network %s not found", network)
-	}
-	return net.IPAddress, nil
+	return s.Container.ContainerIP(ctx, network)
 }
 
 // Exec executes a command in the container as vercel-sandbox and returns the exit code
 func (s *SandboxContainer) Exec(ctx context.Context, cmd []string) (int, string, error) {
-	exitCode, reader, err := s.Container.Exec(ctx, cmd, exec.WithUser("vercel-sandbox"))
-	if err != nil {
-		return exitCode, "", err
-	}
-
-	output, _ := io.ReadAll(reader)
-	return exitCode, string(output), nil
+	return s.Container.Exec(ctx, cmd, runtime.ExecOptions{User: "vercel-sandbox"})
 }
 
 // ExecAsRoot executes a command in the container as root and returns the exit code
 func (s *SandboxContainer) ExecAsRoot(ctx context.Context, cmd []string) (int, string, error) {
-	exitCode, reader, err := s.Container.Exec(ctx, cmd)
-	if err != nil {
-		return exitCode, "", err
-	}
-
-	output, _ := io.ReadAll(reader)
-	return exitCode, string(output), nil
+	return s.Container.Exec(ctx, cmd, runtime.ExecOptions{})
 }
 
 // createBuildContext creates a temporary directory with the binary and Dockerfile
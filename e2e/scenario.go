@@ -0,0 +1,96 @@
+package e2e
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Privilege is the devcontainer privilege mode a Scenario runs under.
+type Privilege string
+
+const (
+	Privileged Privilege = "privileged"
+	Rootless   Privilege = "rootless"
+)
+
+// NetworkBackend is the packet-filtering backend bridge intercept configures
+// inside the devcontainer.
+type NetworkBackend string
+
+const (
+	IPTables NetworkBackend = "iptables"
+	NFTables NetworkBackend = "nftables"
+)
+
+// IPFamily is the address family a Scenario exercises.
+type IPFamily string
+
+const (
+	IPv4      IPFamily = "ipv4"
+	IPv6      IPFamily = "ipv6"
+	DualStack IPFamily = "dual-stack"
+)
+
+// Scenario is one point in the E2E coverage matrix: a combination of
+// devcontainer privilege mode, packet-filtering backend, IP family, and
+// whether mutagen sync is enabled. Each combination becomes its own t.Run
+// sub-test via RunMatrix so a failure pinpoints the exact combination that
+// broke.
+type Scenario struct {
+	Privilege Privilege
+	Network   NetworkBackend
+	IPFamily  IPFamily
+	Mutagen   bool
+}
+
+// Name returns the t.Run sub-test name for this Scenario.
+func (s Scenario) Name() string {
+	mutagen := "mutagen-off"
+	if s.Mutagen {
+		mutagen = "mutagen-on"
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", s.Privilege, s.Network, s.IPFamily, mutagen)
+}
+
+// EnvironmentConfig layers this Scenario's axes onto base, returning the
+// EnvironmentConfig it should run with.
+func (s Scenario) EnvironmentConfig(base EnvironmentConfig) EnvironmentConfig {
+	cfg := base
+	cfg.DevcontainerPrivileged = s.Privilege == Privileged
+	cfg.NetworkBackend = s.Network
+	cfg.IPFamily = s.IPFamily
+	cfg.MutagenDisabled = !s.Mutagen
+	return cfg
+}
+
+// FullMatrix returns every combination of {privileged, rootless} x
+// {iptables, nftables} x {ipv4, ipv6, dual-stack} x {mutagen-on, mutagen-off}.
+func FullMatrix() []Scenario {
+	var scenarios []Scenario
+	for _, p := range []Privilege{Privileged, Rootless} {
+		for _, n := range []NetworkBackend{IPTables, NFTables} {
+			for _, f := range []IPFamily{IPv4, IPv6, DualStack} {
+				for _, m := range []bool{true, false} {
+					scenarios = append(scenarios, Scenario{Privilege: p, Network: n, IPFamily: f, Mutagen: m})
+				}
+			}
+		}
+	}
+	return scenarios
+}
+
+// RunMatrix runs fn once per Scenario in scenarios as a parallel t.Run
+// sub-test, bounding how many run at once with maxParallel so a full matrix
+// doesn't try to stand up two dozen Docker networks and sandboxes
+// simultaneously.
+func RunMatrix(t *testing.T, scenarios []Scenario, maxParallel int, fn func(t *testing.T, sc Scenario)) {
+	sem := make(chan struct{}, maxParallel)
+	for _, sc := range scenarios {
+		t.Run(sc.Name(), func(t *testing.T) {
+			t.Parallel()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			fn(t, sc)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	tcexec "github.com/testcontainers/testcontainers-go/exec"
+	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// dockerRuntime implements Runtime on top of testcontainers-go, which is
+// also what talks to Podman's Docker-compatible API socket if DOCKER_HOST
+// is pointed at one. newPodmanRuntime is preferred when BRIDGE_E2E_RUNTIME
+// or autodetection asks for Podman specifically, since it can express
+// Podman-only flags (e.g. rootless security-opt translation) that the
+// Docker API has no equivalent for.
+type dockerRuntime struct{}
+
+func newDockerRuntime(ctx context.Context) (Runtime, error) {
+	if err := probe(ctx, "docker"); err != nil {
+		return nil, fmt.Errorf("docker runtime unavailable: %w", err)
+	}
+	return dockerRuntime{}, nil
+}
+
+func (dockerRuntime) Backend() Backend { return BackendDocker }
+
+func (dockerRuntime) CreateContainer(ctx context.Context, spec ContainerSpec) (Container, error) {
+	dockerfile := spec.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+
+	req := testcontainers.ContainerRequest{
+		FromDockerfile: testcontainers.FromDockerfile{
+			Context:    spec.BuildContext,
+			Dockerfile: dockerfile,
+		},
+		ExposedPorts: spec.ExposedPorts,
+		Cmd:          spec.Cmd,
+		Env:          spec.Env,
+		User:         spec.User,
+		Privileged:   spec.Privileged,
+	}
+
+	if spec.Network != "" {
+		req.Networks = []string{spec.Network}
+		if len(spec.NetworkAliases) > 0 {
+			req.NetworkAliases = map[string][]string{spec.Network: spec.NetworkAliases}
+		}
+	}
+
+	if spec.WaitHTTPPath != "" {
+		timeout := spec.StartupTimeout
+		if timeout == 0 {
+			timeout = 60 * time.Second
+		}
+		req.WaitingFor = wait.ForHTTP(spec.WaitHTTPPath).WithPort(nat.Port(spec.WaitHTTPPort)).WithStartupTimeout(timeout)
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start container: %w", err)
+	}
+
+	return &dockerContainer{container: container}, nil
+}
+
+func (dockerRuntime) CreateNetwork(ctx context.Context, name string) (Network, error) {
+	net, err := network.New(ctx, network.WithDriver("bridge"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create network: %w", err)
+	}
+	return &dockerNetwork{network: net}, nil
+}
+
+type dockerContainer struct {
+	container testcontainers.Container
+}
+
+func (c *dockerContainer) Host(ctx context.Context) (string, error) {
+	return c.container.Host(ctx)
+}
+
+func (c *dockerContainer) MappedPort(ctx context.Context, containerPort string) (string, error) {
+	port, err := c.container.MappedPort(ctx, nat.Port(containerPort))
+	if err != nil {
+		return "", err
+	}
+	return port.Port(), nil
+}
+
+func (c *dockerContainer) Exec(ctx context.Context, cmd []string, opts ExecOptions) (int, string, error) {
+	var execOpts []tcexec.ProcessOption
+	if opts.User != "" {
+		execOpts = append(execOpts, tcexec.WithUser(opts.User))
+	}
+	if opts.WorkingDir != "" {
+		execOpts = append(execOpts, tcexec.WithWorkingDir(opts.WorkingDir))
+	}
+
+	exitCode, reader, err := c.container.Exec(ctx, cmd, execOpts...)
+	if err != nil {
+		return exitCode, "", err
+	}
+	output, _ := io.ReadAll(reader)
+	return exitCode, string(output), nil
+}
+
+func (c *dockerContainer) Logs(ctx context.Context) (string, error) {
+	reader, err := c.container.Logs(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (c *dockerContainer) ContainerIP(ctx context.Context, network string) (string, error) {
+	inspect, err := c.container.Inspect(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if network == "" {
+		for _, net := range inspect.NetworkSettings.Networks {
+			return net.IPAddress, nil
+		}
+		return "", fmt.Errorf("no network found")
+	}
+
+	net, ok := inspect.NetworkSettings.Networks[network]
+	if !ok {
+		return "", fmt.Errorf("network %s not found", network)
+	}
+	return net.IPAddress, nil
+}
+
+func (c *dockerContainer) ExitCode(ctx context.Context) (int64, error) {
+	state, err := c.container.State(ctx)
+	if err != nil {
+		return -1, err
+	}
+
+	if !state.Running {
+		return int64(state.ExitCode), nil
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return -1, ctx.Err()
+		case <-ticker.C:
+			state, err := c.container.State(ctx)
+			if err != nil {
+				return -1, err
+			}
+			if !state.Running {
+				return int64(state.ExitCode), nil
+			}
+		}
+	}
+}
+
+func (c *dockerContainer) Terminate(ctx context.Context) error {
+	return c.container.Terminate(ctx)
+}
+
+type dockerNetwork struct {
+	network *testcontainers.DockerNetwork
+}
+
+func (n *dockerNetwork) Name() string { return n.network.Name }
+
+func (n *dockerNetwork) Terminate(ctx context.Context) error {
+	return n.network.Remove(ctx)
+}
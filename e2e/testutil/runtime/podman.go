@@ -0,0 +1,288 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// podmanRuntime implements Runtime by shelling out to the podman CLI. It
+// honors CONTAINER_HOST the same way the podman binary itself does (we
+// never touch the socket directly, so rootless and remote Podman work the
+// same as on the command line).
+type podmanRuntime struct {
+	rootless bool
+}
+
+func newPodmanRuntime(ctx context.Context) (Runtime, error) {
+	if err := probe(ctx, "podman"); err != nil {
+		return nil, fmt.Errorf("podman runtime unavailable: %w", err)
+	}
+
+	out, err := exec.CommandContext(ctx, "podman", "info", "--format", "{{.Host.Security.Rootless}}").Output()
+	rootless := err == nil && strings.TrimSpace(string(out)) == "true"
+
+	return &podmanRuntime{rootless: rootless}, nil
+}
+
+func (podmanRuntime) Backend() Backend { return BackendPodman }
+
+func (r *podmanRuntime) CreateContainer(ctx context.Context, spec ContainerSpec) (Container, error) {
+	dockerfile := spec.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+
+	tag := fmt.Sprintf("bridge-e2e-%d", time.Now().UnixNano())
+	if err := podmanRun(ctx, "build", "-t", tag, "-f", dockerfile, spec.BuildContext); err != nil {
+		return nil, fmt.Errorf("podman build: %w", err)
+	}
+
+	args := []string{"run", "-d"}
+	for _, port := range spec.ExposedPorts {
+		args = append(args, "-p", port)
+	}
+	for k, v := range spec.Env {
+		args = append(args, "-e", fmt.Sprintf("%s=%s", k, v))
+	}
+	if spec.User != "" {
+		args = append(args, "--user", spec.User)
+	}
+	if spec.Network != "" {
+		args = append(args, "--network", spec.Network)
+		for _, alias := range spec.NetworkAliases {
+			args = append(args, "--network-alias", alias)
+		}
+	}
+	if spec.Privileged {
+		args = append(args, "--privileged")
+		if r.rootless {
+			// Rootless Podman still confines privileged containers with
+			// SELinux/seccomp in ways Docker's --privileged does not;
+			// disabling the label is the documented workaround for
+			// iptables-manipulating containers (e.g. the devcontainer).
+			args = append(args, "--security-opt", "label=disable")
+		}
+	}
+	args = append(args, tag)
+	args = append(args, spec.Cmd...)
+
+	idBytes, err := exec.CommandContext(ctx, "podman", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("podman run: %w", err)
+	}
+	id := strings.TrimSpace(string(idBytes))
+
+	c := &podmanContainer{id: id}
+
+	if spec.WaitHTTPPath != "" {
+		timeout := spec.StartupTimeout
+		if timeout == 0 {
+			timeout = 60 * time.Second
+		}
+		if err := c.waitHTTP(ctx, spec.WaitHTTPPort, spec.WaitHTTPPath, timeout); err != nil {
+			c.Terminate(ctx)
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (podmanRuntime) CreateNetwork(ctx context.Context, name string) (Network, error) {
+	fullName := fmt.Sprintf("%s-%d", name, time.Now().UnixNano())
+	if err := podmanRun(ctx, "network", "create", fullName); err != nil {
+		return nil, fmt.Errorf("podman network create: %w", err)
+	}
+	return &podmanNetwork{name: fullName}, nil
+}
+
+// podmanRun runs a podman subcommand, streaming its output to our own
+// stdout/stderr for build/run visibility in CI logs.
+func podmanRun(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, "podman", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+type podmanContainer struct {
+	id string
+}
+
+func (c *podmanContainer) Host(ctx context.Context) (string, error) {
+	if host := os.Getenv("CONTAINER_HOST"); host != "" {
+		if u, err := parseContainerHost(host); err == nil && u != "" {
+			return u, nil
+		}
+	}
+	return "127.0.0.1", nil
+}
+
+func (c *podmanContainer) MappedPort(ctx context.Context, containerPort string) (string, error) {
+	out, err := exec.CommandContext(ctx, "podman", "port", c.id, containerPort).Output()
+	if err != nil {
+		return "", fmt.Errorf("podman port: %w", err)
+	}
+	// Output looks like "0.0.0.0:34567"
+	parts := strings.Split(strings.TrimSpace(string(out)), ":")
+	if len(parts) == 0 {
+		return "", fmt.Errorf("unexpected podman port output %q", out)
+	}
+	return parts[len(parts)-1], nil
+}
+
+func (c *podmanContainer) Exec(ctx context.Context, cmd []string, opts ExecOptions) (int, string, error) {
+	args := []string{"exec"}
+	if opts.User != "" {
+		args = append(args, "--user", opts.User)
+	}
+	if opts.WorkingDir != "" {
+		args = append(args, "--workdir", opts.WorkingDir)
+	}
+	args = append(args, c.id)
+	args = append(args, cmd...)
+
+	var out bytes.Buffer
+	execCmd := exec.CommandContext(ctx, "podman", args...)
+	execCmd.Stdout = &out
+	execCmd.Stderr = &out
+
+	err := execCmd.Run()
+	exitCode := 0
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		exitCode = exitErr.ExitCode()
+		err = nil
+	}
+	return exitCode, out.String(), err
+}
+
+func (c *podmanContainer) Logs(ctx context.Context) (string, error) {
+	out, err := exec.CommandContext(ctx, "podman", "logs", c.id).CombinedOutput()
+	return string(out), err
+}
+
+func (c *podmanContainer) ContainerIP(ctx context.Context, network string) (string, error) {
+	var inspect []struct {
+		NetworkSettings struct {
+			Networks map[string]struct {
+				IPAddress string `json:"IPAddress"`
+			} `json:"Networks"`
+		} `json:"NetworkSettings"`
+	}
+
+	out, err := exec.CommandContext(ctx, "podman", "inspect", c.id).Output()
+	if err != nil {
+		return "", fmt.Errorf("podman inspect: %w", err)
+	}
+	if err := json.Unmarshal(out, &inspect); err != nil || len(inspect) == 0 {
+		return "", fmt.Errorf("parse podman inspect output: %w", err)
+	}
+
+	networks := inspect[0].NetworkSettings.Networks
+	if network == "" {
+		for _, n := range networks {
+			return n.IPAddress, nil
+		}
+		return "", fmt.Errorf("no network found")
+	}
+	n, ok := networks[network]
+	if !ok {
+		return "", fmt.Errorf("network %s not found", network)
+	}
+	return n.IPAddress, nil
+}
+
+func (c *podmanContainer) ExitCode(ctx context.Context) (int64, error) {
+	for {
+		out, err := exec.CommandContext(ctx, "podman", "inspect", "--format", "{{.State.Running}} {{.State.ExitCode}}", c.id).Output()
+		if err != nil {
+			return -1, fmt.Errorf("podman inspect: %w", err)
+		}
+		fields := strings.Fields(strings.TrimSpace(string(out)))
+		if len(fields) != 2 {
+			return -1, fmt.Errorf("unexpected podman inspect output %q", out)
+		}
+		if fields[0] == "false" {
+			var code int64
+			fmt.Sscanf(fields[1], "%d", &code)
+			return code, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return -1, ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func (c *podmanContainer) Terminate(ctx context.Context) error {
+	return podmanRun(ctx, "rm", "-f", c.id)
+}
+
+func (c *podmanContainer) waitHTTP(ctx context.Context, containerPort, path string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	client := &http.Client{Timeout: 2 * time.Second}
+
+	for {
+		host, err := c.Host(ctx)
+		if err == nil {
+			if port, err := c.MappedPort(ctx, containerPort); err == nil {
+				resp, err := client.Get(fmt.Sprintf("http://%s:%s%s", host, port, path))
+				if err == nil {
+					resp.Body.Close()
+					if resp.StatusCode == http.StatusOK {
+						return nil
+					}
+				}
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for %s to respond", path)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(250 * time.Millisecond):
+		}
+	}
+}
+
+type podmanNetwork struct {
+	name string
+}
+
+func (n *podmanNetwork) Name() string { return n.name }
+
+func (n *podmanNetwork) Terminate(ctx context.Context) error {
+	return podmanRun(ctx, "network", "rm", "-f", n.name)
+}
+
+// parseContainerHost extracts the host portion of a CONTAINER_HOST URL
+// (e.g. "ssh://user@remote-host/run/podman/podman.sock") so mapped ports
+// can be reached on the machine actually running the container.
+func parseContainerHost(containerHost string) (string, error) {
+	rest, ok := strings.CutPrefix(containerHost, "ssh://")
+	if !ok {
+		return "", fmt.Errorf("unsupported CONTAINER_HOST scheme in %q", containerHost)
+	}
+	if at := strings.Index(rest, "@"); at != -1 {
+		rest = rest[at+1:]
+	}
+	if slash := strings.Index(rest, "/"); slash != -1 {
+		rest = rest[:slash]
+	}
+	if rest == "" {
+		return "", fmt.Errorf("empty host in CONTAINER_HOST %q", containerHost)
+	}
+	return rest, nil
+}
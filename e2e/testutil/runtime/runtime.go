@@ -0,0 +1,124 @@
+// Package runtime abstracts the container engine the e2e suite drives, so
+// the same test code can run against either Docker or Podman.
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// Backend identifies a container runtime implementation.
+type Backend string
+
+const (
+	BackendDocker Backend = "docker"
+	BackendPodman Backend = "podman"
+)
+
+// Runtime creates and drives the containers and networks an e2e test needs.
+type Runtime interface {
+	// Backend identifies which implementation this is, for logging.
+	Backend() Backend
+	// CreateContainer builds (if needed) and starts a container per spec.
+	CreateContainer(ctx context.Context, spec ContainerSpec) (Container, error)
+	// CreateNetwork creates a bridge network containers can join by name.
+	CreateNetwork(ctx context.Context, name string) (Network, error)
+}
+
+// ContainerSpec describes a container to create. It covers the union of
+// what SandboxContainer and DevcontainerContainer need; fields that don't
+// apply to a given container (e.g. ExposedPorts for a devcontainer) are
+// left zero.
+type ContainerSpec struct {
+	// BuildContext is the directory containing Dockerfile and anything it COPYs.
+	BuildContext string
+	// Dockerfile is the filename within BuildContext. Defaults to "Dockerfile".
+	Dockerfile string
+
+	Cmd            []string
+	Env            map[string]string
+	User           string
+	ExposedPorts   []string
+	Network        string
+	NetworkAliases []string
+
+	// Privileged runs the container with elevated capabilities, needed for
+	// iptables-manipulating devcontainers. Runtimes translate this to
+	// whatever flags their engine requires.
+	Privileged bool
+
+	// WaitHTTPPath, if set, is polled on WaitHTTPPort until it returns 200
+	// before CreateContainer returns.
+	WaitHTTPPath   string
+	WaitHTTPPort   string
+	StartupTimeout time.Duration
+}
+
+// ExecOptions configures a single Exec call.
+type ExecOptions struct {
+	User       string
+	WorkingDir string
+}
+
+// Container is a running container returned by CreateContainer.
+type Container interface {
+	// Host returns the address other processes on this machine can use to
+	// reach the container's exposed ports.
+	Host(ctx context.Context) (string, error)
+	// MappedPort returns the host port bound to containerPort (e.g. "3000/tcp").
+	MappedPort(ctx context.Context, containerPort string) (string, error)
+	Exec(ctx context.Context, cmd []string, opts ExecOptions) (int, string, error)
+	Logs(ctx context.Context) (string, error)
+	// ContainerIP returns the container's IP address on the given network.
+	ContainerIP(ctx context.Context, network string) (string, error)
+	// ExitCode returns the container's exit code, waiting for it to stop.
+	ExitCode(ctx context.Context) (int64, error)
+	Terminate(ctx context.Context) error
+}
+
+// Network is a container network returned by CreateNetwork.
+type Network interface {
+	Name() string
+	Terminate(ctx context.Context) error
+}
+
+// Select picks the Runtime to use for the e2e suite. BRIDGE_E2E_RUNTIME
+// forces a specific backend ("docker" or "podman"); if unset, Select
+// autodetects by probing Docker first, then Podman.
+func Select(ctx context.Context) (Runtime, error) {
+	switch b := Backend(os.Getenv("BRIDGE_E2E_RUNTIME")); b {
+	case BackendDocker:
+		return newDockerRuntime(ctx)
+	case BackendPodman:
+		return newPodmanRuntime(ctx)
+	case "":
+		// fall through to autodetect
+	default:
+		return nil, fmt.Errorf("unknown BRIDGE_E2E_RUNTIME %q (want %q or %q)", b, BackendDocker, BackendPodman)
+	}
+
+	if rt, err := newDockerRuntime(ctx); err == nil {
+		return rt, nil
+	}
+	if rt, err := newPodmanRuntime(ctx); err == nil {
+		return rt, nil
+	}
+	return nil, fmt.Errorf("no container runtime available: neither docker nor podman responded; set BRIDGE_E2E_RUNTIME or start one")
+}
+
+// probe runs "<bin> info" with a short timeout to check the engine is
+// reachable, honoring CONTAINER_HOST/DOCKER_HOST via the inherited
+// environment since both CLIs read it themselves.
+func probe(ctx context.Context, bin string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, bin, "info")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s info: %w", bin, err)
+	}
+	return nil
+}
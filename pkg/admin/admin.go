@@ -5,6 +5,7 @@ package admin
 
 import (
 	"context"
+	"io"
 
 	bridgev1 "github.com/vercel/bridge/api/go/bridge/v1"
 )
@@ -16,4 +17,74 @@ type Service interface {
 	ListBridges(ctx context.Context, in *bridgev1.ListBridgesRequest) (*bridgev1.ListBridgesResponse, error)
 	// DeleteBridge tears down a specific bridge and its associated resources.
 	DeleteBridge(ctx context.Context, in *bridgev1.DeleteBridgeRequest) (*bridgev1.DeleteBridgeResponse, error)
+	// PortForward proxies a bidirectional byte stream to a port inside the
+	// named bridge's pod, modeled on the Kubernetes CRI PortForward API. The
+	// first frame read from stream must be a PortForwardFrame with Open set;
+	// every frame after that carries Data until either side sends Close or
+	// the stream returns an error.
+	PortForward(ctx context.Context, stream PortForwardStream) error
+	// HealthCheck immediately probes a single bridge with its configured
+	// probes (or, when none are configured, a TCP dial plus an
+	// application-level Ping) rather than waiting for the next reconciler
+	// tick, and returns the resulting health.
+	HealthCheck(ctx context.Context, in *bridgev1.HealthCheckRequest) (*bridgev1.HealthCheckResponse, error)
+	// ConfigureHealth declares the health probes a bridge's sandbox should be
+	// checked against (http, tcp, or exec, with interval/timeout/retries/
+	// start_period/success_threshold), replacing whatever it was previously
+	// configured with. An empty Probes list reverts the bridge to the
+	// reconciler's built-in default check.
+	ConfigureHealth(ctx context.Context, in *bridgev1.ConfigureHealthRequest) (*bridgev1.ConfigureHealthResponse, error)
+	// HealthHistory returns the bounded ring buffer of a bridge's most recent
+	// probe results, beyond the single current-status summary HealthCheck
+	// reports.
+	HealthHistory(ctx context.Context, in *bridgev1.HealthHistoryRequest) (*bridgev1.HealthHistoryResponse, error)
+	// WatchBridges streams a snapshot of a device's bridges, including
+	// health, every time something changes, long-polling on an interval
+	// when the implementation has no finer-grained signal to push on.
+	WatchBridges(ctx context.Context, in *bridgev1.WatchBridgesRequest) (BridgeWatchStream, error)
+	// SnapshotBridge checkpoints a bridge's accumulated workspace state
+	// (installed feature layers, /workspace overlay, env) into a named
+	// Snapshot that RestoreBridge can later rehydrate into a fresh bridge
+	// for the same source deployment.
+	SnapshotBridge(ctx context.Context, in *bridgev1.SnapshotBridgeRequest) (*bridgev1.SnapshotBridgeResponse, error)
+	// RestoreBridge rehydrates a previously captured Snapshot into the
+	// bridge named in the request, skipping the feature-install work a
+	// fresh CreateBridge would otherwise repeat.
+	RestoreBridge(ctx context.Context, in *bridgev1.RestoreBridgeRequest) (*bridgev1.RestoreBridgeResponse, error)
+	// ListSnapshots returns the Snapshots a device has captured, optionally
+	// filtered to one bridge.
+	ListSnapshots(ctx context.Context, in *bridgev1.ListSnapshotsRequest) (*bridgev1.ListSnapshotsResponse, error)
+}
+
+// PortForwardStream is the bidirectional channel of PortForwardFrame messages
+// a PortForward call reads from and writes to. Both the generated client-side
+// and server-side gRPC stream types satisfy this interface, so Service
+// implementations don't need to know which side of the wire they're on.
+type PortForwardStream interface {
+	Send(*bridgev1.PortForwardFrame) error
+	Recv() (*bridgev1.PortForwardFrame, error)
+}
+
+// BridgeWatchStream is the channel of WatchBridgesResponse snapshots a
+// WatchBridges call delivers over. The generated gRPC client stream and the
+// local admin's long-poll implementation both satisfy this interface.
+type BridgeWatchStream interface {
+	Recv() (*bridgev1.WatchBridgesResponse, error)
+}
+
+// HealthRunner is implemented by Service implementations that run a
+// background health reconciler. The administrator process should start it
+// once at startup; remote clients don't implement it, since reconciliation
+// happens server-side.
+type HealthRunner interface {
+	RunHealthReconciler(ctx context.Context)
+}
+
+// MetricsWriter is implemented by Service implementations that can render
+// their health reconciler's state as Prometheus text exposition format.
+// Like HealthRunner, only the local implementation satisfies this: remote
+// clients expose metrics by scraping the administrator directly, not
+// through the gRPC connection.
+type MetricsWriter interface {
+	WriteHealthMetrics(w io.Writer) error
 }
@@ -0,0 +1,731 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	bridgev1 "github.com/vercel/bridge/api/go/bridge/v1"
+	"github.com/vercel/bridge/pkg/k8s/kube"
+	"github.com/vercel/bridge/pkg/k8s/meta"
+	"github.com/vercel/bridge/pkg/k8s/resources"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	clientexec "k8s.io/client-go/util/exec"
+
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	defaultHealthInterval   = 15 * time.Second
+	defaultFailureThreshold = 3
+	defaultProbeTimeout     = 5 * time.Second
+	latencyHistorySize      = 20
+	probeResultHistorySize  = 20
+	defaultSuccessThreshold = 1
+	maxProbeOutputBytes     = 4 * 1024
+)
+
+// ProbeType selects how a health probe checks a bridge's sandbox, mirroring
+// the probe kinds container-runtime healthchecks (e.g. Docker's HEALTHCHECK)
+// support.
+type ProbeType string
+
+const (
+	ProbeHTTP ProbeType = "http"
+	ProbeTCP  ProbeType = "tcp"
+	ProbeExec ProbeType = "exec"
+)
+
+// ProbeSpec configures one health probe a bridge declares. A bridge may
+// declare several; all of them run together on the reconciler's own tick
+// (Interval is not consulted), and only the first one's result drives the
+// bridge's phase: a failure of any later probe is logged but does not
+// change the phase. Bridges with no configured ProbeSpecs fall back to the
+// reconciler's original check (TCP dial plus an application-level Ping).
+type ProbeSpec struct {
+	Type ProbeType `json:"type"`
+	// Path is the HTTP path requested when Type is ProbeHTTP.
+	Path string `json:"path,omitempty"`
+	// Port is the pod port dialed (ProbeTCP) or requested (ProbeHTTP).
+	Port int32 `json:"port,omitempty"`
+	// Command is exec'd inside the sandbox's proxy container when Type is
+	// ProbeExec; a zero exit code is success, same as Kubernetes' exec probes.
+	Command []string `json:"command,omitempty"`
+
+	Interval         time.Duration `json:"interval,omitempty"`
+	Timeout          time.Duration `json:"timeout,omitempty"`
+	Retries          int           `json:"retries,omitempty"`
+	StartPeriod      time.Duration `json:"startPeriod,omitempty"`
+	SuccessThreshold int           `json:"successThreshold,omitempty"`
+}
+
+// withDefaults fills in zero-valued fields the same way Kubernetes' own
+// probe defaulting does, so a ProbeSpec built from partial user input (e.g.
+// `bridge health --probe-type http`) behaves sensibly.
+func (p ProbeSpec) withDefaults() ProbeSpec {
+	if p.Interval <= 0 {
+		p.Interval = defaultHealthInterval
+	}
+	if p.Timeout <= 0 {
+		p.Timeout = defaultProbeTimeout
+	}
+	if p.Retries <= 0 {
+		p.Retries = defaultFailureThreshold
+	}
+	if p.StartPeriod < 0 {
+		p.StartPeriod = 0
+	}
+	if p.SuccessThreshold <= 0 {
+		p.SuccessThreshold = defaultSuccessThreshold
+	}
+	return p
+}
+
+// defaultProbeSpecs is used for bridges with no probes configured via
+// ConfigureHealth: the TCP-dial-plus-Ping check the reconciler has always run.
+var defaultProbeSpecs = []ProbeSpec{ProbeSpec{Type: ProbeTCP}.withDefaults()}
+
+// healthPhase is the reconciler's per-bridge state machine, modeled on
+// Docker's HEALTHCHECK states: a bridge starts in "starting", and failures
+// during StartPeriod don't count against it (like a grace period for a
+// slow-booting app), though enough consecutive successes still move it to
+// "healthy". Outside StartPeriod it moves to "healthy" or "unhealthy" based
+// on consecutive results crossing SuccessThreshold/Retries.
+type healthPhase string
+
+const (
+	phaseStarting  healthPhase = "starting"
+	phaseHealthy   healthPhase = "healthy"
+	phaseUnhealthy healthPhase = "unhealthy"
+)
+
+// ProbeResult is one probe's outcome, recorded into a bridge's bounded
+// history ring buffer.
+type ProbeResult struct {
+	Timestamp time.Time     `json:"timestamp"`
+	Success   bool          `json:"success"`
+	Duration  time.Duration `json:"duration"`
+	// ExitCode is the probe's exit code for ProbeExec, or 0/1 for
+	// ProbeHTTP/ProbeTCP since those have no real exit code of their own.
+	ExitCode int `json:"exitCode"`
+	// Output is a truncated tail of the probe's error message, empty on
+	// success. For ProbeExec the message includes the command's stderr; its
+	// stdout is not recorded.
+	Output string `json:"output,omitempty"`
+}
+
+// healthState is the rolling health record the reconciler keeps for one
+// bridge deployment, keyed by "namespace/name".
+type healthState struct {
+	lastHealthy          time.Time
+	lastFailure          time.Time
+	consecutiveFailures  int
+	consecutiveSuccesses int
+	latencies            []time.Duration // ring buffer, most recent last
+	nextRetry            time.Time
+	backoff              time.Duration
+
+	phase     healthPhase
+	startedAt time.Time
+	results   []ProbeResult // ring buffer, most recent last
+}
+
+func (s *healthState) recordLatency(d time.Duration) {
+	s.latencies = append(s.latencies, d)
+	if len(s.latencies) > latencyHistorySize {
+		s.latencies = s.latencies[len(s.latencies)-latencyHistorySize:]
+	}
+}
+
+func (s *healthState) avgLatency() time.Duration {
+	if len(s.latencies) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, d := range s.latencies {
+		total += d
+	}
+	return total / time.Duration(len(s.latencies))
+}
+
+// recordResult pushes result onto the bridge's history ring buffer and
+// advances its phase per spec's start_period/retries/success_threshold
+// rules. Callers must hold the reconciler's mutex.
+func (s *healthState) recordResult(spec ProbeSpec, result ProbeResult) {
+	if s.startedAt.IsZero() {
+		s.startedAt = result.Timestamp
+		s.phase = phaseStarting
+	}
+
+	s.results = append(s.results, result)
+	if len(s.results) > probeResultHistorySize {
+		s.results = s.results[len(s.results)-probeResultHistorySize:]
+	}
+
+	if result.Success {
+		s.lastHealthy = result.Timestamp
+		s.consecutiveSuccesses++
+		s.consecutiveFailures = 0
+		s.recordLatency(result.Duration)
+	} else {
+		s.lastFailure = result.Timestamp
+		s.consecutiveFailures++
+		s.consecutiveSuccesses = 0
+	}
+
+	inStartPeriod := spec.StartPeriod > 0 && result.Timestamp.Sub(s.startedAt) < spec.StartPeriod
+	switch {
+	case inStartPeriod:
+		// A slow-booting sandbox's failures during start_period don't count
+		// against it, matching Docker's HEALTHCHECK --start-period semantics.
+		if result.Success && s.consecutiveSuccesses >= spec.SuccessThreshold {
+			s.phase = phaseHealthy
+		}
+	case result.Success && s.consecutiveSuccesses >= spec.SuccessThreshold:
+		s.phase = phaseHealthy
+	case !result.Success && s.consecutiveFailures >= spec.Retries:
+		s.phase = phaseUnhealthy
+	}
+}
+
+// healthReconciler periodically probes every managed bridge deployment
+// against its configured ProbeSpecs (or the built-in default) and
+// auto-recreates ones that fail past their threshold, backing off
+// exponentially between recreate attempts.
+type healthReconciler struct {
+	adm              *adminService
+	interval         time.Duration
+	failureThreshold int
+	// restartOnUnhealthy recreates a bridge the first time it transitions
+	// into phaseUnhealthy. It's on by default, matching the reconciler's
+	// original always-recreate behavior, and applies to every bridge alike:
+	// ConfigureHealth has no per-bridge setting for it.
+	restartOnUnhealthy bool
+
+	mu     sync.Mutex
+	states map[string]*healthState
+}
+
+func newHealthReconciler(adm *adminService) *healthReconciler {
+	return &healthReconciler{
+		adm:                adm,
+		interval:           defaultHealthInterval,
+		failureThreshold:   defaultFailureThreshold,
+		restartOnUnhealthy: true,
+		states:             make(map[string]*healthState),
+	}
+}
+
+// Run probes every managed bridge deployment every interval until ctx is
+// canceled. It's meant to be started as a background goroutine by the
+// administrator process.
+func (h *healthReconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.probeAll(ctx)
+		}
+	}
+}
+
+func (h *healthReconciler) probeAll(ctx context.Context) {
+	deploys, err := h.adm.client.AppsV1().Deployments("").List(ctx, metav1.ListOptions{
+		LabelSelector: meta.LabelManagedBy + "=" + meta.ManagedByAdministrator,
+	})
+	if err != nil {
+		h.adm.logger.Warn("health reconciler: failed to list bridge deployments", "error", err)
+		return
+	}
+
+	for _, d := range deploys.Items {
+		go h.probeOne(ctx, d)
+	}
+}
+
+func (h *healthReconciler) probeOne(ctx context.Context, d appsv1.Deployment) {
+	specs := probeSpecsFor(d)
+	key := d.Namespace + "/" + d.Name
+
+	result, probeErr := h.runProbes(ctx, d, specs)
+
+	h.mu.Lock()
+	st, ok := h.states[key]
+	if !ok {
+		st = &healthState{}
+		h.states[key] = st
+	}
+	// Every configured probe shares the same retry/threshold bookkeeping for
+	// now; the first spec (or the default) drives the state machine, which
+	// is enough to support the common single-probe case this engine targets.
+	st.recordResult(specs[0], result)
+	phase := st.phase
+	readyForRetry := st.nextRetry.IsZero() || time.Now().After(st.nextRetry)
+	h.mu.Unlock()
+
+	if probeErr != nil {
+		h.adm.logger.Warn("bridge health probe failed", "bridge", key, "error", probeErr, "phase", phase)
+	}
+
+	if h.restartOnUnhealthy && phase == phaseUnhealthy && readyForRetry {
+		h.recreate(ctx, d, key)
+	}
+}
+
+// runProbes runs every configured spec against d in turn, returning the
+// ProbeResult for the first (state-machine-driving) spec, plus an error
+// describing the first failure across any spec so probeOne can log it.
+func (h *healthReconciler) runProbes(ctx context.Context, d appsv1.Deployment, specs []ProbeSpec) (ProbeResult, error) {
+	var first ProbeResult
+	var firstErr error
+
+	for i, spec := range specs {
+		start := time.Now()
+		err := h.runProbeSpec(ctx, d, spec)
+		result := ProbeResult{
+			Timestamp: time.Now(),
+			Success:   err == nil,
+			Duration:  time.Since(start),
+			ExitCode:  exitCodeFor(err),
+		}
+		if err != nil {
+			result.Output = truncateOutput(err.Error())
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		if i == 0 {
+			first = result
+		}
+	}
+	return first, firstErr
+}
+
+// runProbeSpec runs a single ProbeSpec against d's current pod: an http
+// request, a raw TCP dial, or an exec'd command, matching the semantics
+// Kubernetes itself gives each probe type.
+func (h *healthReconciler) runProbeSpec(ctx context.Context, d appsv1.Deployment, spec ProbeSpec) error {
+	spec = spec.withDefaults()
+	probeCtx, cancel := context.WithTimeout(ctx, spec.Timeout)
+	defer cancel()
+
+	if spec.Type == "" || (spec.Type == ProbeTCP && spec.Port == 0) {
+		// No explicit probe configured: preserve the reconciler's original
+		// TCP-dial-plus-Ping check.
+		return h.probe(probeCtx, d)
+	}
+
+	podName, err := kube.WaitForPod(probeCtx, h.adm.client, d.Namespace, meta.DeploymentSelector(d.Name), spec.Timeout)
+	if err != nil {
+		return fmt.Errorf("find pod: %w", err)
+	}
+
+	switch spec.Type {
+	case ProbeTCP:
+		dialer, err := h.adm.newProxyDialer(d.Namespace, d.Name, podName, int(spec.Port))
+		if err != nil {
+			return fmt.Errorf("create dialer: %w", err)
+		}
+		conn, err := dialer.DialContext(probeCtx, "")
+		if err != nil {
+			return fmt.Errorf("tcp dial: %w", err)
+		}
+		return conn.Close()
+
+	case ProbeHTTP:
+		dialer, err := h.adm.newProxyDialer(d.Namespace, d.Name, podName, int(spec.Port))
+		if err != nil {
+			return fmt.Errorf("create dialer: %w", err)
+		}
+		client := &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, "")
+				},
+			},
+			Timeout: spec.Timeout,
+		}
+		path := spec.Path
+		if path == "" {
+			path = "/"
+		}
+		req, err := http.NewRequestWithContext(probeCtx, http.MethodGet, "http://proxy"+path, nil)
+		if err != nil {
+			return fmt.Errorf("build request: %w", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("http get: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+			return fmt.Errorf("http get %s: status %d", path, resp.StatusCode)
+		}
+		return nil
+
+	case ProbeExec:
+		return h.execProbe(probeCtx, d.Namespace, podName, spec.Command)
+
+	default:
+		return fmt.Errorf("unsupported probe type %q", spec.Type)
+	}
+}
+
+// execProbe runs command inside the bridge proxy container via the same
+// SPDY exec transport pkg/admin/snapshot.go uses for workspace capture, and
+// treats a nonzero exit code as a probe failure.
+func (h *healthReconciler) execProbe(ctx context.Context, namespace, podName string, command []string) error {
+	req := h.adm.client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Command: command,
+			Stdout:  true,
+			Stderr:  true,
+		}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(h.adm.restConfig, "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("build exec: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := exec.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}); err != nil {
+		return fmt.Errorf("exec probe: %w (stderr: %s)", err, truncateOutput(stderr.String()))
+	}
+	return nil
+}
+
+// exitCodeFor extracts the exec exit code from err, or reports the
+// conventional 0/1 for probes with no real exit code of their own.
+func exitCodeFor(err error) int {
+	if err == nil {
+		return 0
+	}
+	var codeErr clientexec.CodeExitError
+	if ok := asCodeExitError(err, &codeErr); ok {
+		return codeErr.Code
+	}
+	return 1
+}
+
+func asCodeExitError(err error, target *clientexec.CodeExitError) bool {
+	for err != nil {
+		if c, ok := err.(clientexec.CodeExitError); ok {
+			*target = c
+			return true
+		}
+		unwrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = unwrapper.Unwrap()
+	}
+	return false
+}
+
+func truncateOutput(s string) string {
+	if len(s) <= maxProbeOutputBytes {
+		return s
+	}
+	return s[len(s)-maxProbeOutputBytes:]
+}
+
+// probe dials the bridge's proxy pod over TCP and then issues an
+// application-level gRPC Ping, mirroring how container runtimes run
+// liveness/readiness checks against a workload. This is the fallback check
+// used for bridges with no ProbeSpecs configured via ConfigureHealth.
+func (h *healthReconciler) probe(ctx context.Context, d appsv1.Deployment) error {
+	podName, err := kube.WaitForPod(ctx, h.adm.client, d.Namespace, meta.DeploymentSelector(d.Name), defaultProbeTimeout)
+	if err != nil {
+		return fmt.Errorf("find pod: %w", err)
+	}
+
+	port := resources.ProxyPort
+	dialer, err := h.adm.newProxyDialer(d.Namespace, d.Name, podName, port)
+	if err != nil {
+		return fmt.Errorf("create dialer: %w", err)
+	}
+
+	tcpConn, err := dialer.DialContext(ctx, "")
+	if err != nil {
+		return fmt.Errorf("tcp dial: %w", err)
+	}
+	_ = tcpConn.Close()
+
+	conn, err := grpc.NewClient("passthrough:///pod",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "")
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("dial proxy: %w", err)
+	}
+	defer conn.Close()
+
+	client := bridgev1.NewBridgeProxyServiceClient(conn)
+	if _, err := client.Ping(ctx, &bridgev1.PingRequest{}); err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+	return nil
+}
+
+// recreate tears down and re-provisions a bridge deployment that has failed
+// health checks past the threshold, backing off exponentially between
+// attempts so a persistently broken source deployment doesn't get
+// recreated in a tight loop.
+func (h *healthReconciler) recreate(ctx context.Context, d appsv1.Deployment, key string) {
+	h.mu.Lock()
+	st := h.states[key]
+	if st.backoff == 0 {
+		st.backoff = h.interval
+	} else {
+		st.backoff *= 2
+		if st.backoff > 10*time.Minute {
+			st.backoff = 10 * time.Minute
+		}
+	}
+	st.nextRetry = time.Now().Add(st.backoff)
+	h.mu.Unlock()
+
+	sourceDeployment := d.Labels[meta.LabelWorkloadSource]
+	sourceNamespace := d.Labels[meta.LabelWorkloadSourceNamespace]
+	deviceID := d.Labels[meta.LabelDeviceID]
+
+	h.adm.logger.Warn("recreating unhealthy bridge", "bridge", key, "backoff", st.backoff)
+
+	if err := resources.DeleteBridgeResources(ctx, h.adm.client, d.Namespace, d.Name); err != nil {
+		h.adm.logger.Error("failed to tear down unhealthy bridge", "bridge", key, "error", err)
+		return
+	}
+
+	if sourceDeployment == "" {
+		h.adm.logger.Warn("unhealthy bridge has no source deployment recorded, not recreating", "bridge", key)
+		return
+	}
+
+	if _, err := resources.CreateInNamespace(ctx, h.adm.client, resources.InNamespaceConfig{
+		SourceNamespace:  sourceNamespace,
+		SourceDeployment: sourceDeployment,
+		DeviceID:         deviceID,
+		ProxyImage:       h.adm.config.ProxyImage,
+	}); err != nil {
+		h.adm.logger.Error("failed to recreate unhealthy bridge", "bridge", key, "error", err)
+	}
+}
+
+// snapshot returns a copy of the health state for a bridge, or the zero
+// value if it hasn't been probed yet.
+func (h *healthReconciler) snapshot(namespace, name string) healthState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if st, ok := h.states[namespace+"/"+name]; ok {
+		return *st
+	}
+	return healthState{}
+}
+
+// Health returns the current health of a bridge as the wire-level proto,
+// reporting "unknown" for bridges the reconciler hasn't probed yet.
+func (h *healthReconciler) Health(namespace, name string) *bridgev1.BridgeHealth {
+	return h.snapshot(namespace, name).toProto()
+}
+
+// Probe immediately health-checks a single bridge deployment rather than
+// waiting for the next reconciler tick, recording the result the same way
+// periodic probes do so it's reflected in later ListBridges/WatchBridges
+// snapshots too.
+func (h *healthReconciler) Probe(ctx context.Context, namespace, name string) (*bridgev1.BridgeHealth, error) {
+	d, err := h.adm.client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get deployment: %w", err)
+	}
+	h.probeOne(ctx, *d)
+	return h.Health(namespace, name), nil
+}
+
+// toProto renders the health state as the wire-level BridgeHealth proto.
+func (s healthState) toProto() *bridgev1.BridgeHealth {
+	status := string(s.phase)
+	if status == "" {
+		status = "unknown"
+	}
+
+	h := &bridgev1.BridgeHealth{
+		Status:              status,
+		ConsecutiveFailures: int32(s.consecutiveFailures),
+		AvgLatencyMs:        int32(s.avgLatency().Milliseconds()),
+	}
+	if !s.lastHealthy.IsZero() {
+		h.LastHealthyAt = s.lastHealthy.Format(time.RFC3339)
+	}
+	if !s.lastFailure.IsZero() {
+		h.LastFailureAt = s.lastFailure.Format(time.RFC3339)
+	}
+	return h
+}
+
+// probeSpecsFor returns d's configured ProbeSpecs from AnnotationHealthProbes,
+// or defaultProbeSpecs if it has none (or the annotation fails to parse,
+// logged but otherwise treated the same as absent).
+func probeSpecsFor(d appsv1.Deployment) []ProbeSpec {
+	raw, ok := d.Annotations[meta.AnnotationHealthProbes]
+	if !ok || raw == "" {
+		return defaultProbeSpecs
+	}
+	var specs []ProbeSpec
+	if err := json.Unmarshal([]byte(raw), &specs); err != nil || len(specs) == 0 {
+		return defaultProbeSpecs
+	}
+	for i := range specs {
+		specs[i] = specs[i].withDefaults()
+	}
+	return specs
+}
+
+// ConfigureHealth persists the bridge's desired ProbeSpecs as a Deployment
+// annotation, so they're picked up starting with the reconciler's next
+// tick. An empty Probes list clears the annotation, reverting the bridge to
+// the built-in default check.
+func (l *adminService) ConfigureHealth(ctx context.Context, req *bridgev1.ConfigureHealthRequest) (*bridgev1.ConfigureHealthResponse, error) {
+	if req.Namespace == "" || req.Name == "" {
+		return nil, fmt.Errorf("namespace and name are required")
+	}
+
+	specs := make([]ProbeSpec, 0, len(req.Probes))
+	for _, p := range req.Probes {
+		specs = append(specs, ProbeSpec{
+			Type:             ProbeType(p.Type),
+			Path:             p.Path,
+			Port:             p.Port,
+			Command:          p.Command,
+			Interval:         time.Duration(p.IntervalSeconds) * time.Second,
+			Timeout:          time.Duration(p.TimeoutSeconds) * time.Second,
+			Retries:          int(p.Retries),
+			StartPeriod:      time.Duration(p.StartPeriodSeconds) * time.Second,
+			SuccessThreshold: int(p.SuccessThreshold),
+		}.withDefaults())
+	}
+
+	var patch []byte
+	var err error
+	if len(specs) == 0 {
+		patch, err = json.Marshal(map[string]any{
+			"metadata": map[string]any{
+				"annotations": map[string]any{meta.AnnotationHealthProbes: nil},
+			},
+		})
+	} else {
+		encoded, marshalErr := json.Marshal(specs)
+		if marshalErr != nil {
+			return nil, fmt.Errorf("marshal probes: %w", marshalErr)
+		}
+		patch, err = json.Marshal(map[string]any{
+			"metadata": map[string]any{
+				"annotations": map[string]any{meta.AnnotationHealthProbes: string(encoded)},
+			},
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("build patch: %w", err)
+	}
+
+	if _, err := l.client.AppsV1().Deployments(req.Namespace).Patch(ctx, req.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return nil, fmt.Errorf("patch deployment %s/%s: %w", req.Namespace, req.Name, err)
+	}
+	return &bridgev1.ConfigureHealthResponse{}, nil
+}
+
+// HealthHistory returns the bridge's recent probe results, most recent last,
+// as recorded by the reconciler's bounded ring buffer.
+func (l *adminService) HealthHistory(ctx context.Context, req *bridgev1.HealthHistoryRequest) (*bridgev1.HealthHistoryResponse, error) {
+	if req.Namespace == "" || req.Name == "" {
+		return nil, fmt.Errorf("namespace and name are required")
+	}
+
+	st := l.health.snapshot(req.Namespace, req.Name)
+	results := make([]*bridgev1.ProbeResult, 0, len(st.results))
+	for _, r := range st.results {
+		results = append(results, &bridgev1.ProbeResult{
+			TimestampUnix: r.Timestamp.Unix(),
+			Success:       r.Success,
+			DurationMs:    int32(r.Duration.Milliseconds()),
+			ExitCode:      int32(r.ExitCode),
+			Output:        r.Output,
+		})
+	}
+	return &bridgev1.HealthHistoryResponse{
+		Status:  string(st.phase),
+		Results: results,
+	}, nil
+}
+
+// WriteHealthMetrics renders every probed bridge's health as Prometheus text
+// exposition format, for the administrator's /metrics endpoint.
+func (h *healthReconciler) WriteHealthMetrics(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP bridge_health_status Current bridge health phase: 0=starting, 1=healthy, 2=unhealthy.")
+	fmt.Fprintln(w, "# TYPE bridge_health_status gauge")
+	fmt.Fprintln(w, "# HELP bridge_health_consecutive_failures Consecutive failed probes for a bridge.")
+	fmt.Fprintln(w, "# TYPE bridge_health_consecutive_failures gauge")
+	fmt.Fprintln(w, "# HELP bridge_health_avg_latency_ms Average successful probe latency in milliseconds.")
+	fmt.Fprintln(w, "# TYPE bridge_health_avg_latency_ms gauge")
+
+	for key, st := range h.states {
+		ns, name := splitKey(key)
+		labels := fmt.Sprintf(`{namespace=%q,name=%q}`, ns, name)
+		fmt.Fprintf(w, "bridge_health_status%s %d\n", labels, phaseMetricValue(st.phase))
+		fmt.Fprintf(w, "bridge_health_consecutive_failures%s %d\n", labels, st.consecutiveFailures)
+		fmt.Fprintf(w, "bridge_health_avg_latency_ms%s %d\n", labels, st.avgLatency().Milliseconds())
+	}
+	return nil
+}
+
+// WriteHealthMetrics delegates to the reconciler, so adminService satisfies
+// MetricsWriter.
+func (l *adminService) WriteHealthMetrics(w io.Writer) error {
+	return l.health.WriteHealthMetrics(w)
+}
+
+func phaseMetricValue(p healthPhase) int {
+	switch p {
+	case phaseHealthy:
+		return 1
+	case phaseUnhealthy:
+		return 2
+	default:
+		return 0
+	}
+}
+
+func splitKey(key string) (namespace, name string) {
+	for i := 0; i < len(key); i++ {
+		if key[i] == '/' {
+			return key[:i], key[i+1:]
+		}
+	}
+	return "", key
+}
@@ -3,7 +3,9 @@ package admin
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"net"
 	"time"
 
 	"google.golang.org/grpc"
@@ -16,6 +18,7 @@ import (
 	"github.com/vercel/bridge/pkg/k8s/namespace"
 	"github.com/vercel/bridge/pkg/k8s/portforward"
 	"github.com/vercel/bridge/pkg/k8s/resources"
+	"github.com/vercel/bridge/pkg/probe"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -35,6 +38,23 @@ type LocalConfig struct {
 	// ServiceAccountNamespace is the namespace of the administrator's SA.
 	// Defaults to "bridge".
 	ServiceAccountNamespace string
+	// MaxSnapshotsPerDevice caps how many snapshots a device may keep per
+	// bridge; SnapshotBridge evicts the oldest once this is exceeded.
+	// Defaults to 5.
+	MaxSnapshotsPerDevice int
+	// SnapshotTTL evicts a snapshot once it's older than this, regardless
+	// of MaxSnapshotsPerDevice. Defaults to 14 days.
+	SnapshotTTL time.Duration
+	// UseAPIServerDialer tunnels proxy-pod connections (metadata fetch,
+	// PortForward, health probes) through the kube-apiserver's
+	// pods/portforward SPDY subresource via a Service endpoint instead of
+	// dialing a specific pod directly, so they work from laptops that only
+	// have kubectl access and no direct route to the pod network.
+	UseAPIServerDialer bool
+	// Logger carries fixed contextual attributes (e.g. device_id, bridge)
+	// onto every log line the local Service emits. Defaults to
+	// slog.Default() when nil.
+	Logger *slog.Logger
 }
 
 var _ Service = (*adminService)(nil)
@@ -45,6 +65,9 @@ type adminService struct {
 	client     kubernetes.Interface
 	restConfig *rest.Config
 	config     LocalConfig
+	health     *healthReconciler
+	snapshots  *snapshotStore
+	logger     *slog.Logger
 }
 
 // NewService creates a local Service that performs operations using the current
@@ -73,11 +96,35 @@ func NewLocalFromClient(client kubernetes.Interface, restCfg *rest.Config, cfg L
 	if cfg.ServiceAccountNamespace == "" {
 		cfg.ServiceAccountNamespace = "bridge"
 	}
-	return &adminService{
+	if cfg.MaxSnapshotsPerDevice == 0 {
+		cfg.MaxSnapshotsPerDevice = 5
+	}
+	if cfg.SnapshotTTL == 0 {
+		cfg.SnapshotTTL = 14 * 24 * time.Hour
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	svc := &adminService{
 		client:     client,
 		restConfig: restCfg,
 		config:     cfg,
+		logger:     logger,
+		snapshots: newSnapshotStore(client, restCfg, snapshotEviction{
+			MaxPerDevice: cfg.MaxSnapshotsPerDevice,
+			TTL:          cfg.SnapshotTTL,
+		}, logger),
 	}
+	svc.health = newHealthReconciler(svc)
+	return svc
+}
+
+// RunHealthReconciler starts the background bridge health reconciler,
+// blocking until ctx is canceled. It implements HealthRunner so the
+// administrator process can start it alongside the gRPC server.
+func (l *adminService) RunHealthReconciler(ctx context.Context) {
+	l.health.Run(ctx)
 }
 
 func (l *adminService) CreateBridge(ctx context.Context, req *bridgev1.CreateBridgeRequest) (*bridgev1.CreateBridgeResponse, error) {
@@ -88,10 +135,11 @@ func (l *adminService) CreateBridge(ctx context.Context, req *bridgev1.CreateBri
 		return nil, fmt.Errorf("source_namespace is required when source_deployment is set")
 	}
 
-	logger := slog.With("device_id", req.DeviceId)
+	logger := l.logger.With("device_id", req.DeviceId)
 
 	var result *resources.CopyResult
 	var targetNS string
+	var probeSpecs []probe.Spec
 
 	if req.SourceDeployment != "" {
 		targetNS = req.SourceNamespace
@@ -117,6 +165,18 @@ func (l *adminService) CreateBridge(ctx context.Context, req *bridgev1.CreateBri
 		if err != nil {
 			return nil, err
 		}
+
+		// Normalize the source deployment's HTTP probes/preStop hook so the
+		// devcontainer feature can forward them with the same host/port
+		// semantics kubelet would use. A failure here shouldn't fail bridge
+		// creation — it just means the feature falls back to no probe
+		// forwarding.
+		srcDeploy, err := l.client.AppsV1().Deployments(req.SourceNamespace).Get(ctx, req.SourceDeployment, metav1.GetOptions{})
+		if err != nil {
+			logger.Warn("Failed to read source deployment for probe config", "error", err)
+		} else {
+			probeSpecs = probe.FromPodSpec(&srcDeploy.Spec.Template.Spec)
+		}
 	} else {
 		// No source deployment â€” fall back to device namespace with simple deployment.
 		targetNS = identity.NamespaceForDevice(req.DeviceId)
@@ -150,13 +210,19 @@ func (l *adminService) CreateBridge(ctx context.Context, req *bridgev1.CreateBri
 	if err != nil {
 		logger.Warn("Failed to get pod for metadata", "pod", podName, "error", err)
 	} else if pod.Status.PodIP != "" {
-		if md, err := l.fetchProxyMetadata(ctx, targetNS, podName, int(result.PodPort)); err != nil {
+		if md, err := l.fetchProxyMetadata(ctx, targetNS, podName, result.DeploymentName, int(result.PodPort)); err != nil {
 			logger.Warn("GetMetadata call failed", "pod", podName, "error", err)
 		} else {
 			envVars = md
 		}
 	}
 
+	if req.FromSnapshot != "" {
+		if err := l.snapshots.restore(ctx, targetNS, result.DeploymentName, req.FromSnapshot, podName); err != nil {
+			logger.Warn("Failed to restore from snapshot, continuing with a fresh workspace", "snapshot", req.FromSnapshot, "error", err)
+		}
+	}
+
 	return &bridgev1.CreateBridgeResponse{
 		Namespace:        targetNS,
 		PodName:          podName,
@@ -165,9 +231,40 @@ func (l *adminService) CreateBridge(ctx context.Context, req *bridgev1.CreateBri
 		EnvVars:          envVars,
 		VolumeMountPaths: result.VolumeMountPaths,
 		AppPorts:         result.AppPorts,
+		Probes:           toProtoProbes(probeSpecs),
 	}, nil
 }
 
+// toProtoProbes converts probe.FromPodSpec's normalized handlers to the
+// bridgev1.ProbeSpec wire format generateDevcontainerConfig forwards to the
+// bridge feature's "probes" option.
+func toProtoProbes(specs []probe.Spec) []*bridgev1.ProbeSpec {
+	out := make([]*bridgev1.ProbeSpec, 0, len(specs))
+	for _, s := range specs {
+		out = append(out, &bridgev1.ProbeSpec{
+			ContainerName: s.ContainerName,
+			Liveness:      toProtoHandler(s.Liveness),
+			Readiness:     toProtoHandler(s.Readiness),
+			Startup:       toProtoHandler(s.Startup),
+			PreStop:       toProtoHandler(s.PreStop),
+		})
+	}
+	return out
+}
+
+func toProtoHandler(h *probe.Handler) *bridgev1.ProbeHandler {
+	if h == nil {
+		return nil
+	}
+	return &bridgev1.ProbeHandler{
+		Scheme:  h.Scheme,
+		Host:    h.Host,
+		Port:    h.Port,
+		Path:    h.Path,
+		Headers: h.Headers,
+	}
+}
+
 func (l *adminService) ListBridges(ctx context.Context, req *bridgev1.ListBridgesRequest) (*bridgev1.ListBridgesResponse, error) {
 	if req.DeviceId == "" {
 		return nil, fmt.Errorf("device_id is required")
@@ -190,12 +287,47 @@ func (l *adminService) ListBridges(ctx context.Context, req *bridgev1.ListBridge
 			Namespace:        d.Namespace,
 			DeploymentName:   d.Name,
 			CreatedAt:        d.CreationTimestamp.Format(time.RFC3339),
+			Health:           l.health.Health(d.Namespace, d.Name),
 		})
 	}
 
 	return &bridgev1.ListBridgesResponse{Bridges: bridges}, nil
 }
 
+// HealthCheck immediately probes a single bridge rather than waiting for the
+// reconciler's next tick, so callers like `bridge health <name>` see a
+// fresh result instead of a possibly-stale cached one.
+func (l *adminService) HealthCheck(ctx context.Context, req *bridgev1.HealthCheckRequest) (*bridgev1.HealthCheckResponse, error) {
+	if req.Namespace == "" || req.Name == "" {
+		return nil, fmt.Errorf("namespace and name are required")
+	}
+
+	health, err := l.health.Probe(ctx, req.Namespace, req.Name)
+	if err != nil {
+		return nil, fmt.Errorf("probe bridge %s/%s: %w", req.Namespace, req.Name, err)
+	}
+	return &bridgev1.HealthCheckResponse{Health: health}, nil
+}
+
+// WatchBridges long-polls ListBridges on a fixed interval and pushes each
+// snapshot to the returned stream until ctx is canceled. It's a long-poll
+// implementation: the local admin
+// has no cheaper change-notification signal than re-listing, so it always
+// behaves as if streaming were unavailable.
+func (l *adminService) WatchBridges(ctx context.Context, req *bridgev1.WatchBridgesRequest) (BridgeWatchStream, error) {
+	if req.DeviceId == "" {
+		return nil, fmt.Errorf("device_id is required")
+	}
+
+	return newPollWatchStream(ctx, watchPollInterval, func() (*bridgev1.WatchBridgesResponse, error) {
+		resp, err := l.ListBridges(ctx, &bridgev1.ListBridgesRequest{DeviceId: req.DeviceId})
+		if err != nil {
+			return nil, err
+		}
+		return &bridgev1.WatchBridgesResponse{Bridges: resp.Bridges}, nil
+	}), nil
+}
+
 func (l *adminService) DeleteBridge(ctx context.Context, req *bridgev1.DeleteBridgeRequest) (*bridgev1.DeleteBridgeResponse, error) {
 	if req.DeviceId == "" {
 		return nil, fmt.Errorf("device_id is required")
@@ -207,7 +339,7 @@ func (l *adminService) DeleteBridge(ctx context.Context, req *bridgev1.DeleteBri
 		return nil, fmt.Errorf("namespace is required")
 	}
 
-	slog.Info("Deleting bridge", "device_id", req.DeviceId, "namespace", req.Namespace, "name", req.Name)
+	l.logger.With("device_id", req.DeviceId).Info("Deleting bridge", "namespace", req.Namespace, "name", req.Name)
 
 	if err := resources.DeleteBridgeResources(ctx, l.client, req.Namespace, req.Name); err != nil {
 		return nil, err
@@ -216,18 +348,130 @@ func (l *adminService) DeleteBridge(ctx context.Context, req *bridgev1.DeleteBri
 	return &bridgev1.DeleteBridgeResponse{}, nil
 }
 
+// PortForward dials the target pod's port through the kube REST client's
+// SPDY port-forward transport and proxies frames between it and stream in
+// both directions, until the client closes the stream or the pod connection
+// errors out.
+func (l *adminService) PortForward(ctx context.Context, stream PortForwardStream) error {
+	open, err := stream.Recv()
+	if err != nil {
+		return fmt.Errorf("read open frame: %w", err)
+	}
+	if open.GetOpen() == nil {
+		return fmt.Errorf("expected an open frame, got %T", open.GetFrame())
+	}
+	req := open.GetOpen()
+
+	podName, err := kube.WaitForPod(ctx, l.client, req.Namespace, meta.DeploymentSelector(req.Name), 10*time.Second)
+	if err != nil {
+		return fmt.Errorf("find pod for bridge %s/%s: %w", req.Namespace, req.Name, err)
+	}
+
+	dialer, err := l.newProxyDialer(req.Namespace, req.Name, podName, int(req.Port))
+	if err != nil {
+		return err
+	}
+
+	conn, err := dialer.DialContext(ctx, "")
+	if err != nil {
+		return fmt.Errorf("dial pod port %d: %w", req.Port, err)
+	}
+	defer conn.Close()
+
+	errCh := make(chan error, 2)
+
+	// pod -> client
+	go func() {
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := conn.Read(buf)
+			if n > 0 {
+				if sendErr := stream.Send(&bridgev1.PortForwardFrame{
+					Frame: &bridgev1.PortForwardFrame_Data{Data: append([]byte(nil), buf[:n]...)},
+				}); sendErr != nil {
+					errCh <- sendErr
+					return
+				}
+			}
+			if err != nil {
+				if err == io.EOF {
+					_ = stream.Send(&bridgev1.PortForwardFrame{Frame: &bridgev1.PortForwardFrame_Close{Close: true}})
+					errCh <- nil
+					return
+				}
+				errCh <- err
+				return
+			}
+		}
+	}()
+
+	// client -> pod
+	go func() {
+		for {
+			frame, err := stream.Recv()
+			if err != nil {
+				if err == io.EOF {
+					errCh <- nil
+					return
+				}
+				errCh <- err
+				return
+			}
+			switch f := frame.GetFrame().(type) {
+			case *bridgev1.PortForwardFrame_Data:
+				if _, err := conn.Write(f.Data); err != nil {
+					errCh <- err
+					return
+				}
+			case *bridgev1.PortForwardFrame_Close:
+				errCh <- nil
+				return
+			}
+		}
+	}()
+
+	return <-errCh
+}
+
 // Close releases resources. No-op for local admin.
 func (l *adminService) Close() error {
 	return nil
 }
 
-func (l *adminService) fetchProxyMetadata(ctx context.Context, ns, podName string, port int) (map[string]string, error) {
+// proxyDialer is satisfied by both portforward.NewDialer and
+// portforward.NewServiceDialer's return types.
+type proxyDialer interface {
+	DialContext(ctx context.Context, addr string) (net.Conn, error)
+}
+
+// newProxyDialer returns the dialer CreateBridge's metadata fetch,
+// PortForward, and the health reconciler use to reach a bridge's proxy pod.
+// When UseAPIServerDialer is set it resolves serviceName to a ready endpoint
+// through the kube-apiserver's portforward subresource instead of requiring
+// a direct route to podName's pod IP.
+func (l *adminService) newProxyDialer(namespace, serviceName, podName string, port int) (proxyDialer, error) {
+	if l.config.UseAPIServerDialer {
+		dialer, err := portforward.NewServiceDialer(l.restConfig, l.client, namespace, serviceName, port)
+		if err != nil {
+			return nil, fmt.Errorf("create service port-forward dialer: %w", err)
+		}
+		return dialer, nil
+	}
+
+	dialer, err := portforward.NewDialer(l.restConfig, l.client, namespace, podName, port)
+	if err != nil {
+		return nil, fmt.Errorf("create port-forward dialer: %w", err)
+	}
+	return dialer, nil
+}
+
+func (l *adminService) fetchProxyMetadata(ctx context.Context, ns, podName, serviceName string, port int) (map[string]string, error) {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	dialer, err := portforward.NewDialer(l.restConfig, l.client, ns, podName, port)
+	dialer, err := l.newProxyDialer(ns, serviceName, podName, port)
 	if err != nil {
-		return nil, fmt.Errorf("create port-forward dialer: %w", err)
+		return nil, err
 	}
 
 	conn, err := grpc.NewClient("passthrough:///pod",
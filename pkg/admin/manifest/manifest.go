@@ -0,0 +1,170 @@
+// Package manifest lets a bridge be declared alongside its app as a
+// bridge.yaml: a multi-document Kubernetes YAML describing the Deployment
+// (plus any Service, ConfigMap, and PersistentVolumeClaim it depends on) that
+// bridge create should stand up and then mirror. It reuses
+// pkg/k8s/manifests for document parsing and server-side apply, and drives
+// admin.Service exactly the same way the deployment-name form of bridge
+// create does.
+package manifest
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+
+	bridgev1 "github.com/vercel/bridge/api/go/bridge/v1"
+	"github.com/vercel/bridge/pkg/admin"
+	"github.com/vercel/bridge/pkg/k8s/manifests"
+)
+
+// supportedKinds are the resource kinds a bridge.yaml may declare. Anything
+// else (a CustomResourceDefinition, a Job, ...) is rejected up front, since
+// Apply only knows how to derive a bridge's source Deployment from this set.
+var supportedKinds = map[string]bool{
+	"Deployment":            true,
+	"Pod":                   true,
+	"Service":               true,
+	"ConfigMap":             true,
+	"PersistentVolumeClaim": true,
+}
+
+// Options configures CreateFromManifest.
+type Options struct {
+	// FieldManager is passed through to manifests.ApplyOptions. Defaults to
+	// "bridge" (manifests.DefaultApplyOptions' default) when empty.
+	FieldManager string
+	// Force recreates an existing bridge for the same source deployment
+	// instead of failing, mirroring the --force flag on the deployment-name
+	// form of bridge create.
+	Force bool
+	// FromSnapshot restores the new bridge's workspace from a previously
+	// captured snapshot name, same as the deployment-name form.
+	FromSnapshot string
+}
+
+// CreateFromManifest reads path as a bridge.yaml, applies its Deployment (and
+// any Service/ConfigMap/PersistentVolumeClaim siblings) to the cluster via
+// server-side apply, and then creates a bridge mirroring the applied
+// Deployment through svc. cfg is the kubeconfig-derived REST config used to
+// reach the cluster directly for the apply step, independent of whether svc
+// is a remote administrator client or the local kubeconfig fallback.
+func CreateFromManifest(ctx context.Context, cfg *rest.Config, svc admin.Service, deviceID, path string, substitutions map[string]string, opts Options) (*bridgev1.CreateBridgeResponse, error) {
+	deployment, err := validate(path, substitutions)
+	if err != nil {
+		return nil, err
+	}
+
+	applyOpts := manifests.DefaultApplyOptions()
+	if opts.FieldManager != "" {
+		applyOpts.FieldManager = opts.FieldManager
+	}
+	if _, err := manifests.ApplyWithOptions(ctx, cfg, path, substitutions, applyOpts); err != nil {
+		return nil, fmt.Errorf("apply %s: %w", path, err)
+	}
+
+	resp, err := svc.CreateBridge(ctx, &bridgev1.CreateBridgeRequest{
+		DeviceId:         deviceID,
+		SourceDeployment: deployment.Name,
+		SourceNamespace:  deployment.Namespace,
+		Force:            opts.Force,
+		FromSnapshot:     opts.FromSnapshot,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create bridge for %s: %w", path, err)
+	}
+	return resp, nil
+}
+
+// Diff renders a kubectl-style dry-run diff of what CreateFromManifest's
+// apply step would change, without creating a bridge.
+func Diff(ctx context.Context, cfg *rest.Config, path string, substitutions map[string]string) (string, error) {
+	if _, err := validate(path, substitutions); err != nil {
+		return "", err
+	}
+	return manifests.Diff(ctx, cfg, path, substitutions)
+}
+
+// validate decodes path's documents as typed Kubernetes objects, rejects any
+// kind outside supportedKinds and any Deployment pod template field
+// validatePodSpec rejects, and returns the manifest's single Deployment (the
+// bridge's source). A bare Pod with no Deployment isn't supported yet:
+// CreateBridge only knows how to mirror a Deployment.
+func validate(path string, substitutions map[string]string) (*appsv1.Deployment, error) {
+	docs, err := manifests.ReadDocuments(path, substitutions)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := scheme.Codecs.UniversalDeserializer()
+	var deployment *appsv1.Deployment
+
+	for i, doc := range docs {
+		obj, gvk, err := decoder.Decode(doc, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("document %d in %s: %w", i, path, err)
+		}
+		if !supportedKinds[gvk.Kind] {
+			return nil, fmt.Errorf("document %d in %s: kind %q is not supported in a bridge manifest", i, path, gvk.Kind)
+		}
+
+		if o, ok := obj.(*appsv1.Deployment); ok {
+			if deployment != nil {
+				return nil, fmt.Errorf("document %d in %s: a bridge manifest may only declare one Deployment", i, path)
+			}
+			if err := validatePodSpec(field.NewPath("spec", "template", "spec"), o.Spec.Template.Spec); err != nil {
+				return nil, fmt.Errorf("document %d in %s: %w", i, path, err)
+			}
+			deployment = o
+		}
+		// Service/ConfigMap/PersistentVolumeClaim/Pod documents need no
+		// field validation: Apply hands them to the cluster as-is.
+	}
+
+	if deployment == nil {
+		return nil, fmt.Errorf("%s: a bridge manifest must declare exactly one Deployment", path)
+	}
+	return deployment, nil
+}
+
+type schedulingField struct {
+	set   bool
+	child string
+}
+
+// validatePodSpec rejects any PodSpec field the bridge administrator, not
+// the manifest author, controls: where the mirrored workload runs (affinity,
+// tolerations, node selection, priority) and as whom (service account, host
+// namespaces). Containers, volumes, imagePullSecrets, securityContext,
+// hostAliases, DNS config, and each container's env/envFrom/volumeMounts/
+// resources/ports/probes/lifecycle all pass through untouched: Apply sends
+// them to the cluster as part of the Deployment it stands up.
+func validatePodSpec(path *field.Path, spec corev1.PodSpec) error {
+	fields := []schedulingField{
+		{spec.Affinity != nil, "affinity"},
+		{len(spec.Tolerations) > 0, "tolerations"},
+		{len(spec.NodeSelector) > 0, "nodeSelector"},
+		{spec.NodeName != "", "nodeName"},
+		{len(spec.TopologySpreadConstraints) > 0, "topologySpreadConstraints"},
+		{spec.SchedulerName != "", "schedulerName"},
+		{spec.ServiceAccountName != "", "serviceAccountName"},
+		{spec.PriorityClassName != "", "priorityClassName"},
+		{spec.Priority != nil, "priority"},
+		{spec.RuntimeClassName != nil, "runtimeClassName"},
+		{spec.HostNetwork, "hostNetwork"},
+		{spec.HostPID, "hostPID"},
+		{spec.HostIPC, "hostIPC"},
+	}
+
+	var errs field.ErrorList
+	for _, f := range fields {
+		if f.set {
+			errs = append(errs, field.Forbidden(path.Child(f.child), "controlled by the bridge administrator, not the manifest"))
+		}
+	}
+	return errs.ToAggregate()
+}
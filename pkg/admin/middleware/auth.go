@@ -0,0 +1,337 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// Authenticator resolves the Principal a request was made as, or an error if
+// it couldn't be authenticated. ctx carries the peer's TLS state (if any)
+// and incoming metadata, the same way it's available inside an interceptor.
+type Authenticator interface {
+	Authenticate(ctx context.Context) (Principal, error)
+}
+
+// AuthenticatorFunc adapts a function to an Authenticator.
+type AuthenticatorFunc func(ctx context.Context) (Principal, error)
+
+func (f AuthenticatorFunc) Authenticate(ctx context.Context) (Principal, error) {
+	return f(ctx)
+}
+
+// ChainAuthenticators tries each Authenticator in order and returns the first
+// Principal one resolves. This lets a server accept either mTLS or an OIDC
+// bearer token on the same listener.
+func ChainAuthenticators(authenticators ...Authenticator) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context) (Principal, error) {
+		var errs []error
+		for _, a := range authenticators {
+			p, err := a.Authenticate(ctx)
+			if err == nil {
+				return p, nil
+			}
+			errs = append(errs, err)
+		}
+		if len(errs) == 0 {
+			return Principal{}, fmt.Errorf("no authenticator configured")
+		}
+		return Principal{}, fmt.Errorf("authentication failed: %w", errs[len(errs)-1])
+	})
+}
+
+// MTLSAuthenticator resolves a Principal from the client certificate
+// presented during the TLS handshake, using grpc.Creds(credentials.NewTLS(...))
+// with ClientAuth set to RequireAndVerifyClientCert on the server. The
+// certificate's Subject CN becomes Principal.Name and its
+// Organization entries become Principal.Groups.
+type MTLSAuthenticator struct{}
+
+func (MTLSAuthenticator) Authenticate(ctx context.Context) (Principal, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return Principal{}, fmt.Errorf("mtls: no peer info in context")
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return Principal{}, fmt.Errorf("mtls: connection is not over TLS")
+	}
+	chains := tlsInfo.State.PeerCertificates
+	if len(chains) == 0 {
+		return Principal{}, fmt.Errorf("mtls: no client certificate presented")
+	}
+	cert := chains[0]
+	return Principal{
+		Name:   cert.Subject.CommonName,
+		Method: "mtls",
+		Groups: cert.Subject.Organization,
+	}, nil
+}
+
+// OIDCConfig configures bearer-token authentication against a single OIDC
+// issuer.
+type OIDCConfig struct {
+	// Issuer is the OIDC issuer URL, e.g. "https://token.actions.example.com".
+	// Its "/.well-known/openid-configuration" document is fetched to
+	// discover the JWKS endpoint each time the keys are refreshed: once
+	// RefreshInterval has passed, or sooner when a token names an unknown kid.
+	Issuer string
+	// RefreshInterval controls how often the JWKS is re-fetched. Defaults
+	// to 10 minutes.
+	RefreshInterval time.Duration
+	// HTTPClient is used for discovery/JWKS requests. Defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+	// Audience, if set, must appear in the token's aud claim. Empty skips
+	// the check.
+	Audience string
+}
+
+// OIDCAuthenticator resolves a Principal from a "Bearer <jwt>" token in the
+// "authorization" metadata, verifying its RS256 signature against the
+// issuer's published JWKS and its iss/exp claims. Principal.Name is the
+// token's "sub" claim and Principal.Groups is its "groups" claim, if
+// present.
+type OIDCAuthenticator struct {
+	cfg OIDCConfig
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time
+}
+
+// NewOIDCAuthenticator returns an OIDCAuthenticator for cfg. It does not
+// fetch the JWKS until the first Authenticate call.
+func NewOIDCAuthenticator(cfg OIDCConfig) *OIDCAuthenticator {
+	if cfg.RefreshInterval == 0 {
+		cfg.RefreshInterval = 10 * time.Minute
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+	return &OIDCAuthenticator{cfg: cfg}
+}
+
+type oidcDiscovery struct {
+	JWKSURI string `json:"jwks_uri"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func (a *OIDCAuthenticator) keyForKid(kid string) (*rsa.PublicKey, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if time.Since(a.fetched) < a.cfg.RefreshInterval {
+		if key, ok := a.keys[kid]; ok {
+			return key, nil
+		}
+	}
+
+	keys, err := a.fetchKeys()
+	if err != nil {
+		return nil, err
+	}
+	a.keys = keys
+	a.fetched = time.Now()
+
+	key, ok := a.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("oidc: no key found for kid %q", kid)
+	}
+	return key, nil
+}
+
+func (a *OIDCAuthenticator) fetchKeys() (map[string]*rsa.PublicKey, error) {
+	issuer := strings.TrimSuffix(a.cfg.Issuer, "/")
+
+	var disc oidcDiscovery
+	if err := getJSON(a.cfg.HTTPClient, issuer+"/.well-known/openid-configuration", &disc); err != nil {
+		return nil, fmt.Errorf("oidc: discovery: %w", err)
+	}
+	if disc.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc: discovery document has no jwks_uri")
+	}
+
+	var set jwks
+	if err := getJSON(a.cfg.HTTPClient, disc.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("oidc: fetch jwks: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		pub, err := rsaPublicKeyFromJWK(k)
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	return keys, nil
+}
+
+func getJSON(client *http.Client, url string, out any) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func rsaPublicKeyFromJWK(k jwk) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("decode modulus: %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("decode exponent: %w", err)
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
+
+type jwtClaims struct {
+	Issuer   string        `json:"iss"`
+	Subject  string        `json:"sub"`
+	Expiry   int64         `json:"exp"`
+	Audience audienceClaim `json:"aud"`
+	Groups   []string      `json:"groups"`
+}
+
+// audienceClaim accepts a JWT "aud" claim that's either a single string or
+// an array of strings, per RFC 7519 §4.1.3.
+type audienceClaim []string
+
+func (a *audienceClaim) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audienceClaim{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = audienceClaim(many)
+	return nil
+}
+
+func (a audienceClaim) contains(want string) bool {
+	for _, v := range a {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *OIDCAuthenticator) Authenticate(ctx context.Context) (Principal, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return Principal{}, fmt.Errorf("oidc: no request metadata")
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return Principal{}, fmt.Errorf("oidc: no authorization header")
+	}
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return Principal{}, fmt.Errorf("oidc: authorization header is not a bearer token")
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, fmt.Errorf("oidc: malformed JWT")
+	}
+
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return Principal{}, fmt.Errorf("oidc: decode header: %w", err)
+	}
+	var hdr struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(header, &hdr); err != nil {
+		return Principal{}, fmt.Errorf("oidc: parse header: %w", err)
+	}
+	if hdr.Alg != "RS256" {
+		return Principal{}, fmt.Errorf("oidc: unsupported signing algorithm %q", hdr.Alg)
+	}
+
+	key, err := a.keyForKid(hdr.Kid)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, fmt.Errorf("oidc: decode signature: %w", err)
+	}
+	signed := parts[0] + "." + parts[1]
+	sum := sha256.Sum256([]byte(signed))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, sum[:], sig); err != nil {
+		return Principal{}, fmt.Errorf("oidc: signature verification failed: %w", err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return Principal{}, fmt.Errorf("oidc: decode payload: %w", err)
+	}
+	var claims jwtClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return Principal{}, fmt.Errorf("oidc: parse claims: %w", err)
+	}
+	if claims.Issuer != a.cfg.Issuer {
+		return Principal{}, fmt.Errorf("oidc: token issuer %q does not match configured issuer %q", claims.Issuer, a.cfg.Issuer)
+	}
+	if claims.Expiry == 0 {
+		return Principal{}, fmt.Errorf("oidc: token has no exp claim")
+	}
+	if time.Now().Unix() > claims.Expiry {
+		return Principal{}, fmt.Errorf("oidc: token expired")
+	}
+	if a.cfg.Audience != "" && !claims.Audience.contains(a.cfg.Audience) {
+		return Principal{}, fmt.Errorf("oidc: token audience %v does not include %q", []string(claims.Audience), a.cfg.Audience)
+	}
+	if claims.Subject == "" {
+		return Principal{}, fmt.Errorf("oidc: token has no subject claim")
+	}
+
+	return Principal{
+		Name:   claims.Subject,
+		Method: "oidc",
+		Groups: claims.Groups,
+	}, nil
+}
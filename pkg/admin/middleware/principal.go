@@ -0,0 +1,40 @@
+// Package middleware provides a configurable chain of gRPC interceptors for
+// the bridge administrator server: authentication (mTLS or OIDC bearer
+// tokens), authorization against a Policy, per-principal rate limiting, and
+// audit logging. Administrator wires it in by building a Config and
+// installing Config.ServerOptions; a bare grpc.NewServer() with no chain lets anything
+// that can reach the listen address create or delete bridges cluster-wide.
+package middleware
+
+import "context"
+
+// Principal identifies the caller a request was authenticated as, regardless
+// of which Authenticator produced it.
+type Principal struct {
+	// Name is the principal's identity: the client certificate's CN for
+	// mTLS, or the "sub" claim for an OIDC bearer token.
+	Name string
+	// Method is how the principal was authenticated, e.g. "mtls" or "oidc".
+	Method string
+	// Groups are role/group memberships a Policy can match against, taken
+	// from the certificate's Subject Organization entries or an OIDC
+	// "groups" claim.
+	Groups []string
+}
+
+type principalKey struct{}
+
+// withPrincipal returns a context carrying principal, retrievable with
+// PrincipalFromContext.
+func withPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the authenticated caller for ctx. It's only
+// populated for RPCs that passed through this package's interceptors, so
+// Service implementations that also run locally (no administrator process in
+// front of them) will see ok == false.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter enforces a per-principal token bucket: each principal accrues
+// up to Burst tokens at RefillPerSecond per second, and a request is allowed
+// only if a token is available.
+type RateLimiter struct {
+	refillPerSecond float64
+	burst           float64
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing burst requests immediately,
+// then refillPerSecond requests per second thereafter, per principal name.
+func NewRateLimiter(refillPerSecond float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		refillPerSecond: refillPerSecond,
+		burst:           float64(burst),
+		buckets:         make(map[string]*bucket),
+	}
+}
+
+// Allow reports whether principal has a token available, consuming one if
+// so.
+func (r *RateLimiter) Allow(principal string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.buckets[principal]
+	if !ok {
+		b = &bucket{tokens: r.burst, lastRefill: time.Now()}
+		r.buckets[principal] = b
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	b.tokens = min(r.burst, b.tokens+elapsed*r.refillPerSecond)
+	b.lastRefill = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,71 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+
+	bridgev1 "github.com/vercel/bridge/api/go/bridge/v1"
+)
+
+// Pipe proxies conn to port on the named bridge's pod through the
+// administrator's PortForward RPC, blocking until either side closes. It
+// lets a CLI command treat the administrator as the sole ingress for a
+// bridge instead of requiring a separately-deployed proxy pod: accept a
+// local connection, then call Pipe(ctx, adm, ns, name, port, conn).
+func Pipe(ctx context.Context, svc Service, namespace, name string, port int32, conn net.Conn) error {
+	return svc.PortForward(ctx, &connStream{
+		conn: conn,
+		open: &bridgev1.PortForwardFrame{
+			Frame: &bridgev1.PortForwardFrame_Open{
+				Open: &bridgev1.PortForwardOpen{
+					Namespace: namespace,
+					Name:      name,
+					Port:      port,
+				},
+			},
+		},
+	})
+}
+
+// connStream adapts a net.Conn to the PortForwardStream interface: the
+// first Recv returns the open frame describing the target, every Recv
+// after that wraps a read off conn, and Send writes Data frames back to
+// conn.
+type connStream struct {
+	conn net.Conn
+	open *bridgev1.PortForwardFrame
+	buf  [32 * 1024]byte
+}
+
+func (s *connStream) Recv() (*bridgev1.PortForwardFrame, error) {
+	if s.open != nil {
+		open := s.open
+		s.open = nil
+		return open, nil
+	}
+
+	n, err := s.conn.Read(s.buf[:])
+	if n > 0 {
+		return &bridgev1.PortForwardFrame{
+			Frame: &bridgev1.PortForwardFrame_Data{Data: append([]byte(nil), s.buf[:n]...)},
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, io.ErrNoProgress
+}
+
+func (s *connStream) Send(frame *bridgev1.PortForwardFrame) error {
+	switch f := frame.GetFrame().(type) {
+	case *bridgev1.PortForwardFrame_Data:
+		_, err := s.conn.Write(f.Data)
+		return err
+	case *bridgev1.PortForwardFrame_Close:
+		return s.conn.Close()
+	default:
+		return fmt.Errorf("unexpected frame type %T", f)
+	}
+}
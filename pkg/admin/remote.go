@@ -3,8 +3,10 @@ package admin
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
 
@@ -18,13 +20,46 @@ type remoteAdmin struct {
 	client bridgev1.AdministratorServiceClient
 }
 
+// ClientOption configures the gRPC connection NewClient creates, e.g. to
+// present client TLS certificates or a bearer token to an administrator
+// protected by pkg/admin/middleware. Construct these with pkg/admin/client
+// rather than directly.
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	dialOpts []grpc.DialOption
+}
+
+// WithTransportCredentials makes NewClient dial using creds instead of a
+// plaintext connection.
+func WithTransportCredentials(creds credentials.TransportCredentials) ClientOption {
+	return func(o *clientOptions) {
+		o.dialOpts = append(o.dialOpts, grpc.WithTransportCredentials(creds))
+	}
+}
+
+// WithPerRPCCredentials attaches creds (e.g. a bearer token) to every RPC
+// NewClient's connection makes.
+func WithPerRPCCredentials(creds credentials.PerRPCCredentials) ClientOption {
+	return func(o *clientOptions) {
+		o.dialOpts = append(o.dialOpts, grpc.WithPerRPCCredentials(creds))
+	}
+}
+
 // NewClient creates a remote Service that connects to the administrator gRPC
 // server at the given address (e.g. "k8spf:///administrator.bridge:9090").
-func NewClient(addr string) (Service, error) {
+// Connections are plaintext unless opts supplies transport credentials.
+func NewClient(addr string, opts ...ClientOption) (Service, error) {
+	var co clientOptions
+	for _, opt := range opts {
+		opt(&co)
+	}
+
 	builder := k8spf.NewBuilder(k8spf.BuilderConfig{})
-	conn, err := grpc.NewClient(addr,
-		append(builder.DialOptions(), grpc.WithTransportCredentials(insecure.NewCredentials()))...,
-	)
+	dialOpts := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, builder.DialOptions()...)
+	dialOpts = append(dialOpts, co.dialOpts...)
+
+	conn, err := grpc.NewClient(addr, dialOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to administrator: %w", err)
 	}
@@ -58,6 +93,128 @@ func (r *remoteAdmin) DeleteBridge(ctx context.Context, req *bridgev1.DeleteBrid
 	return resp, nil
 }
 
+// PortForward opens the streaming PortForward RPC against the remote
+// administrator and relays frames between it and stream until either side
+// closes or errors.
+func (r *remoteAdmin) PortForward(ctx context.Context, stream PortForwardStream) error {
+	rpcStream, err := r.client.PortForward(ctx)
+	if err != nil {
+		return userError(err)
+	}
+
+	open, err := stream.Recv()
+	if err != nil {
+		return fmt.Errorf("read open frame: %w", err)
+	}
+	if err := rpcStream.Send(open); err != nil {
+		return userError(err)
+	}
+
+	errCh := make(chan error, 2)
+
+	go func() {
+		for {
+			frame, err := rpcStream.Recv()
+			if err != nil {
+				errCh <- userErrorOrEOF(err)
+				return
+			}
+			if err := stream.Send(frame); err != nil {
+				errCh <- err
+				return
+			}
+		}
+	}()
+
+	go func() {
+		for {
+			frame, err := stream.Recv()
+			if err != nil {
+				errCh <- userErrorOrEOF(err)
+				return
+			}
+			if err := rpcStream.Send(frame); err != nil {
+				errCh <- userError(err)
+				return
+			}
+		}
+	}()
+
+	return <-errCh
+}
+
+// HealthCheck calls the remote administrator's HealthCheck RPC.
+func (r *remoteAdmin) HealthCheck(ctx context.Context, req *bridgev1.HealthCheckRequest) (*bridgev1.HealthCheckResponse, error) {
+	resp, err := r.client.HealthCheck(ctx, req)
+	if err != nil {
+		return nil, userError(err)
+	}
+	return resp, nil
+}
+
+// ConfigureHealth calls the remote administrator's ConfigureHealth RPC.
+func (r *remoteAdmin) ConfigureHealth(ctx context.Context, req *bridgev1.ConfigureHealthRequest) (*bridgev1.ConfigureHealthResponse, error) {
+	resp, err := r.client.ConfigureHealth(ctx, req)
+	if err != nil {
+		return nil, userError(err)
+	}
+	return resp, nil
+}
+
+// HealthHistory calls the remote administrator's HealthHistory RPC.
+func (r *remoteAdmin) HealthHistory(ctx context.Context, req *bridgev1.HealthHistoryRequest) (*bridgev1.HealthHistoryResponse, error) {
+	resp, err := r.client.HealthHistory(ctx, req)
+	if err != nil {
+		return nil, userError(err)
+	}
+	return resp, nil
+}
+
+// WatchBridges opens the remote administrator's streaming WatchBridges RPC
+// and returns it directly — the generated client stream already satisfies
+// BridgeWatchStream.
+func (r *remoteAdmin) WatchBridges(ctx context.Context, req *bridgev1.WatchBridgesRequest) (BridgeWatchStream, error) {
+	stream, err := r.client.WatchBridges(ctx, req)
+	if err != nil {
+		return nil, userError(err)
+	}
+	return stream, nil
+}
+
+// SnapshotBridge calls the remote administrator's SnapshotBridge RPC.
+func (r *remoteAdmin) SnapshotBridge(ctx context.Context, req *bridgev1.SnapshotBridgeRequest) (*bridgev1.SnapshotBridgeResponse, error) {
+	resp, err := r.client.SnapshotBridge(ctx, req)
+	if err != nil {
+		return nil, userError(err)
+	}
+	return resp, nil
+}
+
+// RestoreBridge calls the remote administrator's RestoreBridge RPC.
+func (r *remoteAdmin) RestoreBridge(ctx context.Context, req *bridgev1.RestoreBridgeRequest) (*bridgev1.RestoreBridgeResponse, error) {
+	resp, err := r.client.RestoreBridge(ctx, req)
+	if err != nil {
+		return nil, userError(err)
+	}
+	return resp, nil
+}
+
+// ListSnapshots calls the remote administrator's ListSnapshots RPC.
+func (r *remoteAdmin) ListSnapshots(ctx context.Context, req *bridgev1.ListSnapshotsRequest) (*bridgev1.ListSnapshotsResponse, error) {
+	resp, err := r.client.ListSnapshots(ctx, req)
+	if err != nil {
+		return nil, userError(err)
+	}
+	return resp, nil
+}
+
+func userErrorOrEOF(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	return userError(err)
+}
+
 // Close releases the gRPC connection.
 func (r *remoteAdmin) Close() error {
 	if r.conn != nil {
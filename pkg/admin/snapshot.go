@@ -0,0 +1,305 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/vercel/bridge/pkg/k8s/meta"
+	"github.com/vercel/bridge/pkg/sandbox"
+)
+
+const (
+	// snapshotConfigMapPrefix namespaces the ConfigMaps snapshotStore uses
+	// to record snapshot metadata, so they're easy to tell apart from any
+	// ConfigMap the bridged Deployment itself owns.
+	snapshotConfigMapPrefix = "bridge-snapshot-"
+
+	// labelSnapshotName records the human-chosen snapshot name on its
+	// ConfigMap, alongside meta.LabelDeviceID and meta.LabelBridgeDeployment.
+	labelSnapshotName = "vercel.sh/bridge-snapshot-name"
+
+	// snapshotMethodCheckpoint/Tar record which capture path produced a
+	// snapshot, since restore needs to know how to rehydrate it.
+	snapshotMethodCheckpoint = "checkpoint"
+	snapshotMethodTar        = "tar"
+
+	// proxyContainerName is the bridge proxy pod's single container, the
+	// target of both the kubelet checkpoint call and the tar fallback exec.
+	proxyContainerName = "proxy"
+
+	// maxTarSnapshotBytes caps the tar fallback to what comfortably fits in
+	// a ConfigMap (the API server enforces a ~1MiB total object size). This
+	// path is meant for small workspaces; anything bigger needs the CRIU
+	// checkpoint path, which this package doesn't have an external blob
+	// store to spill large images to.
+	maxTarSnapshotBytes = 900 * 1024
+)
+
+// snapshotEviction configures how many Snapshots a device may keep per
+// bridge and how long an unused one lives before it's pruned.
+type snapshotEviction struct {
+	MaxPerDevice int
+	TTL          time.Duration
+}
+
+// snapshotStore persists Snapshots as labeled ConfigMaps in the bridge's own
+// namespace, following the same "Kubernetes object as the database" pattern
+// the rest of this package uses for bridge state (see healthReconciler).
+type snapshotStore struct {
+	client     kubernetes.Interface
+	restConfig *rest.Config
+	eviction   snapshotEviction
+	logger     *slog.Logger
+}
+
+func newSnapshotStore(client kubernetes.Interface, restConfig *rest.Config, eviction snapshotEviction, logger *slog.Logger) *snapshotStore {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &snapshotStore{client: client, restConfig: restConfig, eviction: eviction, logger: logger}
+}
+
+func snapshotConfigMapName(bridgeName, snapshotName string) string {
+	return snapshotConfigMapPrefix + bridgeName + "-" + snapshotName
+}
+
+// create records snap as a ConfigMap, evicting older snapshots of the same
+// bridge first to make room under the configured eviction policy. extra
+// carries capture-method-specific fields (e.g. the node and path a
+// checkpoint landed at) that get merged into the ConfigMap's Data alongside
+// the common fields.
+func (s *snapshotStore) create(ctx context.Context, namespace, deviceID string, snap sandbox.Snapshot, method string, binaryData map[string][]byte, extra map[string]string) error {
+	if err := s.evict(ctx, namespace, deviceID, snap.SandboxID); err != nil {
+		s.logger.Warn("snapshot eviction failed, continuing anyway", "error", err)
+	}
+
+	data := map[string]string{
+		"id":        snap.ID,
+		"sandboxId": snap.SandboxID,
+		"name":      snap.Name,
+		"createdAt": snap.CreatedAt.Format(time.RFC3339),
+		"method":    method,
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      snapshotConfigMapName(snap.SandboxID, snap.Name),
+			Namespace: namespace,
+			Labels: map[string]string{
+				meta.LabelDeviceID:         deviceID,
+				meta.LabelBridgeDeployment: snap.SandboxID,
+				labelSnapshotName:          snap.Name,
+			},
+		},
+		Data:       data,
+		BinaryData: binaryData,
+	}
+	_, err := s.client.CoreV1().ConfigMaps(namespace).Create(ctx, cm, metav1.CreateOptions{})
+	return err
+}
+
+// get fetches one snapshot's ConfigMap by bridge and snapshot name.
+func (s *snapshotStore) get(ctx context.Context, namespace, bridgeName, snapshotName string) (*corev1.ConfigMap, error) {
+	return s.client.CoreV1().ConfigMaps(namespace).Get(ctx, snapshotConfigMapName(bridgeName, snapshotName), metav1.GetOptions{})
+}
+
+// list returns every snapshot ConfigMap for deviceID, optionally narrowed to
+// one bridge. An empty namespace lists across all namespaces, matching how
+// adminService.ListBridges lists Deployments("") for a device-wide view.
+func (s *snapshotStore) list(ctx context.Context, namespace, deviceID, bridgeName string) ([]corev1.ConfigMap, error) {
+	selector := meta.LabelDeviceID + "=" + deviceID
+	if bridgeName != "" {
+		selector += "," + meta.LabelBridgeDeployment + "=" + bridgeName
+	}
+	cms, err := s.client.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	return cms.Items, nil
+}
+
+// evict deletes snapshots of bridgeName older than s.eviction.TTL, then
+// deletes the oldest survivors until at most MaxPerDevice-1 remain, leaving
+// room for the one about to be created.
+func (s *snapshotStore) evict(ctx context.Context, namespace, deviceID, bridgeName string) error {
+	cms, err := s.list(ctx, namespace, deviceID, bridgeName)
+	if err != nil {
+		return err
+	}
+	sort.Slice(cms, func(i, j int) bool {
+		return cms[i].CreationTimestamp.Before(&cms[j].CreationTimestamp)
+	})
+
+	toDelete := map[string]bool{}
+	now := time.Now()
+	if s.eviction.TTL > 0 {
+		for _, cm := range cms {
+			if now.Sub(cm.CreationTimestamp.Time) > s.eviction.TTL {
+				toDelete[cm.Name] = true
+			}
+		}
+	}
+
+	remaining := len(cms) - len(toDelete)
+	if s.eviction.MaxPerDevice > 0 && remaining >= s.eviction.MaxPerDevice {
+		over := remaining - s.eviction.MaxPerDevice + 1
+		for _, cm := range cms {
+			if over <= 0 {
+				break
+			}
+			if toDelete[cm.Name] {
+				continue
+			}
+			toDelete[cm.Name] = true
+			over--
+		}
+	}
+
+	for name := range toDelete {
+		if err := s.client.CoreV1().ConfigMaps(namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+			s.logger.Warn("failed to evict snapshot", "configmap", name, "error", err)
+		}
+	}
+	return nil
+}
+
+// restore rehydrates snapshotName into podName's workspace. Checkpoint
+// snapshots can't be restored automatically yet (see checkpointContainer's
+// doc comment); only the tar fallback is.
+func (s *snapshotStore) restore(ctx context.Context, namespace, bridgeName, snapshotName, podName string) error {
+	cm, err := s.get(ctx, namespace, bridgeName, snapshotName)
+	if err != nil {
+		return fmt.Errorf("get snapshot %q: %w", snapshotName, err)
+	}
+
+	switch cm.Data["method"] {
+	case snapshotMethodTar:
+		return s.restoreWorkspace(ctx, namespace, podName, proxyContainerName, cm.BinaryData["workspace.tar.gz"])
+	case snapshotMethodCheckpoint:
+		return fmt.Errorf("snapshot %q was captured via kubelet checkpoint at %s on node %s; Kubernetes has no public restore API for checkpoint images yet, so it must be replayed onto the node's container runtime manually", snapshotName, cm.Data["checkpointPath"], cm.Data["nodeName"])
+	default:
+		return fmt.Errorf("snapshot %q has unrecognized capture method %q", snapshotName, cm.Data["method"])
+	}
+}
+
+// checkpointContainer asks kubelet, via the API server's node proxy
+// subresource, to checkpoint container on pod into a CRIU-style image on
+// the node's disk, returning the path kubelet reports. This needs the
+// kubelet ContainerCheckpoint feature gate enabled on nodeName; callers
+// should fall back to tarWorkspace when it returns an error.
+//
+// There's no corresponding "restore from checkpoint" kubelet API as of this
+// writing — checkpoint images are meant for forensic export, not live
+// redeployment — so RestoreBridge can only automatically rehydrate
+// tar-fallback snapshots (see snapshotStore.restore).
+func (s *snapshotStore) checkpointContainer(ctx context.Context, namespace, pod, container, nodeName string) (checkpointPath string, err error) {
+	raw, err := s.client.CoreV1().RESTClient().
+		Post().
+		Resource("nodes").
+		Name(nodeName).
+		SubResource("proxy").
+		Suffix("checkpoint", namespace, pod, container).
+		DoRaw(ctx)
+	if err != nil {
+		return "", fmt.Errorf("kubelet checkpoint: %w", err)
+	}
+
+	var result struct {
+		Items []string `json:"items"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil || len(result.Items) == 0 {
+		return "", fmt.Errorf("kubelet checkpoint: unexpected response %q", raw)
+	}
+	return result.Items[0], nil
+}
+
+// tarWorkspace execs into container on pod and streams back a gzipped tar of
+// /workspace, the fallback capture path for nodes without the checkpoint
+// feature gate enabled.
+func (s *snapshotStore) tarWorkspace(ctx context.Context, namespace, pod, container string) ([]byte, error) {
+	req := s.client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   []string{"tar", "czf", "-", "-C", "/workspace", "."},
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(s.restConfig, "POST", req.URL())
+	if err != nil {
+		return nil, fmt.Errorf("build tar exec: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := exec.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}); err != nil {
+		return nil, fmt.Errorf("tar /workspace: %w (stderr: %s)", err, stderr.String())
+	}
+	if stdout.Len() > maxTarSnapshotBytes {
+		return nil, fmt.Errorf("workspace tar is %d bytes, over the %d byte limit the ConfigMap-backed tar fallback supports", stdout.Len(), maxTarSnapshotBytes)
+	}
+	return stdout.Bytes(), nil
+}
+
+// restoreWorkspace execs into container on pod and extracts tarData into
+// /workspace, undoing tarWorkspace.
+func (s *snapshotStore) restoreWorkspace(ctx context.Context, namespace, pod, container string, tarData []byte) error {
+	req := s.client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   []string{"tar", "xzf", "-", "-C", "/workspace"},
+			Stdin:     true,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(s.restConfig, "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("build restore exec: %w", err)
+	}
+
+	var stderr bytes.Buffer
+	if err := exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  bytes.NewReader(tarData),
+		Stdout: io.Discard,
+		Stderr: &stderr,
+	}); err != nil {
+		return fmt.Errorf("restore /workspace: %w (stderr: %s)", err, stderr.String())
+	}
+	return nil
+}
+
+// snapshotFromConfigMap reconstructs the sandbox.Snapshot a ConfigMap
+// represents, the inverse of create's Data population.
+func snapshotFromConfigMap(cm corev1.ConfigMap) sandbox.Snapshot {
+	createdAt, _ := time.Parse(time.RFC3339, cm.Data["createdAt"])
+	return sandbox.Snapshot{
+		ID:        cm.Data["id"],
+		SandboxID: cm.Data["sandboxId"],
+		Name:      cm.Data["name"],
+		CreatedAt: createdAt,
+	}
+}
@@ -0,0 +1,123 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	bridgev1 "github.com/vercel/bridge/api/go/bridge/v1"
+	"github.com/vercel/bridge/pkg/k8s/kube"
+	"github.com/vercel/bridge/pkg/k8s/meta"
+	"github.com/vercel/bridge/pkg/sandbox"
+)
+
+// SnapshotBridge captures the named bridge's workspace state into a Snapshot.
+// It prefers a kubelet checkpoint (see snapshotStore.checkpointContainer) and
+// falls back to taring /workspace when the node doesn't support it.
+func (l *adminService) SnapshotBridge(ctx context.Context, req *bridgev1.SnapshotBridgeRequest) (*bridgev1.SnapshotBridgeResponse, error) {
+	if req.DeviceId == "" || req.Namespace == "" || req.Name == "" || req.SnapshotName == "" {
+		return nil, fmt.Errorf("device_id, namespace, name, and snapshot_name are required")
+	}
+
+	logger := l.logger.With("device_id", req.DeviceId, "namespace", req.Namespace, "bridge", req.Name, "snapshot", req.SnapshotName)
+
+	podName, err := kube.WaitForPod(ctx, l.client, req.Namespace, meta.DeploymentSelector(req.Name), 30*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed finding bridge pod: %w", err)
+	}
+
+	pod, err := l.client.CoreV1().Pods(req.Namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get pod %s/%s: %w", req.Namespace, podName, err)
+	}
+
+	snap := sandbox.Snapshot{
+		ID:        string(pod.UID) + "-" + req.SnapshotName,
+		SandboxID: req.Name,
+		Name:      req.SnapshotName,
+		CreatedAt: time.Now(),
+	}
+
+	method := snapshotMethodCheckpoint
+	binaryData := map[string][]byte{}
+	extra := map[string]string{}
+
+	checkpointPath, err := l.snapshots.checkpointContainer(ctx, req.Namespace, podName, proxyContainerName, pod.Spec.NodeName)
+	if err != nil {
+		logger.Warn("Checkpoint unavailable, falling back to tar of /workspace", "error", err)
+		method = snapshotMethodTar
+		tarData, tarErr := l.snapshots.tarWorkspace(ctx, req.Namespace, podName, proxyContainerName)
+		if tarErr != nil {
+			return nil, fmt.Errorf("snapshot failed: checkpoint unavailable (%v) and tar fallback also failed: %w", err, tarErr)
+		}
+		binaryData["workspace.tar.gz"] = tarData
+	} else {
+		extra["checkpointPath"] = checkpointPath
+		extra["nodeName"] = pod.Spec.NodeName
+	}
+
+	if err := l.snapshots.create(ctx, req.Namespace, req.DeviceId, snap, method, binaryData, extra); err != nil {
+		return nil, fmt.Errorf("record snapshot: %w", err)
+	}
+
+	return &bridgev1.SnapshotBridgeResponse{Snapshot: toProtoSnapshot(snap, method)}, nil
+}
+
+// RestoreBridge rehydrates snapshotName into an already-running bridge. Only
+// tar-fallback snapshots can be restored automatically; see
+// snapshotStore.restore for why checkpoint snapshots can't be yet.
+func (l *adminService) RestoreBridge(ctx context.Context, req *bridgev1.RestoreBridgeRequest) (*bridgev1.RestoreBridgeResponse, error) {
+	if req.DeviceId == "" || req.Namespace == "" || req.Name == "" || req.SnapshotName == "" {
+		return nil, fmt.Errorf("device_id, namespace, name, and snapshot_name are required")
+	}
+
+	podName, err := kube.WaitForPod(ctx, l.client, req.Namespace, meta.DeploymentSelector(req.Name), 30*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed finding bridge pod: %w", err)
+	}
+
+	if err := l.snapshots.restore(ctx, req.Namespace, req.Name, req.SnapshotName, podName); err != nil {
+		return nil, err
+	}
+
+	return &bridgev1.RestoreBridgeResponse{PodName: podName}, nil
+}
+
+// ListSnapshots returns a device's captured Snapshots, optionally narrowed to
+// one bridge. An empty namespace lists across all namespaces, matching
+// ListBridges' device-wide view.
+func (l *adminService) ListSnapshots(ctx context.Context, req *bridgev1.ListSnapshotsRequest) (*bridgev1.ListSnapshotsResponse, error) {
+	if req.DeviceId == "" {
+		return nil, fmt.Errorf("device_id is required")
+	}
+
+	cms, err := l.snapshots.list(ctx, req.Namespace, req.DeviceId, req.Name)
+	if err != nil {
+		return nil, fmt.Errorf("list snapshots: %w", err)
+	}
+
+	var snaps []*bridgev1.SnapshotInfo
+	for _, cm := range cms {
+		snaps = append(snaps, &bridgev1.SnapshotInfo{
+			Snapshot:  toProtoSnapshot(snapshotFromConfigMap(cm), cm.Data["method"]),
+			Namespace: cm.Namespace,
+		})
+	}
+
+	return &bridgev1.ListSnapshotsResponse{Snapshots: snaps}, nil
+}
+
+// toProtoSnapshot converts a sandbox.Snapshot plus its capture method to the
+// wire format, reusing pkg/sandbox's Snapshot shape since it already models
+// exactly the fields a point-in-time capture needs.
+func toProtoSnapshot(snap sandbox.Snapshot, method string) *bridgev1.Snapshot {
+	return &bridgev1.Snapshot{
+		Id:        snap.ID,
+		SandboxId: snap.SandboxID,
+		Name:      snap.Name,
+		CreatedAt: snap.CreatedAt.Format(time.RFC3339),
+		Method:    method,
+	}
+}
@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"context"
+	"io"
+	"time"
+
+	bridgev1 "github.com/vercel/bridge/api/go/bridge/v1"
+)
+
+// watchPollInterval is how often the local admin re-lists bridges while
+// serving WatchBridges.
+const watchPollInterval = 3 * time.Second
+
+// pollWatchStream implements BridgeWatchStream by re-running snapshot on a
+// fixed interval, pushing the result over a channel. It's the long-poll
+// fallback every local WatchBridges call uses, since the local admin has no
+// cheaper signal to push on; an in-cluster implementation could instead
+// notify on reconciler state changes.
+type pollWatchStream struct {
+	ch  chan *bridgev1.WatchBridgesResponse
+	err chan error
+}
+
+func newPollWatchStream(ctx context.Context, interval time.Duration, snapshot func() (*bridgev1.WatchBridgesResponse, error)) *pollWatchStream {
+	s := &pollWatchStream{
+		ch:  make(chan *bridgev1.WatchBridgesResponse),
+		err: make(chan error, 1),
+	}
+	go s.run(ctx, interval, snapshot)
+	return s
+}
+
+func (s *pollWatchStream) run(ctx context.Context, interval time.Duration, snapshot func() (*bridgev1.WatchBridgesResponse, error)) {
+	push := func() bool {
+		resp, err := snapshot()
+		if err != nil {
+			s.err <- err
+			return false
+		}
+		select {
+		case s.ch <- resp:
+			return true
+		case <-ctx.Done():
+			s.err <- ctx.Err()
+			return false
+		}
+	}
+	if !push() {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.err <- ctx.Err()
+			return
+		case <-ticker.C:
+			if !push() {
+				return
+			}
+		}
+	}
+}
+
+// Recv returns the next snapshot, blocking until one is pushed or ctx ends.
+func (s *pollWatchStream) Recv() (*bridgev1.WatchBridgesResponse, error) {
+	select {
+	case resp, ok := <-s.ch:
+		if !ok {
+			return nil, io.EOF
+		}
+		return resp, nil
+	case err := <-s.err:
+		return nil, err
+	}
+}
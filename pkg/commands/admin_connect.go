@@ -6,20 +6,67 @@ import (
 	"io"
 	"time"
 
+	"github.com/urfave/cli/v3"
+
 	bridgev1 "github.com/vercel/bridge/api/go/bridge/v1"
 	"github.com/vercel/bridge/pkg/admin"
+	adminclient "github.com/vercel/bridge/pkg/admin/client"
 	"github.com/vercel/bridge/pkg/interact"
 )
 
+// credentialFlags are the client-auth flags shared by every command that
+// calls connectAdmin against a remote administrator protected by
+// pkg/admin/middleware.
+func credentialFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "client-cert",
+			Usage:   "Client certificate presented for mTLS to the administrator",
+			Sources: cli.EnvVars("BRIDGE_CLIENT_CERT"),
+		},
+		&cli.StringFlag{
+			Name:    "client-key",
+			Usage:   "Client key presented for mTLS to the administrator",
+			Sources: cli.EnvVars("BRIDGE_CLIENT_KEY"),
+		},
+		&cli.StringFlag{
+			Name:    "ca-cert",
+			Usage:   "CA bundle used to verify the administrator's server certificate",
+			Sources: cli.EnvVars("BRIDGE_CA_CERT"),
+		},
+		&cli.StringFlag{
+			Name:    "admin-token",
+			Usage:   "Bearer token presented to the administrator as an OIDC credential",
+			Sources: cli.EnvVars("BRIDGE_ADMIN_TOKEN"),
+		},
+	}
+}
+
+// credentialOptionsFromFlags builds the admin.ClientOptions matching the
+// flags credentialFlags defines.
+func credentialOptionsFromFlags(c *cli.Command) ([]admin.ClientOption, error) {
+	cfg := adminclient.Config{
+		ClientCert: c.String("client-cert"),
+		ClientKey:  c.String("client-key"),
+		CACert:     c.String("ca-cert"),
+		Token:      c.String("admin-token"),
+	}
+	return cfg.Options()
+}
+
 // connectAdmin establishes a connection to the bridge administrator. It tries
 // the remote administrator first; if unavailable, falls back to a local admin
 // backed by the user's kubeconfig. The returned bool is true when the local
-// fallback was used. The caller must defer adm.Close().
-func connectAdmin(ctx context.Context, adminAddr string) (admin.Service, bool, error) {
+// fallback was used. The caller must defer adm.Close(). opts is typically
+// built from pkg/admin/client.Config.Options, to present a client
+// certificate or bearer token to an administrator protected by
+// pkg/admin/middleware; the local fallback ignores it, since it talks to the
+// Kubernetes API directly rather than the administrator's gRPC server.
+func connectAdmin(ctx context.Context, adminAddr string, opts ...admin.ClientOption) (admin.Service, bool, error) {
 	sp := interact.NewSpinner("Connecting to bridge administrator...")
 	go sp.Start(ctx)
 
-	remote, dialErr := admin.NewClient(adminAddr)
+	remote, dialErr := admin.NewClient(adminAddr, opts...)
 	if dialErr == nil {
 		// Probe the remote with a lightweight RPC to confirm it's reachable.
 		probeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
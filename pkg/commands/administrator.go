@@ -2,26 +2,39 @@ package commands
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net"
-	"os/signal"
-	"syscall"
+	"net/http"
+	"os"
+	"time"
 
 	"github.com/urfave/cli/v3"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/status"
 
 	bridgev1 "github.com/vercel/bridge/api/go/bridge/v1"
 	"github.com/vercel/bridge/pkg/admin"
+	"github.com/vercel/bridge/pkg/admin/middleware"
 	"github.com/vercel/bridge/pkg/k8s/kube"
 	"github.com/vercel/bridge/pkg/k8s/resources"
 
 	"k8s.io/client-go/kubernetes"
 )
 
+// Default per-principal rate limit applied by the administrator's
+// interceptor chain, regardless of which auth method resolved the caller.
+const (
+	defaultRateLimitPerSecond = 5
+	defaultRateLimitBurst     = 20
+)
+
 // Administrator returns the CLI command for the bridge administrator server.
 func Administrator() *cli.Command {
 	return &cli.Command{
@@ -63,6 +76,63 @@ func Administrator() *cli.Command {
 				Usage:   "Override the default kubeconfig namespace (out-of-cluster only)",
 				Sources: cli.EnvVars("KUBE_NAMESPACE"),
 			},
+			&cli.StringFlag{
+				Name:    "tls-cert",
+				Usage:   "Server certificate for the gRPC listener; enables TLS (add --client-ca for mTLS client authentication)",
+				Sources: cli.EnvVars("ADMINISTRATOR_TLS_CERT"),
+			},
+			&cli.StringFlag{
+				Name:    "tls-key",
+				Usage:   "Server key for the gRPC listener",
+				Sources: cli.EnvVars("ADMINISTRATOR_TLS_KEY"),
+			},
+			&cli.StringFlag{
+				Name:    "client-ca",
+				Usage:   "CA bundle used to verify client certificates; requires --tls-cert and enables mTLS authentication",
+				Sources: cli.EnvVars("ADMINISTRATOR_CLIENT_CA"),
+			},
+			&cli.StringFlag{
+				Name:    "auth-oidc-issuer",
+				Usage:   "OIDC issuer URL used to verify bearer tokens presented by clients",
+				Sources: cli.EnvVars("ADMINISTRATOR_OIDC_ISSUER"),
+			},
+			&cli.StringFlag{
+				Name:    "auth-oidc-audience",
+				Usage:   "Expected aud claim on OIDC bearer tokens; if unset, the audience is not checked",
+				Sources: cli.EnvVars("ADMINISTRATOR_OIDC_AUDIENCE"),
+			},
+			&cli.StringFlag{
+				Name:    "policy-file",
+				Usage:   "Path to a JSON RBAC policy file; if unset, all authenticated callers are allowed",
+				Sources: cli.EnvVars("ADMINISTRATOR_POLICY_FILE"),
+			},
+			&cli.StringFlag{
+				Name:    "audit-log",
+				Usage:   "Path to append structured JSON audit records to; if unset, audit logging is disabled",
+				Sources: cli.EnvVars("ADMINISTRATOR_AUDIT_LOG"),
+			},
+			&cli.IntFlag{
+				Name:    "max-snapshots-per-device",
+				Usage:   "Maximum bridge snapshots a device may keep per bridge before the oldest is evicted",
+				Value:   5,
+				Sources: cli.EnvVars("ADMINISTRATOR_MAX_SNAPSHOTS_PER_DEVICE"),
+			},
+			&cli.DurationFlag{
+				Name:    "snapshot-ttl",
+				Usage:   "Maximum age of a bridge snapshot before it's evicted",
+				Value:   14 * 24 * time.Hour,
+				Sources: cli.EnvVars("ADMINISTRATOR_SNAPSHOT_TTL"),
+			},
+			&cli.BoolFlag{
+				Name:    "use-apiserver-dialer",
+				Usage:   "Reach bridge proxy pods through the kube-apiserver's portforward subresource instead of dialing pod IPs directly",
+				Sources: cli.EnvVars("ADMINISTRATOR_USE_APISERVER_DIALER"),
+			},
+			&cli.StringFlag{
+				Name:    "metrics-addr",
+				Usage:   "Address to serve Prometheus-style bridge health metrics on at /metrics; disabled if unset",
+				Sources: cli.EnvVars("ADMINISTRATOR_METRICS_ADDR"),
+			},
 		},
 		Action: runAdministrator,
 	}
@@ -88,9 +158,31 @@ func runAdministrator(ctx context.Context, c *cli.Command) error {
 		ProxyImage:              c.String("proxy-image"),
 		ServiceAccountName:      c.String("service-account"),
 		ServiceAccountNamespace: c.String("namespace"),
+		MaxSnapshotsPerDevice:   c.Int("max-snapshots-per-device"),
+		SnapshotTTL:             c.Duration("snapshot-ttl"),
+		UseAPIServerDialer:      c.Bool("use-apiserver-dialer"),
 	})
 
-	srv := grpc.NewServer()
+	var serverOpts []grpc.ServerOption
+
+	tlsOpt, err := serverTransportOption(c)
+	if err != nil {
+		return err
+	}
+	if tlsOpt != nil {
+		serverOpts = append(serverOpts, tlsOpt)
+	}
+
+	mwCfg, auditCloser, err := buildMiddlewareConfig(c)
+	if err != nil {
+		return err
+	}
+	if auditCloser != nil {
+		defer auditCloser.Close()
+	}
+	serverOpts = append(serverOpts, mwCfg.ServerOptions()...)
+
+	srv := grpc.NewServer(serverOpts...)
 	bridgev1.RegisterAdministratorServiceServer(srv, &administratorServer{admin: localAdm})
 
 	lis, err := net.Listen("tcp", addr)
@@ -98,9 +190,25 @@ func runAdministrator(ctx context.Context, c *cli.Command) error {
 		return fmt.Errorf("failed to listen on %s: %w", addr, err)
 	}
 
-	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	ctx, stop := trapSignals(ctx)
 	defer stop()
 
+	if hr, ok := localAdm.(admin.HealthRunner); ok {
+		go hr.RunHealthReconciler(ctx)
+	}
+
+	if metricsAddr := c.String("metrics-addr"); metricsAddr != "" {
+		if mw, ok := localAdm.(admin.MetricsWriter); ok {
+			metricsSrv := newMetricsServer(metricsAddr, mw)
+			go func() {
+				if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					slog.Error("metrics server stopped", "error", err)
+				}
+			}()
+			defer metricsSrv.Close()
+		}
+	}
+
 	slog.Info("Administrator server starting", "addr", addr)
 
 	errCh := make(chan error, 1)
@@ -118,6 +226,115 @@ func runAdministrator(ctx context.Context, c *cli.Command) error {
 	}
 }
 
+// newMetricsServer builds the HTTP server backing --metrics-addr: a single
+// /metrics endpoint rendering mw's health state as Prometheus text exposition
+// format, scraped the same way the rest of a cluster's workloads would be.
+func newMetricsServer(addr string, mw admin.MetricsWriter) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := mw.WriteHealthMetrics(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	return &http.Server{Addr: addr, Handler: mux}
+}
+
+// serverTransportOption builds the grpc.ServerOption enabling TLS on the
+// gRPC listener from --tls-cert/--tls-key, and mTLS client-certificate
+// verification if --client-ca is also set. Returns a nil option with no
+// error if neither flag is set, leaving the listener plaintext.
+func serverTransportOption(c *cli.Command) (grpc.ServerOption, error) {
+	certFile := c.String("tls-cert")
+	keyFile := c.String("tls-key")
+	if certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("--tls-cert and --tls-key must both be set")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+	tlsCfg := &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	if caFile := c.String("client-ca"); caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --client-ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", caFile)
+		}
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return grpc.Creds(credentials.NewTLS(tlsCfg)), nil
+}
+
+// buildMiddlewareConfig assembles the interceptor chain configuration from
+// the administrator's auth/policy/audit flags. If neither mTLS nor OIDC is
+// configured, requests authenticate as an anonymous principal, preserving
+// today's open-by-default behavior for local/dev deployments while still
+// routing every call through policy, rate limiting and audit logging. The
+// returned io.Closer, if non-nil, must be closed on shutdown to flush the
+// audit log.
+func buildMiddlewareConfig(c *cli.Command) (middleware.Config, io.Closer, error) {
+	var authenticators []middleware.Authenticator
+	if c.String("client-ca") != "" {
+		authenticators = append(authenticators, middleware.MTLSAuthenticator{})
+	}
+	if issuer := c.String("auth-oidc-issuer"); issuer != "" {
+		authenticators = append(authenticators, middleware.NewOIDCAuthenticator(middleware.OIDCConfig{
+			Issuer:   issuer,
+			Audience: c.String("auth-oidc-audience"),
+		}))
+	}
+	if len(authenticators) == 0 {
+		authenticators = append(authenticators, anonymousAuthenticator{})
+	}
+
+	var policy middleware.Policy
+	if policyFile := c.String("policy-file"); policyFile != "" {
+		p, err := middleware.LoadStaticPolicy(policyFile)
+		if err != nil {
+			return middleware.Config{}, nil, fmt.Errorf("failed to load --policy-file: %w", err)
+		}
+		policy = p
+	}
+
+	var audit middleware.AuditSink
+	var closer io.Closer
+	if auditLog := c.String("audit-log"); auditLog != "" {
+		sink, f, err := middleware.OpenFileAuditSink(auditLog)
+		if err != nil {
+			return middleware.Config{}, nil, fmt.Errorf("failed to open --audit-log: %w", err)
+		}
+		audit, closer = sink, f
+	}
+
+	cfg := middleware.Config{
+		Authenticator: middleware.ChainAuthenticators(authenticators...),
+		Policy:        policy,
+		RateLimiter:   middleware.NewRateLimiter(defaultRateLimitPerSecond, defaultRateLimitBurst),
+		Audit:         audit,
+	}
+	return cfg, closer, nil
+}
+
+// anonymousAuthenticator authenticates every call as principal "anonymous".
+// It's the fallback used when the administrator is started without
+// --client-ca or --auth-oidc-issuer.
+type anonymousAuthenticator struct{}
+
+func (anonymousAuthenticator) Authenticate(context.Context) (middleware.Principal, error) {
+	return middleware.Principal{Name: "anonymous", Method: "none"}, nil
+}
+
 // administratorServer is a thin gRPC wrapper that delegates to an Service implementation.
 type administratorServer struct {
 	bridgev1.UnimplementedAdministratorServiceServer
@@ -148,6 +365,80 @@ func (s *administratorServer) DeleteBridge(ctx context.Context, req *bridgev1.De
 	return resp, nil
 }
 
+func (s *administratorServer) PortForward(stream bridgev1.AdministratorService_PortForwardServer) error {
+	if err := s.admin.PortForward(stream.Context(), stream); err != nil {
+		return grpcError(err)
+	}
+	return nil
+}
+
+func (s *administratorServer) SnapshotBridge(ctx context.Context, req *bridgev1.SnapshotBridgeRequest) (*bridgev1.SnapshotBridgeResponse, error) {
+	resp, err := s.admin.SnapshotBridge(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp, nil
+}
+
+func (s *administratorServer) RestoreBridge(ctx context.Context, req *bridgev1.RestoreBridgeRequest) (*bridgev1.RestoreBridgeResponse, error) {
+	resp, err := s.admin.RestoreBridge(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp, nil
+}
+
+func (s *administratorServer) ListSnapshots(ctx context.Context, req *bridgev1.ListSnapshotsRequest) (*bridgev1.ListSnapshotsResponse, error) {
+	resp, err := s.admin.ListSnapshots(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp, nil
+}
+
+func (s *administratorServer) HealthCheck(ctx context.Context, req *bridgev1.HealthCheckRequest) (*bridgev1.HealthCheckResponse, error) {
+	resp, err := s.admin.HealthCheck(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp, nil
+}
+
+func (s *administratorServer) ConfigureHealth(ctx context.Context, req *bridgev1.ConfigureHealthRequest) (*bridgev1.ConfigureHealthResponse, error) {
+	resp, err := s.admin.ConfigureHealth(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp, nil
+}
+
+func (s *administratorServer) HealthHistory(ctx context.Context, req *bridgev1.HealthHistoryRequest) (*bridgev1.HealthHistoryResponse, error) {
+	resp, err := s.admin.HealthHistory(ctx, req)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return resp, nil
+}
+
+func (s *administratorServer) WatchBridges(req *bridgev1.WatchBridgesRequest, stream bridgev1.AdministratorService_WatchBridgesServer) error {
+	watch, err := s.admin.WatchBridges(stream.Context(), req)
+	if err != nil {
+		return grpcError(err)
+	}
+	for {
+		resp, err := watch.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return grpcError(err)
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
 // grpcError converts an error from the Service implementation to an appropriate
 // gRPC status error.
 func grpcError(err error) error {
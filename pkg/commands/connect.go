@@ -4,29 +4,45 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/url"
 	"os"
-	"os/signal"
 	"path/filepath"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/reach/pkg/proxy"
 	"github.com/reach/pkg/sessions"
 	"github.com/urfave/cli/v3"
+
+	bridgev1 "github.com/vercel/bridge/api/go/bridge/v1"
+	"github.com/vercel/bridge/pkg/admin"
+	"github.com/vercel/bridge/pkg/identity"
 )
 
 func Connect() *cli.Command {
 	return &cli.Command{
 		Name:  "connect",
 		Usage: "Connect to a sandbox",
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.IntFlag{
 				Name:  "local-port",
 				Usage: "Local port for SSH proxy (random if not specified)",
 			},
-		},
+			&cli.StringFlag{
+				Name:  "admin-addr",
+				Usage: "Address of the bridge administrator; when set, SSH is forwarded through it instead of a separately-deployed proxy pod",
+			},
+			&cli.IntFlag{
+				Name:  "remote-port",
+				Usage: "Remote port to forward when using --admin-addr",
+				Value: 22,
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Connect even if the sandbox's last recorded health is unhealthy",
+			},
+		}, credentialFlags()...),
 		Arguments: []cli.Argument{
 			&cli.StringArg{
 				Name:      "target",
@@ -43,8 +59,18 @@ func Connect() *cli.Command {
 func runConnect(ctx context.Context, c *cli.Command) error {
 	arg := c.StringArg("target")
 	localPort := c.Int("local-port")
+	adminAddr := c.String("admin-addr")
+	remotePort := c.Int("remote-port")
 
-	store, err := sessions.NewStore()
+	if adminAddr != "" {
+		credOpts, err := credentialOptionsFromFlags(c)
+		if err != nil {
+			return err
+		}
+		return runConnectViaAdmin(ctx, adminAddr, arg, localPort, int32(remotePort), c.Bool("force"), credOpts...)
+	}
+
+	store, err := sessions.NewFileStore()
 	if err != nil {
 		return fmt.Errorf("failed to initialize session store: %w", err)
 	}
@@ -104,7 +130,7 @@ func runConnect(ctx context.Context, c *cli.Command) error {
 		}
 	}()
 
-	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	ctx, stop := trapSignals(ctx)
 	defer stop()
 
 	fmt.Printf("Sandbox connected: %s\n", name)
@@ -115,6 +141,94 @@ func runConnect(ctx context.Context, c *cli.Command) error {
 	return serveWithReconnect(ctx, tcpProxy)
 }
 
+// runConnectViaAdmin forwards reach.<name> SSH sessions straight through the
+// bridge administrator's PortForward RPC, so the administrator is the sole
+// ingress and no separately-deployed proxy pod is required.
+func runConnectViaAdmin(ctx context.Context, adminAddr, name string, localPort int, remotePort int32, force bool, credOpts ...admin.ClientOption) error {
+	adm, _, err := connectAdmin(ctx, adminAddr, credOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to bridge administrator: %w", err)
+	}
+	defer adm.Close()
+
+	namespace, err := resolveBridgeNamespace(ctx, adm, name, force)
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", localPort))
+	if err != nil {
+		return fmt.Errorf("failed to start local listener: %w", err)
+	}
+	defer ln.Close()
+
+	actualPort := ln.Addr().(*net.TCPAddr).Port
+	if err := updateSSHConfig(name, actualPort); err != nil {
+		return fmt.Errorf("failed to update SSH config: %w", err)
+	}
+	defer func() {
+		if err := removeSSHConfig(name); err != nil {
+			slog.Error("failed to remove SSH config", "error", err)
+		}
+	}()
+
+	ctx, stop := trapSignals(ctx)
+	defer stop()
+
+	fmt.Printf("Sandbox connected: %s\n", name)
+	fmt.Printf("SSH: ssh reach.%s\n", name)
+	fmt.Printf("Local proxy listening on 127.0.0.1:%d (via administrator)\n", actualPort)
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("accept error: %w", err)
+		}
+
+		go func() {
+			defer conn.Close()
+			if err := admin.Pipe(ctx, adm, namespace, name, remotePort, conn); err != nil {
+				slog.Warn("port-forward session ended", "error", err)
+			}
+		}()
+	}
+}
+
+// resolveBridgeNamespace looks up the namespace of the named bridge via the
+// administrator's bridge listing. Unless force is set, it refuses to return
+// a handle to a bridge whose last recorded health is unhealthy, since
+// connecting to (or forwarding traffic into) a sandbox already known to be
+// failing its health checks is rarely what the caller wants.
+func resolveBridgeNamespace(ctx context.Context, adm admin.Service, name string, force bool) (string, error) {
+	deviceID, err := identity.GetDeviceID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get device identity: %w", err)
+	}
+
+	resp, err := adm.ListBridges(ctx, &bridgev1.ListBridgesRequest{DeviceId: deviceID})
+	if err != nil {
+		return "", fmt.Errorf("failed to list bridges: %w", err)
+	}
+	for _, bridge := range resp.Bridges {
+		if bridge.DeploymentName != name {
+			continue
+		}
+		if !force && bridge.Health != nil && bridge.Health.Status == "unhealthy" {
+			return "", fmt.Errorf("bridge %q is unhealthy (%d consecutive failures); pass --force to connect anyway", name, bridge.Health.ConsecutiveFailures)
+		}
+		return bridge.Namespace, nil
+	}
+	return "", fmt.Errorf("bridge %q not found", name)
+}
+
 func serveWithReconnect(ctx context.Context, tcpProxy proxy.TCPProxy) error {
 	backoff := 1 * time.Second
 	maxBackoff := 10 * time.Second
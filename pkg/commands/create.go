@@ -3,6 +3,7 @@ package commands
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
@@ -18,6 +19,9 @@ import (
 	"github.com/vercel-eddie/bridge/pkg/devcontainer"
 	"github.com/vercel-eddie/bridge/pkg/identity"
 	"github.com/vercel-eddie/bridge/pkg/k8s/k8spf"
+
+	"github.com/vercel/bridge/pkg/admin/manifest"
+	"github.com/vercel/bridge/pkg/k8s/kube"
 )
 
 const defaultFeatureRef = "ghcr.io/vercel-eddie/bridge/features/bridge:edge"
@@ -29,7 +33,7 @@ func Create() *cli.Command {
 	return &cli.Command{
 		Name:  "create",
 		Usage: "Create a bridge to a Kubernetes deployment",
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.BoolFlag{
 				Name:  "connect",
 				Usage: "Start a Devcontainer and connect to the bridge after creation",
@@ -68,7 +72,19 @@ func Create() *cli.Command {
 				Hidden:  true,
 				Sources: cli.EnvVars("BRIDGE_FEATURE_REF"),
 			},
-		},
+			&cli.StringFlag{
+				Name:  "from-snapshot",
+				Usage: "Restore the bridge's /workspace from a previously captured snapshot name",
+			},
+			&cli.StringFlag{
+				Name:  "manifest",
+				Usage: "Path to a bridge.yaml manifest declaring the Deployment (and Service/ConfigMap/PersistentVolumeClaim) to stand up and bridge, instead of bridging an existing deployment",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "With --manifest, print a diff of what would change instead of creating anything",
+			},
+		}, credentialFlags()...),
 		Arguments: []cli.Argument{
 			&cli.StringArg{
 				Name:      "deployment",
@@ -83,6 +99,10 @@ func Create() *cli.Command {
 }
 
 func runCreate(ctx context.Context, c *cli.Command) error {
+	if manifestPath := c.String("manifest"); manifestPath != "" {
+		return runCreateFromManifest(ctx, c, manifestPath)
+	}
+
 	deploymentName := c.StringArg("deployment")
 	sourceNamespace := c.String("namespace")
 	adminAddr := c.String("admin-addr")
@@ -153,6 +173,7 @@ func runCreate(ctx context.Context, c *cli.Command) error {
 		SourceDeployment: deploymentName,
 		SourceNamespace:  sourceNamespace,
 		Force:            force,
+		FromSnapshot:     c.String("from-snapshot"),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create bridge: %w", err)
@@ -182,6 +203,63 @@ func runCreate(ctx context.Context, c *cli.Command) error {
 	return nil
 }
 
+// runCreateFromManifest handles `bridge create --manifest bridge.yaml`: it
+// applies the manifest's Deployment (and any Service/ConfigMap/
+// PersistentVolumeClaim) to the cluster and creates a bridge mirroring it,
+// going through connectAdmin so the same manifest works whether or not a
+// remote administrator is reachable. --connect and --devcontainer-config
+// aren't supported yet on this path; run `bridge connect` separately.
+func runCreateFromManifest(ctx context.Context, c *cli.Command, manifestPath string) error {
+	adminAddr := c.String("admin-addr")
+	w := c.Root().Writer
+
+	deviceID, err := identity.GetDeviceID()
+	if err != nil {
+		return fmt.Errorf("failed to get device identity: %w", err)
+	}
+
+	credOpts, err := credentialOptionsFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	adm, _, err := connectAdmin(ctx, adminAddr, credOpts...)
+	if err != nil {
+		return err
+	}
+	defer adm.Close()
+
+	restCfg, err := kube.RestConfig(kube.Config{})
+	if err != nil {
+		return fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+
+	if c.Bool("dry-run") {
+		diff, err := manifest.Diff(ctx, restCfg, manifestPath, nil)
+		if err != nil {
+			return fmt.Errorf("failed to diff manifest %s: %w", manifestPath, err)
+		}
+		fmt.Fprint(w, diff)
+		return nil
+	}
+
+	slog.Info("Creating bridge from manifest...", "manifest", manifestPath)
+
+	resp, err := manifest.CreateFromManifest(ctx, restCfg, adm, deviceID, manifestPath, nil, manifest.Options{
+		Force:        c.Bool("force"),
+		FromSnapshot: c.String("from-snapshot"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create bridge from manifest %s: %w", manifestPath, err)
+	}
+
+	fmt.Fprintf(w, "\nBridge created successfully!\n")
+	fmt.Fprintf(w, "  Namespace: %s\n", resp.Namespace)
+	fmt.Fprintf(w, "  Pod:       %s\n", resp.PodName)
+	fmt.Fprintf(w, "  Port:      %d\n", resp.Port)
+	return nil
+}
+
 // generateDevcontainerConfig creates a bridge devcontainer.json from a base config.
 // It respects the KUBECONFIG env var by bind-mounting it into the container,
 // unless the base config already sets containerEnv.KUBECONFIG.
@@ -214,13 +292,25 @@ func generateDevcontainerConfig(w io.Writer, deploymentName, baseConfigPath, fea
 
 	cfg.Name = "bridge-" + dcName
 	bridgeServerAddr := fmt.Sprintf("k8spf:///%s.%s:%d", resp.PodName, resp.Namespace, resp.Port)
-	cfg.SetFeature(featureRef, map[string]any{
+	featureOpts := map[string]any{
 		"bridgeVersion":    Version,
 		"bridgeServerAddr": bridgeServerAddr,
 		"forwardDomains":   "*",
 		"appPort":          fmt.Sprintf("%d", appPort),
 		"workspacePath":    "${containerWorkspaceFolder}",
-	})
+	}
+	// Forward the source deployment's normalized probe/preStop handlers so
+	// the in-container bridge feature can run a local responder on
+	// --listen that honors the same scheme/host/port semantics kubelet
+	// would use in-cluster (see pkg/probe).
+	if len(resp.Probes) > 0 {
+		probesJSON, err := json.Marshal(resp.Probes)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal probe config: %w", err)
+		}
+		featureOpts["probes"] = string(probesJSON)
+	}
+	cfg.SetFeature(featureRef, featureOpts)
 	cfg.EnsureCapAdd("NET_ADMIN")
 
 	// Mount KUBECONFIG if set, unless the base config already configured it.
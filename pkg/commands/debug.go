@@ -4,18 +4,22 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"runtime"
+	goruntime "runtime"
 	"strings"
 	"time"
 
 	"github.com/urfave/cli/v3"
 	"github.com/vercel/bridge/pkg/identity"
 	"github.com/vercel/bridge/pkg/interact"
+	containerruntime "github.com/vercel/bridge/pkg/runtime"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+// containerLabelKeyBridgeDeployment marks containers started for a bridge
+// intercept session, regardless of which engine is running them.
+const containerLabelKeyBridgeDeployment = "bridge.deployment"
+
 // Debug returns the CLI command for collecting diagnostic information.
 func Debug() *cli.Command {
 	return &cli.Command{
@@ -70,8 +74,8 @@ func collectHeader(b *strings.Builder) {
 	content := fmt.Sprintf("Timestamp: %s\nVersion:   %s\nOS/Arch:   %s/%s\n",
 		time.Now().Format(time.RFC3339),
 		Version,
-		runtime.GOOS,
-		runtime.GOARCH,
+		goruntime.GOOS,
+		goruntime.GOARCH,
 	)
 	writeSection(b, "HEADER", content)
 }
@@ -131,65 +135,72 @@ func collectHostLogs(b *strings.Builder) {
 	writeSection(b, "HOST LOGS", fmt.Sprintf("(last %d lines of %s)\n%s", min(len(lines), tailLines), logPath, strings.Join(lines, "\n")))
 }
 
-// collectRunningContainers lists bridge containers and returns their IDs.
-func collectRunningContainers(ctx context.Context, b *strings.Builder) []string {
-	cmd := exec.CommandContext(ctx, "docker", "ps",
-		"--filter", "label="+containerLabelKeyBridgeDeployment,
-		"--format", "{{.ID}}\t{{.Names}}\t{{.Status}}\t{{.Label \"bridge.deployment\"}}",
-	)
-	out, err := cmd.Output()
-	if err != nil {
-		writeSection(b, "RUNNING BRIDGE CONTAINERS", fmt.Sprintf("error: %v", err))
-		return nil
-	}
+// runningContainer pairs a container with the engine that's running it, so
+// diagnostics can be collected through the same Runtime that listed it.
+type runningContainer struct {
+	engine    containerruntime.Runtime
+	container containerruntime.Container
+}
 
-	output := strings.TrimSpace(string(out))
-	if output == "" {
-		writeSection(b, "RUNNING BRIDGE CONTAINERS", "(none)")
+// collectRunningContainers lists bridge containers across every detected
+// container engine (Docker, Podman, containerd) and returns them so a
+// machine with mixed engines still produces one report.
+func collectRunningContainers(ctx context.Context, b *strings.Builder) []runningContainer {
+	engines := containerruntime.Detect(ctx)
+	if len(engines) == 0 {
+		writeSection(b, "RUNNING BRIDGE CONTAINERS", "error: no container engine socket found")
 		return nil
 	}
 
-	writeSection(b, "RUNNING BRIDGE CONTAINERS", output)
-
-	// Extract container IDs (first column).
-	var ids []string
-	for _, line := range strings.Split(output, "\n") {
-		if id, _, ok := strings.Cut(line, "\t"); ok && id != "" {
-			ids = append(ids, id)
+	var lines []string
+	var containers []runningContainer
+	for _, engine := range engines {
+		found, err := engine.List(ctx, map[string]string{containerLabelKeyBridgeDeployment: ""})
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("%s: error: %v", engine.Name(), err))
+			continue
+		}
+		for _, c := range found {
+			lines = append(lines, fmt.Sprintf("%s\t%s\t%s\t%s", engine.Name(), c.ID, c.Name, c.Status))
+			containers = append(containers, runningContainer{engine: engine, container: c})
 		}
 	}
-	return ids
+
+	if len(lines) == 0 {
+		writeSection(b, "RUNNING BRIDGE CONTAINERS", "(none)")
+		return nil
+	}
+	writeSection(b, "RUNNING BRIDGE CONTAINERS", strings.Join(lines, "\n"))
+	return containers
 }
 
-func collectContainerDiagnostics(ctx context.Context, b *strings.Builder, containerIDs []string) {
-	for _, id := range containerIDs {
-		short := id
+func collectContainerDiagnostics(ctx context.Context, b *strings.Builder, containers []runningContainer) {
+	for _, rc := range containers {
+		short := rc.container.ID
 		if len(short) > 12 {
 			short = short[:12]
 		}
 
-		sectionPrefix := fmt.Sprintf("CONTAINER %s", short)
+		sectionPrefix := fmt.Sprintf("CONTAINER %s (%s)", short, rc.engine.Name())
 
 		// Intercept log
-		writeSection(b, sectionPrefix+" INTERCEPT LOG", dockerExec(ctx, id, "cat", "/tmp/bridge-intercept.log"))
+		writeSection(b, sectionPrefix+" INTERCEPT LOG", runtimeExec(ctx, rc.engine, rc.container.ID, "cat", "/tmp/bridge-intercept.log"))
 
 		// iptables rules
-		writeSection(b, sectionPrefix+" IPTABLES", dockerExec(ctx, id, "iptables", "-t", "nat", "-L", "BRIDGE_INTERCEPT", "-n", "-v"))
+		writeSection(b, sectionPrefix+" IPTABLES", runtimeExec(ctx, rc.engine, rc.container.ID, "iptables", "-t", "nat", "-L", "BRIDGE_INTERCEPT", "-n", "-v"))
 
 		// Bridge env
-		writeSection(b, sectionPrefix+" BRIDGE ENV", dockerExec(ctx, id, "cat", "/etc/profile.d/bridge.sh"))
+		writeSection(b, sectionPrefix+" BRIDGE ENV", runtimeExec(ctx, rc.engine, rc.container.ID, "cat", "/etc/profile.d/bridge.sh"))
 
 		// Port listeners
-		writeSection(b, sectionPrefix+" PORT LISTENERS", dockerExec(ctx, id, "sh", "-c", "ss -tlnp 2>/dev/null || netstat -tlnp 2>/dev/null"))
+		writeSection(b, sectionPrefix+" PORT LISTENERS", runtimeExec(ctx, rc.engine, rc.container.ID, "sh", "-c", "ss -tlnp 2>/dev/null || netstat -tlnp 2>/dev/null"))
 	}
 }
 
-func dockerExec(ctx context.Context, containerID string, args ...string) string {
-	cmdArgs := append([]string{"exec", containerID}, args...)
-	cmd := exec.CommandContext(ctx, "docker", cmdArgs...)
-	out, err := cmd.CombinedOutput()
+func runtimeExec(ctx context.Context, engine containerruntime.Runtime, containerID string, args ...string) string {
+	out, err := engine.Exec(ctx, containerID, args...)
 	if err != nil {
-		return fmt.Sprintf("error: %v\n%s", err, string(out))
+		return fmt.Sprintf("error: %v\n%s", err, out)
 	}
 	result := strings.TrimSpace(string(out))
 	if result == "" {
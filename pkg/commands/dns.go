@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"sync"
+)
+
+// dnsAllocator hands out a deterministic IP from a CIDR block per hostname,
+// so a TCP flow to one of those synthetic IPs can later be reverse-resolved
+// back to the hostname it was allocated for (see proxyCIDR's doc comment).
+// The mapping only lives in memory: a restarted intercept session
+// re-allocates IPs for whatever hostnames it sees again, in the same
+// deterministic way, so nothing needs to persist across runs.
+type dnsAllocator struct {
+	network *net.IPNet
+
+	mu     sync.Mutex
+	byHost map[string]net.IP
+	byIP   map[string]string
+}
+
+// newDNSAllocator builds a dnsAllocator that hands out addresses from cidr.
+func newDNSAllocator(cidr string) (*dnsAllocator, error) {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("parse proxy cidr %q: %w", cidr, err)
+	}
+	return &dnsAllocator{
+		network: network,
+		byHost:  make(map[string]net.IP),
+		byIP:    make(map[string]string),
+	}, nil
+}
+
+// allocate returns the IP assigned to hostname, allocating one
+// deterministically from a's network the first time hostname is seen.
+func (a *dnsAllocator) allocate(hostname string) net.IP {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if ip, ok := a.byHost[hostname]; ok {
+		return ip
+	}
+
+	ip := a.deterministicIP(hostname)
+	a.byHost[hostname] = ip
+	a.byIP[ip.String()] = hostname
+	return ip
+}
+
+// deterministicIP hashes hostname into an offset within a.network so the
+// same hostname always maps to the same address across intercept restarts,
+// which keeps logs and packet captures comparable between runs. Offset 0
+// (the network address) is never handed out.
+func (a *dnsAllocator) deterministicIP(hostname string) net.IP {
+	ones, bits := a.network.Mask.Size()
+	hostBits := uint(bits - ones)
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(hostname))
+	offset := h.Sum32()
+	if hostBits < 32 {
+		offset &= (1 << hostBits) - 1
+	}
+	if offset == 0 {
+		offset = 1
+	}
+
+	base := binary.BigEndian.Uint32(a.network.IP.To4())
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, base+offset)
+	return ip
+}
+
+// hostnameFor returns the hostname previously allocated to ip, if any.
+func (a *dnsAllocator) hostnameFor(ip string) (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	host, ok := a.byIP[ip]
+	return host, ok
+}
@@ -6,25 +6,36 @@ import (
 	"time"
 
 	"github.com/urfave/cli/v3"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	bridgev1 "github.com/vercel/bridge/api/go/bridge/v1"
+	"github.com/vercel/bridge/pkg/admin"
 	"github.com/vercel/bridge/pkg/identity"
 	"github.com/vercel/bridge/pkg/interact"
 )
 
+// watchPollInterval is how often `get --watch` re-lists bridges when the
+// administrator doesn't support the streaming WatchBridges RPC.
+const watchPollInterval = 3 * time.Second
+
 // Get returns the CLI command for listing or inspecting bridges.
 func Get() *cli.Command {
 	return &cli.Command{
 		Name:  "get",
 		Usage: "List bridges or show details of a specific bridge",
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{
 				Name:    "admin-addr",
 				Usage:   "Address of the bridge administrator",
 				Value:   defaultAdminAddr,
 				Sources: cli.EnvVars("BRIDGE_ADMIN_ADDR"),
 			},
-		},
+			&cli.BoolFlag{
+				Name:  "watch",
+				Usage: "Stream bridge updates instead of exiting after the first snapshot",
+			},
+		}, credentialFlags()...),
 		Arguments: []cli.Argument{
 			&cli.StringArg{
 				Name:      "name",
@@ -49,12 +60,21 @@ func runGet(ctx context.Context, c *cli.Command) error {
 		return fmt.Errorf("failed to get device identity: %w", err)
 	}
 
-	adm, _, err := connectAdmin(ctx, adminAddr)
+	credOpts, err := credentialOptionsFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	adm, _, err := connectAdmin(ctx, adminAddr, credOpts...)
 	if err != nil {
 		return err
 	}
 	defer adm.Close()
 
+	if c.Bool("watch") {
+		return watchBridges(ctx, p, adm, deviceID, name)
+	}
+
 	listResp, err := adm.ListBridges(ctx, &bridgev1.ListBridgesRequest{DeviceId: deviceID})
 	if err != nil {
 		return fmt.Errorf("failed to list bridges: %w", err)
@@ -66,16 +86,74 @@ func runGet(ctx context.Context, c *cli.Command) error {
 	return showBridge(p, listResp.Bridges, name)
 }
 
+// watchBridges renders a live-updating bridge table by consuming the
+// administrator's streaming WatchBridges RPC, falling back to polling
+// ListBridges on an interval if the administrator is too old to implement
+// it.
+func watchBridges(ctx context.Context, p interact.Printer, adm admin.Service, deviceID, name string) error {
+	stream, err := adm.WatchBridges(ctx, &bridgev1.WatchBridgesRequest{DeviceId: deviceID})
+	if err != nil {
+		return watchBridgesPoll(ctx, p, adm, deviceID, name)
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if status.Code(err) == codes.Unimplemented {
+				return watchBridgesPoll(ctx, p, adm, deviceID, name)
+			}
+			return fmt.Errorf("watch bridges: %w", err)
+		}
+		if err := renderBridges(p, resp.Bridges, name); err != nil {
+			return err
+		}
+	}
+}
+
+// watchBridgesPoll is the long-poll fallback for administrators that don't
+// implement WatchBridges: it re-lists bridges on a fixed interval until ctx
+// is canceled.
+func watchBridgesPoll(ctx context.Context, p interact.Printer, adm admin.Service, deviceID, name string) error {
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		resp, err := adm.ListBridges(ctx, &bridgev1.ListBridgesRequest{DeviceId: deviceID})
+		if err != nil {
+			return fmt.Errorf("failed to list bridges: %w", err)
+		}
+		if err := renderBridges(p, resp.Bridges, name); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// renderBridges prints one snapshot of the bridge list or, if name is set,
+// the details for that single bridge.
+func renderBridges(p interact.Printer, bridges []*bridgev1.BridgeInfo, name string) error {
+	p.Newline()
+	if name == "" {
+		return listBridges(p, bridges)
+	}
+	return showBridge(p, bridges, name)
+}
+
 func listBridges(p interact.Printer, bridges []*bridgev1.BridgeInfo) error {
 	if len(bridges) == 0 {
 		p.Muted("No active bridges")
 		return nil
 	}
 
-	p.Println(fmt.Sprintf("%-30s %-10s %s", "NAME", "STATUS", "AGE"))
+	p.Println(fmt.Sprintf("%-30s %-10s %-16s %s", "NAME", "STATUS", "HEALTH", "AGE"))
 	for _, b := range bridges {
 		age := humanAge(b.CreatedAt)
-		p.Println(fmt.Sprintf("%-30s %-10s %s", b.DeploymentName, b.Status, age))
+		p.Println(fmt.Sprintf("%-30s %-10s %-16s %s", b.DeploymentName, b.Status, healthLabel(b.Health), age))
 	}
 	return nil
 }
@@ -86,6 +164,7 @@ func showBridge(p interact.Printer, bridges []*bridgev1.BridgeInfo, name string)
 			p.Newline()
 			p.KeyValue("Name", b.DeploymentName)
 			p.KeyValue("Status", b.Status)
+			p.KeyValue("Health", healthLabel(b.Health))
 			p.KeyValue("Age", humanAge(b.CreatedAt))
 			p.KeyValue("Namespace", b.Namespace)
 			if b.SourceDeployment != "" {
@@ -102,6 +181,19 @@ func showBridge(p interact.Printer, bridges []*bridgev1.BridgeInfo, name string)
 	return fmt.Errorf("no bridge named %q found", name)
 }
 
+// healthLabel renders a BridgeHealth as the short form shown in the HEALTH
+// column: "Healthy 12ms", "Unhealthy 3x", or "Unknown" for bridges that
+// haven't been probed yet or are still starting.
+func healthLabel(h *bridgev1.BridgeHealth) string {
+	if h == nil || h.Status == "" || h.Status == "unknown" || h.Status == "starting" {
+		return "Unknown"
+	}
+	if h.Status == "healthy" {
+		return fmt.Sprintf("Healthy %dms", h.AvgLatencyMs)
+	}
+	return fmt.Sprintf("Unhealthy %dx", h.ConsecutiveFailures)
+}
+
 // humanAge parses an RFC 3339 timestamp and returns a human-readable duration
 // string using the shortest unit: "30s", "5m", "2h", "3d".
 func humanAge(rfc3339 string) string {
@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	bridgev1 "github.com/vercel/bridge/api/go/bridge/v1"
+	"github.com/vercel/bridge/pkg/identity"
+	"github.com/vercel/bridge/pkg/interact"
+)
+
+// Health returns the CLI command for inspecting, and optionally configuring,
+// a bridge's health probes.
+func Health() *cli.Command {
+	return &cli.Command{
+		Name:  "health",
+		Usage: "Show a bridge's health status and recent probe history",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:    "admin-addr",
+				Usage:   "Address of the bridge administrator",
+				Value:   defaultAdminAddr,
+				Sources: cli.EnvVars("BRIDGE_ADMIN_ADDR"),
+			},
+			&cli.BoolFlag{
+				Name:  "configure",
+				Usage: "Replace the bridge's configured health probe instead of showing its status",
+			},
+			&cli.StringFlag{
+				Name:  "probe-type",
+				Usage: "Probe type with --configure: http, tcp, or exec",
+			},
+			&cli.StringFlag{
+				Name:  "probe-path",
+				Usage: "HTTP path with --configure --probe-type http",
+			},
+			&cli.IntFlag{
+				Name:  "probe-port",
+				Usage: "Pod port with --configure --probe-type http|tcp",
+			},
+			&cli.StringSliceFlag{
+				Name:  "probe-command",
+				Usage: "Command to exec with --configure --probe-type exec",
+			},
+			&cli.DurationFlag{
+				Name:  "interval",
+				Usage: "Probe interval stored with --configure; the reconciler runs every probe on its own fixed tick and does not read it",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "Per-probe timeout with --configure",
+			},
+			&cli.IntFlag{
+				Name:  "retries",
+				Usage: "Consecutive failures before the bridge is marked unhealthy, with --configure",
+			},
+			&cli.DurationFlag{
+				Name:  "start-period",
+				Usage: "Grace period after which failures start counting towards --retries, with --configure",
+			},
+			&cli.IntFlag{
+				Name:  "success-threshold",
+				Usage: "Consecutive successes before the bridge is marked healthy, with --configure",
+			},
+			&cli.BoolFlag{
+				Name:  "clear",
+				Usage: "With --configure, clear the bridge's probes instead of setting a new one",
+			},
+		}, credentialFlags()...),
+		Arguments: []cli.Argument{
+			&cli.StringArg{
+				Name:      "name",
+				UsageText: "Name of the bridge",
+				Config: cli.StringConfig{
+					TrimSpace: true,
+				},
+			},
+		},
+		Action: runHealth,
+	}
+}
+
+func runHealth(ctx context.Context, c *cli.Command) error {
+	name := c.StringArg("name")
+	if name == "" {
+		return fmt.Errorf("bridge name is required")
+	}
+	adminAddr := c.String("admin-addr")
+
+	p := interact.NewPrinter(c.Root().Writer)
+
+	deviceID, err := identity.GetDeviceID()
+	if err != nil {
+		return fmt.Errorf("failed to get device identity: %w", err)
+	}
+
+	credOpts, err := credentialOptionsFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	adm, _, err := connectAdmin(ctx, adminAddr, credOpts...)
+	if err != nil {
+		return err
+	}
+	defer adm.Close()
+
+	// force=true: bridge health must be able to inspect or reconfigure an
+	// unhealthy bridge rather than being blocked by its own unhealthy status.
+	namespace, err := resolveBridgeNamespace(ctx, adm, name, true)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("configure") {
+		var probes []*bridgev1.ProbeSpec
+		if !c.Bool("clear") {
+			probeType := c.String("probe-type")
+			if probeType == "" {
+				return fmt.Errorf("--probe-type is required with --configure unless --clear is set")
+			}
+			probes = []*bridgev1.ProbeSpec{{
+				Type:               probeType,
+				Path:               c.String("probe-path"),
+				Port:               int32(c.Int("probe-port")),
+				Command:            c.StringSlice("probe-command"),
+				IntervalSeconds:    int64(c.Duration("interval").Seconds()),
+				TimeoutSeconds:     int64(c.Duration("timeout").Seconds()),
+				Retries:            int32(c.Int("retries")),
+				StartPeriodSeconds: int64(c.Duration("start-period").Seconds()),
+				SuccessThreshold:   int32(c.Int("success-threshold")),
+			}}
+		}
+
+		if _, err := adm.ConfigureHealth(ctx, &bridgev1.ConfigureHealthRequest{
+			DeviceId:  deviceID,
+			Namespace: namespace,
+			Name:      name,
+			Probes:    probes,
+		}); err != nil {
+			return fmt.Errorf("failed to configure health probes: %w", err)
+		}
+		p.Success(fmt.Sprintf("Health probes for %q updated", name))
+		return nil
+	}
+
+	checkResp, err := adm.HealthCheck(ctx, &bridgev1.HealthCheckRequest{Namespace: namespace, Name: name})
+	if err != nil {
+		return fmt.Errorf("failed to check health: %w", err)
+	}
+	p.Println(fmt.Sprintf("Status: %s", healthLabel(checkResp.Health)))
+
+	historyResp, err := adm.HealthHistory(ctx, &bridgev1.HealthHistoryRequest{Namespace: namespace, Name: name})
+	if err != nil {
+		return fmt.Errorf("failed to fetch health history: %w", err)
+	}
+	if len(historyResp.Results) == 0 {
+		p.Muted("No probe history yet")
+		return nil
+	}
+
+	p.Println(fmt.Sprintf("%-20s %-8s %-10s %-8s %s", "TIME", "RESULT", "DURATION", "EXIT", "OUTPUT"))
+	for _, r := range historyResp.Results {
+		result := "ok"
+		if !r.Success {
+			result = "fail"
+		}
+		ts := time.Unix(r.TimestampUnix, 0).Format(time.RFC3339)
+		p.Println(fmt.Sprintf("%-20s %-8s %-10s %-8d %s", ts, result, time.Duration(r.DurationMs)*time.Millisecond, r.ExitCode, strings.TrimSpace(r.Output)))
+	}
+	return nil
+}
@@ -17,6 +17,8 @@ import (
 	"github.com/vercel-eddie/bridge/pkg/bidi"
 	"github.com/vercel-eddie/bridge/pkg/mutagen"
 	"github.com/vercel-eddie/bridge/pkg/sshproxy"
+	"github.com/vercel-eddie/bridge/pkg/sshserver/recorder"
+	"github.com/vercel-eddie/bridge/pkg/sync"
 	"github.com/vercel-eddie/bridge/pkg/tunnel"
 )
 
@@ -72,30 +74,72 @@ func Intercept() *cli.Command {
 				Name:  "no-sync",
 				Usage: "Disable file sync",
 			},
+			&cli.StringFlag{
+				Name:    "sync-engine",
+				Usage:   "File sync backend to use: mutagen, rsync, or none. Defaults to mutagen if installed or findable on PATH, else rsync if it's on PATH, else none",
+				Sources: cli.EnvVars("SYNC_ENGINE"),
+			},
+			&cli.StringSliceFlag{
+				Name:  "mutagen-ignore",
+				Usage: "Additional gitignore-style pattern to ignore when --sync-engine=mutagen (repeatable)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "rsync-args",
+				Usage: "Additional argument to pass to rsync when --sync-engine=rsync (repeatable)",
+			},
+			&cli.BoolFlag{
+				Name:  "intercept-udp",
+				Usage: "Also transparently intercept outbound UDP traffic to the proxy CIDR (Linux only)",
+			},
+			&cli.BoolFlag{
+				Name:  "intercept-dns",
+				Usage: "Intercept outbound DNS queries and answer them with addresses allocated from the proxy CIDR, so later TCP flows to those addresses can be traced back to the hostname that was looked up (Linux only)",
+			},
 			&cli.BoolFlag{
 				Name:  "no-ssh-proxy",
 				Usage: "Disable SSH proxy",
 			},
+			&cli.BoolFlag{
+				Name:  "record-sessions",
+				Usage: "Record shells proxied through the SSH proxy as asciinema v2 .cast files, same as the sandbox's own `server --record-sessions`",
+			},
+			&cli.StringFlag{
+				Name:  "record-dir",
+				Usage: "Directory recordings are written under (default ~/.bridge/sessions)",
+			},
 		},
 		Action: runIntercept,
 	}
 }
 
 type interceptor struct {
-	sandboxURL    string
-	functionURL   string
-	name          string
-	proxyPort     int
-	sshProxyPort  int
-	syncSource    string
-	syncTarget    string
-	noSync        bool
-	noSSHProxy    bool
-	tunnel        *tunnel.Client
-	listener      net.Listener
-	sshProxy      *sshproxy.SSHProxy
-	syncName      string
-	mutagenClient *mutagen.Client
+	sandboxURL   string
+	functionURL  string
+	name         string
+	proxyPort    int
+	sshProxyPort int
+	syncSource   string
+	syncTarget   string
+	noSync       bool
+	noSSHProxy   bool
+	interceptUDP bool
+	interceptDNS bool
+	tunnel       *tunnel.Client
+	listener     net.Listener
+	sshProxy     *sshproxy.SSHProxy
+	syncName     string
+	syncEngine   sync.Engine
+	syncSession  sync.Session
+
+	// UDP interception (--intercept-udp).
+	udpProxyPort int
+	udpListener  *net.UDPConn
+	udpFlows     *udpFlowTable
+
+	// DNS interception (--intercept-dns).
+	dnsProxyPort int
+	dnsListener  net.PacketConn
+	dnsAllocator *dnsAllocator
 }
 
 func runIntercept(ctx context.Context, c *cli.Command) error {
@@ -108,6 +152,8 @@ func runIntercept(ctx context.Context, c *cli.Command) error {
 	syncTarget := c.String("sync-target")
 	noSync := c.Bool("no-sync")
 	noSSHProxy := c.Bool("no-ssh-proxy")
+	interceptUDP := c.Bool("intercept-udp")
+	interceptDNS := c.Bool("intercept-dns")
 
 	// Derive name from sandbox URL if not provided
 	if name == "" {
@@ -129,6 +175,8 @@ func runIntercept(ctx context.Context, c *cli.Command) error {
 		syncTarget:   syncTarget,
 		noSync:       noSync,
 		noSSHProxy:   noSSHProxy,
+		interceptUDP: interceptUDP,
+		interceptDNS: interceptDNS,
 		syncName:     "bridge-sync",
 	}
 
@@ -152,20 +200,51 @@ func runIntercept(ctx context.Context, c *cli.Command) error {
 	// Initialize tunnel client
 	i.tunnel = tunnel.NewClient(sandboxURL, functionURL)
 
-	// Set up iptables for traffic interception
-	if err := i.setupIptables(); err != nil {
-		slog.Warn("Failed to setup iptables",
+	// Set up OS-level redirect rules for traffic interception
+	if err := i.setupIntercept(); err != nil {
+		slog.Warn("Failed to setup traffic interception",
 			"error", err,
-			"hint", "Traffic interception requires NET_ADMIN capability",
+			"hint", "Traffic interception requires elevated network privileges",
 		)
 	}
 
+	// Start UDP interception if enabled
+	if interceptUDP {
+		if err := i.startUDPProxyListener(); err != nil {
+			slog.Warn("Failed to start UDP proxy listener", "error", err)
+		} else if err := i.setupUDPIntercept(); err != nil {
+			slog.Warn("Failed to setup UDP traffic interception", "error", err)
+		} else {
+			go i.runUDPProxy()
+		}
+	}
+
+	// Start DNS interception if enabled
+	if interceptDNS {
+		if err := i.startDNSProxyListener(); err != nil {
+			slog.Warn("Failed to start DNS proxy listener", "error", err)
+		} else if err := i.setupDNSIntercept(); err != nil {
+			slog.Warn("Failed to setup DNS traffic interception", "error", err)
+		} else {
+			go i.runDNSProxy()
+		}
+	}
+
 	// Start SSH proxy if enabled
 	if !noSSHProxy {
+		rec, err := interceptSessionRecorder(c)
+		if err != nil {
+			slog.Warn("Failed to configure session recording, continuing without it", "error", err)
+		}
+
 		proxy, err := sshproxy.New(ctx, sshproxy.Config{
 			Name:      name,
 			TunnelURL: sandboxURL,
 			LocalPort: sshProxyPort,
+			// Recorder audits shells proxied through here the same way
+			// sshserver.Config.Recorder does on the sandbox side, so
+			// --record-sessions works end-to-end.
+			Recorder: rec,
 		})
 		if err != nil {
 			slog.Warn("Failed to start SSH proxy", "error", err)
@@ -210,8 +289,14 @@ func runIntercept(ctx context.Context, c *cli.Command) error {
 
 	// Start file sync if enabled
 	if !noSync && i.syncTarget != "" {
-		if err := i.startSync(); err != nil {
-			slog.Error("Failed to start file sync", "error", err)
+		engine, err := selectSyncEngine(c)
+		if err != nil {
+			slog.Error("Failed to configure sync engine", "error", err)
+		} else if engine != nil {
+			i.syncEngine = engine
+			if err := i.startSync(ctx); err != nil {
+				slog.Error("Failed to start file sync", "error", err)
+			}
 		}
 	}
 
@@ -224,12 +309,43 @@ func runIntercept(ctx context.Context, c *cli.Command) error {
 	return nil
 }
 
+// interceptSessionRecorder builds the *recorder.Recorder selected by
+// --record-sessions/--record-dir, or nil if --record-sessions wasn't set.
+func interceptSessionRecorder(c *cli.Command) (*recorder.Recorder, error) {
+	if !c.Bool("record-sessions") {
+		return nil, nil
+	}
+
+	var sink recorder.SessionSink
+	if dir := c.String("record-dir"); dir != "" {
+		localSink, err := recorder.NewLocalSink(dir, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure local recording sink: %w", err)
+		}
+		sink = localSink
+	}
+
+	return recorder.New(recorder.Config{Sink: sink})
+}
+
 func (i *interceptor) cleanup() {
 	i.stopSync()
-	i.cleanupIptables()
+	i.cleanupIntercept()
 	if i.listener != nil {
 		_ = i.listener.Close()
 	}
+	if i.interceptUDP {
+		i.cleanupUDPIntercept()
+		if i.udpListener != nil {
+			_ = i.udpListener.Close()
+		}
+	}
+	if i.interceptDNS {
+		i.cleanupDNSIntercept()
+		if i.dnsListener != nil {
+			_ = i.dnsListener.Close()
+		}
+	}
 	if i.tunnel != nil {
 		_ = i.tunnel.Close()
 	}
@@ -280,115 +396,102 @@ func (i *interceptor) handleOutbound(clientConn net.Conn) {
 
 	slog.Debug("Intercepted outbound connection", "source", sourceAddr, "destination", origDst)
 
+	// If DNS interception allocated this destination's IP for a hostname,
+	// dial the tunnel with that hostname instead of the synthetic IP, so
+	// the dispatcher resolves the name itself the same way it would have
+	// for a connection that was never rewritten.
+	dialDst := origDst
+	if i.dnsAllocator != nil {
+		if host, port, err := net.SplitHostPort(origDst); err == nil {
+			if hostname, ok := i.dnsAllocator.hostnameFor(host); ok {
+				dialDst = net.JoinHostPort(hostname, port)
+			}
+		}
+	}
+
 	// Dial through the tunnel
-	targetConn, err := i.tunnel.DialThroughTunnel(sourceAddr, origDst)
+	targetConn, err := i.tunnel.DialThroughTunnel(sourceAddr, dialDst)
 	if err != nil {
-		slog.Error("Failed to dial through tunnel", "source", sourceAddr, "destination", origDst, "error", err)
+		slog.Error("Failed to dial through tunnel", "source", sourceAddr, "destination", dialDst, "error", err)
 		return
 	}
 	defer targetConn.Close()
 
-	slog.Info("Proxying connection", "source", sourceAddr, "destination", origDst)
+	slog.Info("Proxying connection", "source", sourceAddr, "destination", dialDst)
 
 	// Bidirectional copy
 	bidi.New(clientConn, targetConn).Wait(context.Background())
 }
 
-func (i *interceptor) setupIptables() error {
-	// Check if iptables exists
-	if _, err := exec.LookPath("iptables"); err != nil {
-		return fmt.Errorf("iptables not found: %w", err)
-	}
-
-	// Get our own UID to exclude our traffic from interception
-	uid := fmt.Sprintf("%d", os.Getuid())
-
-	cmds := [][]string{
-		// Create a new chain for our rules
-		{"iptables", "-t", "nat", "-N", "BRIDGE_INTERCEPT"},
-
-		// TCP interception: redirect traffic to our proxy CIDR
-		{"iptables", "-t", "nat", "-A", "BRIDGE_INTERCEPT", "-d", proxyCIDR, "-p", "tcp", "-m", "owner", "!", "--uid-owner", uid, "-j", "REDIRECT", "--to-ports", fmt.Sprintf("%d", i.proxyPort)},
-
-		// Jump to our chain from OUTPUT
-		{"iptables", "-t", "nat", "-A", "OUTPUT", "-d", proxyCIDR, "-p", "tcp", "-j", "BRIDGE_INTERCEPT"},
+// selectSyncEngine builds the sync.Engine selected by --sync-engine, or
+// picks one based on which sync binary is on PATH if the flag is unset.
+// Returns a nil Engine (and nil error) for "none".
+func selectSyncEngine(c *cli.Command) (sync.Engine, error) {
+	name := c.String("sync-engine")
+	if name == "" {
+		name = defaultSyncEngineName()
 	}
 
-	for _, args := range cmds {
-		cmd := exec.Command(args[0], args[1:]...)
-		if output, err := cmd.CombinedOutput(); err != nil {
-			slog.Debug("iptables command failed",
-				"command", args,
-				"error", err,
-				"output", string(output),
-			)
-		}
+	switch name {
+	case "none":
+		return nil, nil
+	case "mutagen":
+		return &sync.MutagenEngine{Ignore: c.StringSlice("mutagen-ignore")}, nil
+	case "rsync":
+		return &sync.RsyncEngine{ExtraArgs: c.StringSlice("rsync-args")}, nil
+	default:
+		return nil, fmt.Errorf("unknown --sync-engine %q", name)
 	}
-
-	slog.Info("iptables rules configured", "proxy_port", i.proxyPort, "proxy_cidr", proxyCIDR)
-	return nil
 }
 
-func (i *interceptor) cleanupIptables() {
-	cmds := [][]string{
-		{"iptables", "-t", "nat", "-D", "OUTPUT", "-d", proxyCIDR, "-p", "tcp", "-j", "BRIDGE_INTERCEPT"},
-		{"iptables", "-t", "nat", "-F", "BRIDGE_INTERCEPT"},
-		{"iptables", "-t", "nat", "-X", "BRIDGE_INTERCEPT"},
-	}
-
-	for _, args := range cmds {
-		cmd := exec.Command(args[0], args[1:]...)
-		_ = cmd.Run()
+// defaultSyncEngineName picks mutagen if it's installed or on PATH (the
+// engine bridge has always used), else rsync if that's on PATH, else none -
+// so intercept still starts in images where neither is available.
+func defaultSyncEngineName() string {
+	if mutagen.IsInstalled() {
+		return "mutagen"
 	}
-	slog.Info("iptables rules cleaned up")
-}
-
-func (i *interceptor) startSync() error {
-	// Install mutagen if not already installed
-	slog.Info("Checking mutagen installation...")
-	if err := mutagen.Install(); err != nil {
-		return fmt.Errorf("failed to install mutagen: %w", err)
+	if _, err := exec.LookPath("mutagen"); err == nil {
+		return "mutagen"
 	}
-	slog.Info("Mutagen installed", "path", mutagen.BinaryPath())
-
-	// Create mutagen client
-	client, err := mutagen.NewClient()
-	if err != nil {
-		return fmt.Errorf("failed to create mutagen client: %w", err)
+	if _, err := exec.LookPath("rsync"); err == nil {
+		return "rsync"
 	}
-	i.mutagenClient = client
+	return "none"
+}
 
+func (i *interceptor) startSync(ctx context.Context) error {
 	// Resolve absolute path for sync source
 	absSource, err := filepath.Abs(i.syncSource)
 	if err != nil {
 		return fmt.Errorf("failed to resolve sync source: %w", err)
 	}
-	slog.Info("Starting mutagen sync", "source", i.syncSource, "abs_source", absSource, "target", i.syncTarget)
+	slog.Info("Starting file sync", "source", i.syncSource, "abs_source", absSource, "target", i.syncTarget)
 
-	// Create sync session
-	if err := client.CreateSyncSession(mutagen.SyncConfig{
+	session, err := i.syncEngine.Start(ctx, sync.Config{
 		Name:      i.syncName,
 		Source:    absSource,
 		Target:    i.syncTarget,
 		IgnoreVCS: true,
-		SyncMode:  "two-way-resolved",
-	}); err != nil {
-		return fmt.Errorf("failed to create mutagen sync: %w", err)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start sync: %w", err)
 	}
+	i.syncSession = session
 
-	slog.Info("Mutagen sync started", "name", i.syncName)
+	slog.Info("File sync started", "name", i.syncName)
 
 	return nil
 }
 
 func (i *interceptor) stopSync() {
-	if i.mutagenClient == nil || i.syncName == "" {
+	if i.syncSession == nil {
 		return
 	}
 
-	if err := i.mutagenClient.TerminateSyncSession(i.syncName); err != nil {
-		slog.Error("Failed to terminate mutagen sync", "error", err)
+	if err := i.syncSession.Terminate(); err != nil {
+		slog.Error("Failed to terminate file sync", "error", err)
 	} else {
-		slog.Info("Mutagen sync terminated", "name", i.syncName)
+		slog.Info("File sync terminated", "name", i.syncName)
 	}
 }
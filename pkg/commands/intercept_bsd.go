@@ -0,0 +1,62 @@
+//go:build darwin || freebsd
+
+package commands
+
+import (
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+)
+
+const pfAnchor = "bridge-intercept"
+
+// setupIntercept configures a pf anchor that redirects outbound traffic to
+// proxyCIDR into our transparent proxy listener, the BSD/macOS equivalent
+// of the iptables REDIRECT rules used on Linux.
+func (i *interceptor) setupIntercept() error {
+	if _, err := exec.LookPath("pfctl"); err != nil {
+		return fmt.Errorf("pfctl not found: %w", err)
+	}
+
+	rules := fmt.Sprintf("rdr pass on lo0 proto tcp to %s -> 127.0.0.1 port %d\n", proxyCIDR, i.proxyPort)
+
+	loadCmd := exec.Command("pfctl", "-a", pfAnchor, "-f", "-")
+	loadCmd.Stdin = strings.NewReader(rules)
+	if output, err := loadCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to load pf anchor: %w: %s", err, output)
+	}
+
+	if output, err := exec.Command("pfctl", "-e").CombinedOutput(); err != nil {
+		// pf may already be enabled; that's fine.
+		slog.Debug("pfctl -e failed (pf may already be enabled)", "error", err, "output", string(output))
+	}
+
+	slog.Info("pf rules configured", "anchor", pfAnchor, "proxy_port", i.proxyPort, "proxy_cidr", proxyCIDR)
+	return nil
+}
+
+// cleanupIntercept flushes the pf anchor installed by setupIntercept.
+func (i *interceptor) cleanupIntercept() {
+	cmd := exec.Command("pfctl", "-a", pfAnchor, "-F", "all")
+	_ = cmd.Run()
+	slog.Info("pf rules cleaned up", "anchor", pfAnchor)
+}
+
+// setupUDPIntercept is not yet implemented on BSD/macOS: TPROXY and
+// IP_RECVORIGDSTADDR are Linux-specific, and pf's rdr-based redirect (used
+// above for TCP) can't preserve a UDP datagram's original destination the
+// way SO_ORIGINAL_DST does for TCP.
+func (i *interceptor) setupUDPIntercept() error {
+	return fmt.Errorf("UDP interception is only supported on Linux")
+}
+
+func (i *interceptor) cleanupUDPIntercept() {}
+
+// setupDNSIntercept is not yet implemented on BSD/macOS; see
+// setupUDPIntercept.
+func (i *interceptor) setupDNSIntercept() error {
+	return fmt.Errorf("DNS interception is only supported on Linux")
+}
+
+func (i *interceptor) cleanupDNSIntercept() {}
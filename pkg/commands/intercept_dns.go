@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// startDNSProxyListener opens the plain UDP listener bridge intercept's DNS
+// mode redirects UDP/53 to. Unlike startUDPProxyListener it doesn't need
+// IP_TRANSPARENT/IP_RECVORIGDSTADDR: every DNS query we intercept gets the
+// same answer regardless of which name server it was actually addressed to.
+func (i *interceptor) startDNSProxyListener() error {
+	conn, err := net.ListenPacket("udp", ":0")
+	if err != nil {
+		return fmt.Errorf("failed to listen for DNS: %w", err)
+	}
+
+	i.dnsListener = conn
+	i.dnsProxyPort = conn.LocalAddr().(*net.UDPAddr).Port
+
+	allocator, err := newDNSAllocator(proxyCIDR)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to configure DNS allocator: %w", err)
+	}
+	i.dnsAllocator = allocator
+
+	return nil
+}
+
+// runDNSProxy answers intercepted DNS queries until the listener is closed.
+func (i *interceptor) runDNSProxy() {
+	slog.Info("DNS proxy listening", "port", i.dnsProxyPort)
+
+	buf := make([]byte, dns.MaxMsgSize)
+	for {
+		n, addr, err := i.dnsListener.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		query := make([]byte, n)
+		copy(query, buf[:n])
+		go i.handleDNSQuery(query, addr)
+	}
+}
+
+// handleDNSQuery answers an intercepted A query by resolving hostname
+// through the tunnel (so we only ever allocate an address for a name the
+// sandbox can actually reach) and returning a deterministic address from
+// proxyCIDR instead of the tunnel's real answer. handleOutbound later
+// reverse-resolves that address back to hostname once the caller opens a
+// TCP connection to it.
+func (i *interceptor) handleDNSQuery(query []byte, addr net.Addr) {
+	req := new(dns.Msg)
+	if err := req.Unpack(query); err != nil {
+		slog.Debug("failed to parse intercepted DNS query", "error", err)
+		return
+	}
+
+	resp := new(dns.Msg)
+	resp.SetReply(req)
+
+	for _, q := range req.Question {
+		if q.Qtype != dns.TypeA {
+			// proxyCIDR is v4-only; leave AAAA and anything else
+			// unanswered so the client falls back to A or to its own
+			// resolver.
+			continue
+		}
+
+		hostname := strings.TrimSuffix(q.Name, ".")
+		if _, err := i.tunnel.ResolveDNS(context.Background(), hostname); err != nil {
+			slog.Debug("tunnel DNS resolution failed", "hostname", hostname, "error", err)
+			continue
+		}
+
+		ip := i.dnsAllocator.allocate(hostname)
+		rr, err := dns.NewRR(fmt.Sprintf("%s 60 IN A %s", q.Name, ip))
+		if err != nil {
+			slog.Debug("failed to build synthesized A record", "hostname", hostname, "error", err)
+			continue
+		}
+		resp.Answer = append(resp.Answer, rr)
+	}
+
+	out, err := resp.Pack()
+	if err != nil {
+		slog.Error("failed to pack intercepted DNS response", "error", err)
+		return
+	}
+	if _, err := i.dnsListener.WriteTo(out, addr); err != nil {
+		slog.Error("failed to write intercepted DNS response", "error", err)
+	}
+}
@@ -0,0 +1,173 @@
+//go:build linux
+
+package commands
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+)
+
+// setupIntercept configures iptables NAT rules that redirect outbound
+// traffic to proxyCIDR into our transparent proxy listener.
+func (i *interceptor) setupIntercept() error {
+	// Check if iptables exists
+	if _, err := exec.LookPath("iptables"); err != nil {
+		return fmt.Errorf("iptables not found: %w", err)
+	}
+
+	// Get our own UID to exclude our traffic from interception
+	uid := fmt.Sprintf("%d", os.Getuid())
+
+	cmds := [][]string{
+		// Create a new chain for our rules
+		{"iptables", "-t", "nat", "-N", "BRIDGE_INTERCEPT"},
+
+		// TCP interception: redirect traffic to our proxy CIDR
+		{"iptables", "-t", "nat", "-A", "BRIDGE_INTERCEPT", "-d", proxyCIDR, "-p", "tcp", "-m", "owner", "!", "--uid-owner", uid, "-j", "REDIRECT", "--to-ports", fmt.Sprintf("%d", i.proxyPort)},
+
+		// Jump to our chain from OUTPUT
+		{"iptables", "-t", "nat", "-A", "OUTPUT", "-d", proxyCIDR, "-p", "tcp", "-j", "BRIDGE_INTERCEPT"},
+	}
+
+	for _, args := range cmds {
+		cmd := exec.Command(args[0], args[1:]...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			slog.Debug("iptables command failed",
+				"command", args,
+				"error", err,
+				"output", string(output),
+			)
+		}
+	}
+
+	slog.Info("iptables rules configured", "proxy_port", i.proxyPort, "proxy_cidr", proxyCIDR)
+	return nil
+}
+
+// cleanupIntercept tears down the iptables rules installed by setupIntercept.
+func (i *interceptor) cleanupIntercept() {
+	cmds := [][]string{
+		{"iptables", "-t", "nat", "-D", "OUTPUT", "-d", proxyCIDR, "-p", "tcp", "-j", "BRIDGE_INTERCEPT"},
+		{"iptables", "-t", "nat", "-F", "BRIDGE_INTERCEPT"},
+		{"iptables", "-t", "nat", "-X", "BRIDGE_INTERCEPT"},
+	}
+
+	for _, args := range cmds {
+		cmd := exec.Command(args[0], args[1:]...)
+		_ = cmd.Run()
+	}
+	slog.Info("iptables rules cleaned up")
+}
+
+// udpFwMark marks packets setupUDPIntercept redirects to ourselves, so the
+// `ip rule`/`ip route` pair below can route them back through lo instead of
+// out whatever interface they were actually headed for. TPROXY only
+// rewrites the socket a locally-generated packet is delivered to once it's
+// been routed back onto this host in the first place; REDIRECT's NAT trick
+// doesn't work for UDP because there's no per-flow conntrack entry to
+// un-DNAT replies through.
+const udpFwMark = "1"
+
+// udpRouteTable is the routing table number `ip rule`/`ip route` use to
+// deliver fwmark'd packets to lo instead of their original interface.
+const udpRouteTable = "100"
+
+// setupUDPIntercept configures the mangle-table TPROXY rule (and backing
+// `ip rule`/`ip route`) that redirect outbound UDP traffic to proxyCIDR
+// into our transparent UDP proxy listener.
+func (i *interceptor) setupUDPIntercept() error {
+	if _, err := exec.LookPath("ip"); err != nil {
+		return fmt.Errorf("ip not found: %w", err)
+	}
+
+	uid := fmt.Sprintf("%d", os.Getuid())
+
+	cmds := [][]string{
+		{"iptables", "-t", "mangle", "-N", "BRIDGE_INTERCEPT_UDP"},
+
+		// Mark matching locally-generated packets...
+		{"iptables", "-t", "mangle", "-A", "BRIDGE_INTERCEPT_UDP", "-d", proxyCIDR, "-p", "udp", "-m", "owner", "!", "--uid-owner", uid, "-j", "MARK", "--set-mark", udpFwMark},
+		{"iptables", "-t", "mangle", "-A", "OUTPUT", "-d", proxyCIDR, "-p", "udp", "-j", "BRIDGE_INTERCEPT_UDP"},
+
+		// ...so this rule/route pair delivers them back to lo, where...
+		{"ip", "rule", "add", "fwmark", udpFwMark, "lookup", udpRouteTable},
+		{"ip", "route", "add", "local", "0.0.0.0/0", "dev", "lo", "table", udpRouteTable},
+
+		// ...TPROXY hands the re-delivered packet to our listener without
+		// a NAT/conntrack entry, preserving the original destination for
+		// IP_RECVORIGDSTADDR to recover.
+		{"iptables", "-t", "mangle", "-A", "PREROUTING", "-d", proxyCIDR, "-p", "udp", "-j", "TPROXY", "--on-port", fmt.Sprintf("%d", i.udpProxyPort), "--on-ip", "127.0.0.1", "--tproxy-mark", udpFwMark},
+	}
+
+	for _, args := range cmds {
+		cmd := exec.Command(args[0], args[1:]...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			slog.Debug("udp intercept command failed", "command", args, "error", err, "output", string(output))
+		}
+	}
+
+	slog.Info("UDP TPROXY rules configured", "proxy_port", i.udpProxyPort, "proxy_cidr", proxyCIDR)
+	return nil
+}
+
+// cleanupUDPIntercept tears down the rules installed by setupUDPIntercept.
+func (i *interceptor) cleanupUDPIntercept() {
+	cmds := [][]string{
+		{"iptables", "-t", "mangle", "-D", "PREROUTING", "-d", proxyCIDR, "-p", "udp", "-j", "TPROXY", "--on-port", fmt.Sprintf("%d", i.udpProxyPort), "--on-ip", "127.0.0.1", "--tproxy-mark", udpFwMark},
+		{"ip", "route", "del", "local", "0.0.0.0/0", "dev", "lo", "table", udpRouteTable},
+		{"ip", "rule", "del", "fwmark", udpFwMark, "lookup", udpRouteTable},
+		{"iptables", "-t", "mangle", "-D", "OUTPUT", "-d", proxyCIDR, "-p", "udp", "-j", "BRIDGE_INTERCEPT_UDP"},
+		{"iptables", "-t", "mangle", "-F", "BRIDGE_INTERCEPT_UDP"},
+		{"iptables", "-t", "mangle", "-X", "BRIDGE_INTERCEPT_UDP"},
+	}
+
+	for _, args := range cmds {
+		cmd := exec.Command(args[0], args[1:]...)
+		_ = cmd.Run()
+	}
+	slog.Info("UDP TPROXY rules cleaned up")
+}
+
+// setupDNSIntercept configures a TPROXY rule that redirects outbound
+// UDP/53 traffic to any destination into our DNS proxy listener, separate
+// from setupUDPIntercept because DNS interception isn't restricted to
+// proxyCIDR: it needs to see every outbound query to allocate an address
+// for it.
+func (i *interceptor) setupDNSIntercept() error {
+	uid := fmt.Sprintf("%d", os.Getuid())
+
+	cmds := [][]string{
+		{"iptables", "-t", "mangle", "-N", "BRIDGE_INTERCEPT_DNS"},
+		{"iptables", "-t", "mangle", "-A", "BRIDGE_INTERCEPT_DNS", "-p", "udp", "--dport", "53", "-m", "owner", "!", "--uid-owner", uid, "-j", "MARK", "--set-mark", udpFwMark},
+		{"iptables", "-t", "mangle", "-A", "OUTPUT", "-p", "udp", "--dport", "53", "-j", "BRIDGE_INTERCEPT_DNS"},
+		{"iptables", "-t", "mangle", "-A", "PREROUTING", "-p", "udp", "--dport", "53", "-j", "TPROXY", "--on-port", fmt.Sprintf("%d", i.dnsProxyPort), "--on-ip", "127.0.0.1", "--tproxy-mark", udpFwMark},
+	}
+
+	for _, args := range cmds {
+		cmd := exec.Command(args[0], args[1:]...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			slog.Debug("dns intercept command failed", "command", args, "error", err, "output", string(output))
+		}
+	}
+
+	slog.Info("DNS TPROXY rules configured", "proxy_port", i.dnsProxyPort)
+	return nil
+}
+
+// cleanupDNSIntercept tears down the rules installed by setupDNSIntercept.
+func (i *interceptor) cleanupDNSIntercept() {
+	cmds := [][]string{
+		{"iptables", "-t", "mangle", "-D", "PREROUTING", "-p", "udp", "--dport", "53", "-j", "TPROXY", "--on-port", fmt.Sprintf("%d", i.dnsProxyPort), "--on-ip", "127.0.0.1", "--tproxy-mark", udpFwMark},
+		{"iptables", "-t", "mangle", "-D", "OUTPUT", "-p", "udp", "--dport", "53", "-j", "BRIDGE_INTERCEPT_DNS"},
+		{"iptables", "-t", "mangle", "-F", "BRIDGE_INTERCEPT_DNS"},
+		{"iptables", "-t", "mangle", "-X", "BRIDGE_INTERCEPT_DNS"},
+	}
+
+	for _, args := range cmds {
+		cmd := exec.Command(args[0], args[1:]...)
+		_ = cmd.Run()
+	}
+	slog.Info("DNS TPROXY rules cleaned up")
+}
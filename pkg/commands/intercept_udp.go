@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vercel-eddie/bridge/pkg/netutil"
+)
+
+// udpFlowIdleTimeout closes a UDP flow's tunnel after this long without a
+// datagram in either direction, mirroring pkg/proxy.UDPProxy's default.
+const udpFlowIdleTimeout = 60 * time.Second
+
+// udpFlow is one client source address's tunnel to wherever its first
+// datagram's original destination pointed.
+type udpFlow struct {
+	tunnel     net.PacketConn
+	lastActive atomic.Int64 // unix nano, updated on each datagram
+	closeOnce  sync.Once
+}
+
+func (f *udpFlow) touch() {
+	f.lastActive.Store(time.Now().UnixNano())
+}
+
+func (f *udpFlow) idleSince() time.Duration {
+	return time.Since(time.Unix(0, f.lastActive.Load()))
+}
+
+func (f *udpFlow) Close() {
+	f.closeOnce.Do(func() { f.tunnel.Close() })
+}
+
+// udpFlowTable is the mutex-guarded map of in-flight UDP flows, keyed by
+// client source address.
+type udpFlowTable struct {
+	mu    sync.Mutex
+	flows map[string]*udpFlow
+}
+
+func newUDPFlowTable() *udpFlowTable {
+	return &udpFlowTable{flows: make(map[string]*udpFlow)}
+}
+
+func (t *udpFlowTable) get(key string) (*udpFlow, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	flow, ok := t.flows[key]
+	return flow, ok
+}
+
+func (t *udpFlowTable) put(key string, flow *udpFlow) {
+	t.mu.Lock()
+	t.flows[key] = flow
+	t.mu.Unlock()
+}
+
+func (t *udpFlowTable) delete(key string, flow *udpFlow) {
+	t.mu.Lock()
+	if t.flows[key] == flow {
+		delete(t.flows, key)
+	}
+	t.mu.Unlock()
+}
+
+func (t *udpFlowTable) idleSnapshot(idleTimeout time.Duration) map[string]*udpFlow {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stale := make(map[string]*udpFlow)
+	for key, flow := range t.flows {
+		if flow.idleSince() > idleTimeout {
+			stale[key] = flow
+		}
+	}
+	return stale
+}
+
+// startUDPProxyListener opens the transparent UDP listener that a TPROXY
+// iptables rule (see setupUDPIntercept) redirects proxyCIDR-bound UDP
+// traffic into.
+func (i *interceptor) startUDPProxyListener() error {
+	conn, err := netutil.ListenTransparentUDP(":0")
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	i.udpListener = conn
+	i.udpProxyPort = conn.LocalAddr().(*net.UDPAddr).Port
+	i.udpFlows = newUDPFlowTable()
+
+	return nil
+}
+
+// runUDPProxy reads datagrams off the transparent UDP listener and forwards
+// each distinct source address's flow through its own tunnel.PacketConn,
+// until the listener is closed.
+func (i *interceptor) runUDPProxy() {
+	slog.Info("UDP proxy listening", "port", i.udpProxyPort)
+
+	go i.reapIdleUDPFlows()
+
+	buf := make([]byte, 65507)
+	for {
+		n, srcAddr, origDst, err := netutil.ReadFromUDPWithOrigDst(i.udpListener, buf)
+		if err != nil {
+			return
+		}
+
+		flow, err := i.udpFlowFor(srcAddr, origDst)
+		if err != nil {
+			slog.Error("failed to establish udp tunnel", "error", err, "source", srcAddr, "destination", origDst)
+			continue
+		}
+
+		flow.touch()
+		if _, err := flow.tunnel.WriteTo(buf[:n], srcAddr); err != nil {
+			slog.Error("failed to write udp datagram through tunnel", "error", err, "source", srcAddr)
+			i.closeUDPFlow(srcAddr.String(), flow)
+		}
+	}
+}
+
+// udpFlowFor returns the existing flow for srcAddr, dialing a new tunnel to
+// origDst (and starting its response reader) if this is the first datagram
+// seen from it.
+func (i *interceptor) udpFlowFor(srcAddr *net.UDPAddr, origDst string) (*udpFlow, error) {
+	key := srcAddr.String()
+
+	if flow, ok := i.udpFlows.get(key); ok {
+		return flow, nil
+	}
+
+	tunnelConn, err := i.tunnel.DialUDPThroughTunnel(key, origDst)
+	if err != nil {
+		return nil, err
+	}
+
+	flow := &udpFlow{tunnel: tunnelConn}
+	flow.touch()
+	i.udpFlows.put(key, flow)
+
+	go i.readUDPFlowResponses(srcAddr, key, flow)
+
+	return flow, nil
+}
+
+// readUDPFlowResponses copies datagrams the tunnel sends back for srcAddr's
+// flow out to the transparent UDP listener until the tunnel closes, then
+// tears the flow down.
+func (i *interceptor) readUDPFlowResponses(srcAddr *net.UDPAddr, key string, flow *udpFlow) {
+	defer i.closeUDPFlow(key, flow)
+
+	buf := make([]byte, 65507)
+	for {
+		n, _, err := flow.tunnel.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		flow.touch()
+		if _, err := i.udpListener.WriteToUDP(buf[:n], srcAddr); err != nil {
+			slog.Error("failed to write udp response", "error", err, "source", srcAddr)
+			return
+		}
+	}
+}
+
+func (i *interceptor) closeUDPFlow(key string, flow *udpFlow) {
+	i.udpFlows.delete(key, flow)
+	flow.Close()
+}
+
+// reapIdleUDPFlows periodically closes flows that haven't carried a
+// datagram in either direction for udpFlowIdleTimeout.
+func (i *interceptor) reapIdleUDPFlows() {
+	ticker := time.NewTicker(udpFlowIdleTimeout / 2)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if i.udpListener == nil {
+			return
+		}
+
+		stale := i.udpFlows.idleSnapshot(udpFlowIdleTimeout)
+		for key, flow := range stale {
+			slog.Debug("closing idle udp flow", "flow", key, "idle", flow.idleSince())
+			i.closeUDPFlow(key, flow)
+		}
+	}
+}
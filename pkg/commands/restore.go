@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+
+	bridgev1 "github.com/vercel/bridge/api/go/bridge/v1"
+	"github.com/vercel/bridge/pkg/identity"
+	"github.com/vercel/bridge/pkg/interact"
+)
+
+// Restore returns the CLI command for rehydrating a snapshot into a running bridge.
+func Restore() *cli.Command {
+	return &cli.Command{
+		Name:  "restore",
+		Usage: "Restore a bridge's workspace from a snapshot",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:    "admin-addr",
+				Usage:   "Address of the bridge administrator",
+				Value:   defaultAdminAddr,
+				Sources: cli.EnvVars("BRIDGE_ADMIN_ADDR"),
+			},
+			&cli.StringFlag{
+				Name:     "namespace",
+				Usage:    "Namespace the bridge lives in",
+				Required: true,
+			},
+		}, credentialFlags()...),
+		Arguments: []cli.Argument{
+			&cli.StringArg{
+				Name:      "name",
+				UsageText: "Name of the bridge to restore into",
+				Config: cli.StringConfig{
+					TrimSpace: true,
+				},
+			},
+			&cli.StringArg{
+				Name:      "snapshot-name",
+				UsageText: "Name of the snapshot to restore",
+				Config: cli.StringConfig{
+					TrimSpace: true,
+				},
+			},
+		},
+		Action: runRestore,
+	}
+}
+
+func runRestore(ctx context.Context, c *cli.Command) error {
+	name := c.StringArg("name")
+	snapshotName := c.StringArg("snapshot-name")
+	if name == "" || snapshotName == "" {
+		return fmt.Errorf("bridge name and snapshot name are required")
+	}
+	adminAddr := c.String("admin-addr")
+	namespace := c.String("namespace")
+
+	p := interact.NewPrinter(c.Root().Writer)
+
+	deviceID, err := identity.GetDeviceID()
+	if err != nil {
+		return fmt.Errorf("failed to get device identity: %w", err)
+	}
+
+	credOpts, err := credentialOptionsFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	adm, _, err := connectAdmin(ctx, adminAddr, credOpts...)
+	if err != nil {
+		return err
+	}
+	defer adm.Close()
+
+	sp := interact.NewSpinner("Restoring snapshot...")
+	go sp.Start(ctx)
+
+	_, err = adm.RestoreBridge(ctx, &bridgev1.RestoreBridgeRequest{
+		DeviceId:     deviceID,
+		Namespace:    namespace,
+		Name:         name,
+		SnapshotName: snapshotName,
+	})
+	sp.Stop()
+	if err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+
+	p.Newline()
+	p.Success(fmt.Sprintf("Snapshot %q restored into %q", snapshotName, name))
+	return nil
+}
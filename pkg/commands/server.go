@@ -4,14 +4,17 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"os/signal"
-	"syscall"
 	"time"
 
+	"github.com/reach/pkg/sessions"
 	"github.com/urfave/cli/v3"
 	"github.com/vercel-eddie/bridge/pkg/mutagen"
 	"github.com/vercel-eddie/bridge/pkg/proxy"
 	"github.com/vercel-eddie/bridge/pkg/sshserver"
+	"github.com/vercel-eddie/bridge/pkg/sshserver/recorder"
+	"github.com/vercel/bridge/pkg/k8s/kube"
+
+	"k8s.io/client-go/kubernetes"
 )
 
 func Server() *cli.Command {
@@ -54,11 +57,128 @@ func Server() *cli.Command {
 				Value:   2 * time.Hour,
 				Sources: cli.EnvVars("SSH_MAX_TIMEOUT"),
 			},
+			&cli.DurationFlag{
+				Name:    "drain-timeout",
+				Usage:   "How long to wait for in-flight tunnels to finish on shutdown before forcing them closed",
+				Value:   30 * time.Second,
+				Sources: cli.EnvVars("SSH_DRAIN_TIMEOUT"),
+			},
+			&cli.StringFlag{
+				Name:    "session-store",
+				Usage:   "Where to register this sandbox's session: file (~/.bridge/sessions.json) or kube (ConfigMaps, for administrators running in-cluster)",
+				Value:   "file",
+				Sources: cli.EnvVars("SESSION_STORE"),
+			},
+			&cli.StringFlag{
+				Name:    "session-namespace",
+				Usage:   "Namespace for session ConfigMaps when --session-store=kube",
+				Value:   "bridge",
+				Sources: cli.EnvVars("SESSION_NAMESPACE"),
+			},
+			&cli.StringFlag{
+				Name:    "device-id",
+				Usage:   "Device KSUID recorded on this sandbox's session when --session-store=kube",
+				Sources: cli.EnvVars("BRIDGE_DEVICE_ID"),
+			},
+			&cli.BoolFlag{
+				Name:    "record-sessions",
+				Usage:   "Record every SSH session as an asciinema v2 .cast file for audit",
+				Sources: cli.EnvVars("SSH_RECORD_SESSIONS"),
+			},
+			&cli.BoolFlag{
+				Name:    "record-input",
+				Usage:   "Also record client keystrokes, not just shell output (--record-sessions)",
+				Sources: cli.EnvVars("SSH_RECORD_INPUT"),
+			},
+			&cli.StringFlag{
+				Name:    "record-dir",
+				Usage:   "Directory recordings are written under (default ~/.bridge/sessions); ignored when --record-s3-bucket is set",
+				Sources: cli.EnvVars("SSH_RECORD_DIR"),
+			},
+			&cli.StringFlag{
+				Name:    "record-s3-bucket",
+				Usage:   "Upload recordings to this S3(-compatible) bucket instead of writing them locally",
+				Sources: cli.EnvVars("SSH_RECORD_S3_BUCKET"),
+			},
+			&cli.StringFlag{
+				Name:    "record-s3-region",
+				Usage:   "Region of --record-s3-bucket",
+				Sources: cli.EnvVars("SSH_RECORD_S3_REGION"),
+			},
+			&cli.StringFlag{
+				Name:    "record-s3-prefix",
+				Usage:   "Key prefix for uploaded recordings",
+				Sources: cli.EnvVars("SSH_RECORD_S3_PREFIX"),
+			},
+			&cli.StringFlag{
+				Name:    "record-s3-endpoint",
+				Usage:   "S3-compatible endpoint to upload recordings to (default: AWS's regional endpoint for --record-s3-region)",
+				Sources: cli.EnvVars("SSH_RECORD_S3_ENDPOINT"),
+			},
 		},
 		Action: runServer,
 	}
 }
 
+// sessionRecorder builds the *recorder.Recorder selected by --record-*, or
+// nil if --record-sessions wasn't set.
+func sessionRecorder(c *cli.Command) (*recorder.Recorder, error) {
+	if !c.Bool("record-sessions") {
+		return nil, nil
+	}
+
+	var sink recorder.SessionSink
+	if bucket := c.String("record-s3-bucket"); bucket != "" {
+		s3Sink, err := recorder.NewS3SinkFromEnv(recorder.S3Sink{
+			Bucket:   bucket,
+			Region:   c.String("record-s3-region"),
+			Prefix:   c.String("record-s3-prefix"),
+			Endpoint: c.String("record-s3-endpoint"),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure S3 recording sink: %w", err)
+		}
+		sink = s3Sink
+	} else if dir := c.String("record-dir"); dir != "" {
+		localSink, err := recorder.NewLocalSink(dir, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure local recording sink: %w", err)
+		}
+		sink = localSink
+	}
+
+	return recorder.New(recorder.Config{
+		Sink:         sink,
+		CaptureInput: c.Bool("record-input"),
+	})
+}
+
+// sessionStore builds the sessions.Store selected by --session-store. "file"
+// works for a sandbox running on the operator's own machine; "kube" is for
+// sandboxes running in a cluster the administrator also manages, where
+// ~/.bridge/sessions.json isn't visible to (or shared across) anything else.
+func sessionStore(c *cli.Command) (sessions.Store, error) {
+	switch backend := c.String("session-store"); backend {
+	case "", "file":
+		return sessions.NewFileStore()
+	case "kube":
+		restCfg, err := kube.RestConfig(kube.Config{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+		}
+		clientset, err := kubernetes.NewForConfig(restCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+		}
+		return sessions.NewKubeStore(clientset, sessions.KubeStoreConfig{
+			Namespace: c.String("session-namespace"),
+			DeviceID:  c.String("device-id"),
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown --session-store %q", backend)
+	}
+}
+
 func runServer(ctx context.Context, c *cli.Command) error {
 	// Install mutagen agent if not already installed
 	// This is needed for file sync between devcontainer and sandbox
@@ -74,6 +194,11 @@ func runServer(ctx context.Context, c *cli.Command) error {
 	sshPort := c.Int("port")
 	proxyPort := c.Int("proxy-port")
 
+	rec, err := sessionRecorder(c)
+	if err != nil {
+		slog.Warn("failed to configure session recording, continuing without it", "error", err)
+	}
+
 	cfg := sshserver.Config{
 		Host:            host,
 		Port:            sshPort,
@@ -81,6 +206,7 @@ func runServer(ctx context.Context, c *cli.Command) error {
 		MaxTimeout:      c.Duration("max-timeout"),
 		AgentForwarding: true,
 		SessionHandler:  sshserver.ShellHandler(),
+		Recorder:        rec,
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -99,7 +225,27 @@ func runServer(ctx context.Context, c *cli.Command) error {
 		Name:   name,
 	})
 
-	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	if name != "" {
+		store, err := sessionStore(c)
+		if err != nil {
+			slog.Warn("failed to initialize session store, session will not be registered", "error", err)
+		} else {
+			session := sessions.Session{URL: fmt.Sprintf("http://%s:%d", host, proxyPort)}
+			if err := store.Add(name, session); err != nil {
+				slog.Warn("failed to register session", "name", name, "error", err)
+			} else {
+				defer func() {
+					if err := store.Remove(name); err != nil {
+						slog.Warn("failed to remove session on shutdown", "name", name, "error", err)
+					}
+				}()
+			}
+		}
+	}
+
+	drainTimeout := c.Duration("drain-timeout")
+
+	ctx, stop := trapSignals(ctx)
 	defer stop()
 
 	errCh := make(chan error, 2)
@@ -114,7 +260,7 @@ func runServer(ctx context.Context, c *cli.Command) error {
 	case err := <-errCh:
 		return err
 	case <-ctx.Done():
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
 		defer cancel()
 		srv.Shutdown(shutdownCtx)
 		wsServer.Shutdown(shutdownCtx)
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// trapSignals returns a context canceled on the first SIGINT/SIGTERM, so a
+// caller can start a graceful shutdown (e.g. draining in-flight tunnels).
+// A second signal just logs that a drain is already underway. A third forces
+// an immediate exit, so an operator mashing Ctrl-C on a stuck drain isn't
+// stuck themselves.
+func trapSignals(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	sigCh := make(chan os.Signal, 3)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		strikes := 0
+		for {
+			select {
+			case <-sigCh:
+				strikes++
+				switch strikes {
+				case 1:
+					slog.Info("received shutdown signal, draining (press again to force exit)")
+					cancel()
+				case 2:
+					slog.Warn("received second shutdown signal, still draining")
+				default:
+					slog.Error("received third shutdown signal, forcing exit")
+					os.Exit(1)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(sigCh)
+		close(done)
+		cancel()
+	}
+}
@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+
+	bridgev1 "github.com/vercel/bridge/api/go/bridge/v1"
+	"github.com/vercel/bridge/pkg/admin"
+	"github.com/vercel/bridge/pkg/identity"
+	"github.com/vercel/bridge/pkg/interact"
+)
+
+// Snapshot returns the CLI command for capturing and listing bridge snapshots.
+func Snapshot() *cli.Command {
+	return &cli.Command{
+		Name:  "snapshot",
+		Usage: "Capture or list bridge workspace snapshots",
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:    "admin-addr",
+				Usage:   "Address of the bridge administrator",
+				Value:   defaultAdminAddr,
+				Sources: cli.EnvVars("BRIDGE_ADMIN_ADDR"),
+			},
+			&cli.StringFlag{
+				Name:  "namespace",
+				Usage: "Namespace the bridge lives in (optional — omit to search across namespaces)",
+			},
+			&cli.BoolFlag{
+				Name:  "list",
+				Usage: "List existing snapshots instead of capturing a new one",
+			},
+		}, credentialFlags()...),
+		Arguments: []cli.Argument{
+			&cli.StringArg{
+				Name:      "name",
+				UsageText: "Name of the bridge to snapshot",
+				Config: cli.StringConfig{
+					TrimSpace: true,
+				},
+			},
+			&cli.StringArg{
+				Name:      "snapshot-name",
+				UsageText: "Name to give the snapshot (omit with --list)",
+				Config: cli.StringConfig{
+					TrimSpace: true,
+				},
+			},
+		},
+		Action: runSnapshot,
+	}
+}
+
+func runSnapshot(ctx context.Context, c *cli.Command) error {
+	name := c.StringArg("name")
+	if name == "" {
+		return fmt.Errorf("bridge name is required")
+	}
+	adminAddr := c.String("admin-addr")
+	namespace := c.String("namespace")
+
+	p := interact.NewPrinter(c.Root().Writer)
+
+	deviceID, err := identity.GetDeviceID()
+	if err != nil {
+		return fmt.Errorf("failed to get device identity: %w", err)
+	}
+
+	credOpts, err := credentialOptionsFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	adm, _, err := connectAdmin(ctx, adminAddr, credOpts...)
+	if err != nil {
+		return err
+	}
+	defer adm.Close()
+
+	if c.Bool("list") {
+		return listSnapshots(ctx, p, adm, deviceID, namespace, name)
+	}
+
+	snapshotName := c.StringArg("snapshot-name")
+	if snapshotName == "" {
+		return fmt.Errorf("snapshot name is required")
+	}
+	if namespace == "" {
+		return fmt.Errorf("--namespace is required to capture a snapshot")
+	}
+
+	sp := interact.NewSpinner("Capturing snapshot...")
+	go sp.Start(ctx)
+
+	resp, err := adm.SnapshotBridge(ctx, &bridgev1.SnapshotBridgeRequest{
+		DeviceId:     deviceID,
+		Namespace:    namespace,
+		Name:         name,
+		SnapshotName: snapshotName,
+	})
+	sp.Stop()
+	if err != nil {
+		return fmt.Errorf("failed to capture snapshot: %w", err)
+	}
+
+	p.Newline()
+	p.Success(fmt.Sprintf("Snapshot %q captured (%s)", resp.Snapshot.Name, resp.Snapshot.Method))
+	return nil
+}
+
+func listSnapshots(ctx context.Context, p interact.Printer, adm admin.Service, deviceID, namespace, name string) error {
+	resp, err := adm.ListSnapshots(ctx, &bridgev1.ListSnapshotsRequest{
+		DeviceId:  deviceID,
+		Namespace: namespace,
+		Name:      name,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	if len(resp.Snapshots) == 0 {
+		p.Muted("No snapshots")
+		return nil
+	}
+
+	p.Println(fmt.Sprintf("%-20s %-10s %-30s %s", "NAME", "METHOD", "NAMESPACE", "CREATED"))
+	for _, s := range resp.Snapshots {
+		p.Println(fmt.Sprintf("%-20s %-10s %-30s %s", s.Snapshot.Name, s.Snapshot.Method, s.Namespace, s.Snapshot.CreatedAt))
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build darwin || freebsd
+
+package commands
+
+import (
+	"net"
+
+	"github.com/vercel-eddie/bridge/pkg/netutil"
+)
+
+// getOriginalDst recovers the pre-redirect destination via pf's
+// DIOCNATLOOK, mirroring what sockopt_linux.go does with SO_ORIGINAL_DST.
+func getOriginalDst(conn net.Conn) (string, error) {
+	return netutil.OriginalDest(conn)
+}
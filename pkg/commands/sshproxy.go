@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+	"github.com/vercel-eddie/bridge/pkg/proxy/carrier"
+)
+
+// SSHProxy implements `bridge ssh-proxy <wss-url>`, meant to be dropped into
+// an SSH config as `ProxyCommand bridge ssh-proxy wss://sandbox.example/ssh`
+// so ssh tunnels over a WebSocket edge without a local TCP listener,
+// matching how cloudflared's carrier works for `cloudflared access ssh`.
+func SSHProxy() *cli.Command {
+	return &cli.Command{
+		Name:      "ssh-proxy",
+		Usage:     "Tunnel SSH over a bridge WebSocket endpoint (for ssh -o ProxyCommand)",
+		ArgsUsage: "<wss-url>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "listener",
+				Usage: "Instead of piping stdio, listen on this address and tunnel each accepted TCP connection over a fresh WebSocket",
+			},
+			&cli.StringFlag{
+				Name:    "protection-bypass",
+				Usage:   "Value sent as the x-vercel-protection-bypass header",
+				Sources: cli.EnvVars("VERCEL_PROTECTION_BYPASS"),
+			},
+			&cli.StringSliceFlag{
+				Name:    "header",
+				Aliases: []string{"H"},
+				Usage:   "Extra header to send on dial, as \"Name: Value\" (repeatable)",
+			},
+		},
+		Arguments: []cli.Argument{
+			&cli.StringArg{
+				Name:      "url",
+				UsageText: "The ws(s):// or http(s):// URL of the tunnel server's /ssh endpoint",
+				Config: cli.StringConfig{
+					TrimSpace: true,
+				},
+			},
+		},
+		Action: runSSHProxy,
+	}
+}
+
+func runSSHProxy(ctx context.Context, c *cli.Command) error {
+	tunnelURL := c.StringArg("url")
+	if tunnelURL == "" {
+		return fmt.Errorf("ssh-proxy: missing <wss-url> argument")
+	}
+
+	headers, err := parseHeaderFlags(c.StringSlice("header"))
+	if err != nil {
+		return err
+	}
+
+	cfg := carrier.Config{
+		TunnelURL:              tunnelURL,
+		ProtectionBypassSecret: c.String("protection-bypass"),
+		Headers:                headers,
+	}
+
+	ctx, stop := trapSignals(ctx)
+	defer stop()
+
+	if listener := c.String("listener"); listener != "" {
+		return carrier.RunListener(ctx, cfg, listener)
+	}
+	return carrier.RunStdio(ctx, cfg)
+}
+
+// parseHeaderFlags parses "-H Name: Value" flags into an http.Header.
+func parseHeaderFlags(raw []string) (http.Header, error) {
+	header := http.Header{}
+	for _, h := range raw {
+		name, value, ok := strings.Cut(h, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid -H value %q, expected \"Name: Value\"", h)
+		}
+		header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return header, nil
+}
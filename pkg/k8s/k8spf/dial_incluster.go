@@ -0,0 +1,53 @@
+package k8spf
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/vercel/bridge/pkg/k8s/kube"
+)
+
+// ResolveInCluster resolves target directly to a dialable "ip:port" address
+// via the Kubernetes API, without shelling out to kubectl port-forward. It's
+// only usable from a process running inside the cluster, where the pod IPs
+// the API server returns are actually reachable.
+//
+// workload=="service" isn't resolved here: it already gets client-side load
+// balancing across every ready pod via the EndpointSlice watch in
+// resolver_service.go, which needs no port-forward tunnel to begin with and
+// so behaves identically whether or not mode=incluster is set.
+func ResolveInCluster(ctx context.Context, clientset kubernetes.Interface, target Target) (string, error) {
+	switch target.Workload {
+	case "pod", "":
+		return podAddr(ctx, clientset, target.Namespace, target.Name, target.Port)
+	case "deployment", "statefulset", "daemonset", "replicaset":
+		selector, err := kube.NewPodSelector(target.Selector)
+		if err != nil {
+			return "", err
+		}
+		podName, err := kube.GetFirstPodForWorkloadWithSelector(ctx, clientset, target.Namespace, target.Workload, target.Name, selector, target.DeviceID)
+		if err != nil {
+			return "", err
+		}
+		return podAddr(ctx, clientset, target.Namespace, podName, target.Port)
+	default:
+		return "", fmt.Errorf("k8spf: in-cluster mode doesn't resolve workload %q directly", target.Workload)
+	}
+}
+
+// podAddr looks up name's pod IP and joins it with port.
+func podAddr(ctx context.Context, clientset kubernetes.Interface, namespace, name string, port int) (string, error) {
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("get pod %s/%s: %w", namespace, name, err)
+	}
+	if pod.Status.PodIP == "" {
+		return "", fmt.Errorf("pod %s/%s has no assigned IP", namespace, name)
+	}
+	return net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(port)), nil
+}
@@ -1,9 +1,19 @@
 package k8spf
 
 import (
+	"context"
+	"sync"
 	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 
 	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/serviceconfig"
 )
 
 func TestParseAddr(t *testing.T) {
@@ -38,6 +48,41 @@ func TestParseAddr(t *testing.T) {
 			addr: "deploy.ns:9090?workload=deployment",
 			want: Target{Name: "deploy", Namespace: "ns", Port: 9090, Workload: "deployment"},
 		},
+		{
+			name: "workload service",
+			addr: "svc.ns:9090?workload=service",
+			want: Target{Name: "svc", Namespace: "ns", Port: 9090, Workload: "service"},
+		},
+		{
+			name: "workload statefulset",
+			addr: "sts.ns:9090?workload=statefulset",
+			want: Target{Name: "sts", Namespace: "ns", Port: 9090, Workload: "statefulset"},
+		},
+		{
+			name: "workload daemonset",
+			addr: "ds.ns:9090?workload=daemonset",
+			want: Target{Name: "ds", Namespace: "ns", Port: 9090, Workload: "daemonset"},
+		},
+		{
+			name: "workload replicaset",
+			addr: "rs.ns:9090?workload=replicaset",
+			want: Target{Name: "rs", Namespace: "ns", Port: 9090, Workload: "replicaset"},
+		},
+		{
+			name: "incluster mode",
+			addr: "admin-pod.bridge:9090?mode=incluster",
+			want: Target{Name: "admin-pod", Namespace: "bridge", Port: 9090, Workload: "pod", Mode: "incluster"},
+		},
+		{
+			name: "incluster mode with deployment workload",
+			addr: "deploy.ns:9090?workload=deployment&mode=incluster",
+			want: Target{Name: "deploy", Namespace: "ns", Port: 9090, Workload: "deployment", Mode: "incluster"},
+		},
+		{
+			name:    "invalid mode",
+			addr:    "pod.ns:9090?mode=bogus",
+			wantErr: true,
+		},
 		{
 			name: "explicit workload pod",
 			addr: "pod.ns:9090?workload=pod",
@@ -184,6 +229,26 @@ func TestTargetString(t *testing.T) {
 			target: Target{Name: "bridge-administrator", Namespace: "bridge", Port: 9090, Workload: "deployment"},
 			want:   "bridge-administrator.bridge:9090?workload=deployment",
 		},
+		{
+			name:   "service workload includes query",
+			target: Target{Name: "svc", Namespace: "bridge", Port: 9090, Workload: "service"},
+			want:   "svc.bridge:9090?workload=service",
+		},
+		{
+			name:   "statefulset workload includes query",
+			target: Target{Name: "sts", Namespace: "bridge", Port: 9090, Workload: "statefulset"},
+			want:   "sts.bridge:9090?workload=statefulset",
+		},
+		{
+			name:   "incluster mode includes query",
+			target: Target{Name: "admin-pod", Namespace: "bridge", Port: 9090, Workload: "pod", Mode: "incluster"},
+			want:   "admin-pod.bridge:9090?mode=incluster",
+		},
+		{
+			name:   "incluster mode with deployment workload",
+			target: Target{Name: "deploy", Namespace: "ns", Port: 9090, Workload: "deployment", Mode: "incluster"},
+			want:   "deploy.ns:9090?mode=incluster&workload=deployment",
+		},
 	}
 
 	for _, tt := range tests {
@@ -194,3 +259,165 @@ func TestTargetString(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveInCluster(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "admin-pod", Namespace: "bridge"},
+		Status:     corev1.PodStatus{PodIP: "10.1.2.3"},
+	})
+
+	got, err := ResolveInCluster(context.Background(), clientset, Target{
+		Name: "admin-pod", Namespace: "bridge", Port: 9090, Workload: "pod", Mode: "incluster",
+	})
+	if err != nil {
+		t.Fatalf("ResolveInCluster error: %v", err)
+	}
+	if want := "10.1.2.3:9090"; got != want {
+		t.Errorf("ResolveInCluster = %q, want %q", got, want)
+	}
+}
+
+func TestResolveInClusterStatefulSet(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "bridge"},
+			Spec: appsv1.StatefulSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "bridge", Labels: map[string]string{"app": "db"}},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.1.2.4"},
+		},
+	)
+
+	got, err := ResolveInCluster(context.Background(), clientset, Target{
+		Name: "db", Namespace: "bridge", Port: 5432, Workload: "statefulset", Mode: "incluster",
+	})
+	if err != nil {
+		t.Fatalf("ResolveInCluster error: %v", err)
+	}
+	if want := "10.1.2.4:5432"; got != want {
+		t.Errorf("ResolveInCluster = %q, want %q", got, want)
+	}
+}
+
+func TestWatchServiceEndpointChurn(t *testing.T) {
+	ready := true
+	notReady := false
+
+	clientset := fake.NewSimpleClientset()
+	target := Target{Name: "svc", Namespace: "ns", Port: 9090, Workload: "service"}
+
+	cc := newFakeClientConn()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watchService(ctx, clientset, target, cc)
+
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "svc-abc",
+			Namespace: "ns",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "svc"},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: &ready}},
+		},
+	}
+	if _, err := clientset.DiscoveryV1().EndpointSlices("ns").Create(ctx, slice, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create endpointslice: %v", err)
+	}
+	waitForAddrs(t, cc, "10.0.0.1:9090")
+
+	// Scale up: a second ready endpoint should be added without reconnecting.
+	slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{
+		Addresses:  []string{"10.0.0.2"},
+		Conditions: discoveryv1.EndpointConditions{Ready: &ready},
+	})
+	if _, err := clientset.DiscoveryV1().EndpointSlices("ns").Update(ctx, slice, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update endpointslice: %v", err)
+	}
+	waitForAddrs(t, cc, "10.0.0.1:9090", "10.0.0.2:9090")
+
+	// Scale down via not-ready: the endpoint should drop out immediately,
+	// before the pod is actually removed.
+	slice.Endpoints[1].Conditions.Ready = &notReady
+	if _, err := clientset.DiscoveryV1().EndpointSlices("ns").Update(ctx, slice, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update endpointslice: %v", err)
+	}
+	waitForAddrs(t, cc, "10.0.0.1:9090")
+}
+
+// waitForAddrs polls cc's update channel until its most recent state exactly
+// matches want (as a set), or fails the test after a timeout.
+func waitForAddrs(t *testing.T, cc *fakeClientConn, want ...string) {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		if addrsMatch(cc.last(), want) {
+			return
+		}
+		select {
+		case <-cc.updated:
+		case <-deadline:
+			t.Fatalf("timed out waiting for addresses %v, last state: %+v", want, cc.last())
+		}
+	}
+}
+
+func addrsMatch(state resolver.State, want []string) bool {
+	if len(state.Addresses) != len(want) {
+		return false
+	}
+	got := make(map[string]bool, len(state.Addresses))
+	for _, a := range state.Addresses {
+		got[a.Addr] = true
+	}
+	for _, w := range want {
+		if !got[w] {
+			return false
+		}
+	}
+	return true
+}
+
+// fakeClientConn is a minimal resolver.ClientConn that records every
+// UpdateState call, for asserting how watchService reacts to endpoint churn.
+type fakeClientConn struct {
+	mu      sync.Mutex
+	states  []resolver.State
+	updated chan struct{}
+}
+
+func newFakeClientConn() *fakeClientConn {
+	return &fakeClientConn{updated: make(chan struct{}, 100)}
+}
+
+func (f *fakeClientConn) UpdateState(s resolver.State) error {
+	f.mu.Lock()
+	f.states = append(f.states, s)
+	f.mu.Unlock()
+	select {
+	case f.updated <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (f *fakeClientConn) ReportError(error)             {}
+func (f *fakeClientConn) NewAddress([]resolver.Address) {}
+func (f *fakeClientConn) NewServiceConfig(string)       {}
+func (f *fakeClientConn) ParseServiceConfig(string) *serviceconfig.ParseResult {
+	return nil
+}
+
+func (f *fakeClientConn) last() resolver.State {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.states) == 0 {
+		return resolver.State{}
+	}
+	return f.states[len(f.states)-1]
+}
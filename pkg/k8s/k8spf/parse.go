@@ -12,11 +12,19 @@ import (
 
 // Target holds the parsed components of a k8spf:/// address.
 type Target struct {
-	Name      string // pod or deployment name depending on Workload
+	Name      string // pod, deployment, or Service name depending on Workload
 	Namespace string
 	Port      int
-	Workload  string // "pod" (default) or "deployment"
+	Workload  string // "pod" (default), "deployment", "statefulset", "daemonset", "replicaset", or "service"
+	Mode      string // "" (port-forward, default) or "incluster"
 	Context   string // optional kubectl context override
+	// Selector names the kube.PodSelector strategy used to pick a pod when
+	// Workload resolves to more than one candidate (see kube.NewPodSelector).
+	// "" uses kube's default ranking.
+	Selector string
+	// DeviceID is passed through to the selector as its stickiness key, used
+	// by the "sticky-by-device-id" strategy.
+	DeviceID string
 }
 
 // ParseTarget extracts a Target from a gRPC resolver.Target.
@@ -80,8 +88,15 @@ func ParseAddr(addr string) (Target, error) {
 	if workload == "" {
 		workload = "pod"
 	}
-	if workload != "pod" && workload != "deployment" {
-		return Target{}, fmt.Errorf("k8spf: invalid workload %q, must be \"pod\" or \"deployment\"", workload)
+	switch workload {
+	case "pod", "deployment", "statefulset", "daemonset", "replicaset", "service":
+	default:
+		return Target{}, fmt.Errorf("k8spf: invalid workload %q, must be one of \"pod\", \"deployment\", \"statefulset\", \"daemonset\", \"replicaset\", or \"service\"", workload)
+	}
+
+	mode := query.Get("mode")
+	if mode != "" && mode != "incluster" {
+		return Target{}, fmt.Errorf("k8spf: invalid mode %q, must be \"incluster\"", mode)
 	}
 
 	return Target{
@@ -89,16 +104,44 @@ func ParseAddr(addr string) (Target, error) {
 		Namespace: ns,
 		Port:      port,
 		Workload:  workload,
+		Mode:      mode,
 		Context:   query.Get("context"),
+		Selector:  query.Get("selector"),
+		DeviceID:  query.Get("device_id"),
 	}, nil
 }
 
 // String returns the canonical "name.namespace:port" representation,
-// appending "?workload=deployment" when the target refers to a deployment.
+// appending "?workload=...", "?mode=incluster", "?selector=..." and/or
+// "?device_id=..." when the target carries a non-default value for any of
+// them.
 func (t Target) String() string {
 	s := net.JoinHostPort(t.Name+"."+t.Namespace, strconv.Itoa(t.Port))
-	if t.Workload == "deployment" {
-		s += "?workload=deployment"
+
+	var query url.Values
+	if t.Workload != "" && t.Workload != "pod" {
+		query = url.Values{"workload": []string{t.Workload}}
+	}
+	if t.Mode != "" {
+		if query == nil {
+			query = url.Values{}
+		}
+		query.Set("mode", t.Mode)
+	}
+	if t.Selector != "" {
+		if query == nil {
+			query = url.Values{}
+		}
+		query.Set("selector", t.Selector)
+	}
+	if t.DeviceID != "" {
+		if query == nil {
+			query = url.Values{}
+		}
+		query.Set("device_id", t.DeviceID)
+	}
+	if len(query) > 0 {
+		s += "?" + query.Encode()
 	}
 	return s
 }
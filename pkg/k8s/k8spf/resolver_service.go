@@ -0,0 +1,125 @@
+package k8spf
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// Service watch backoff bounds, used when runServiceInformer returns an error
+// (cc rejected an address update) and the informer is restarted. A broken
+// watch is re-established by the informer's own reflector.
+const (
+	serviceWatchBackoffMin = 500 * time.Millisecond
+	serviceWatchBackoffMax = 30 * time.Second
+)
+
+// watchService keeps cc's address set in sync with the ready endpoints of
+// target's Service by running an EndpointSlice informer, pushing the full
+// set via cc.UpdateState on every change. Unlike pod/deployment targets,
+// which resolve once to a single tunneled address, this surfaces every ready
+// pod so a gRPC load balancer such as round_robin actually spreads RPCs
+// across them, and reflects scale-up/down without the client reconnecting.
+//
+// It restarts the informer with exponential backoff if runServiceInformer
+// returns an error, and runs until ctx is canceled.
+func watchService(ctx context.Context, clientset kubernetes.Interface, target Target, cc resolver.ClientConn) {
+	backoff := serviceWatchBackoffMin
+	for {
+		err := runServiceInformer(ctx, clientset, target, cc)
+		if ctx.Err() != nil {
+			return
+		}
+		cc.ReportError(fmt.Errorf("k8spf: endpointslice watch for %s: %w", target, err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > serviceWatchBackoffMax {
+			backoff = serviceWatchBackoffMax
+		}
+	}
+}
+
+// runServiceInformer runs a single EndpointSlice informer for target's
+// Service until it errors or ctx is canceled. Each add/update/delete pushes
+// the current ready-address set to cc.
+func runServiceInformer(ctx context.Context, clientset kubernetes.Interface, target Target, cc resolver.ClientConn) error {
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0,
+		informers.WithNamespace(target.Namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = discoveryv1.LabelServiceName + "=" + target.Name
+		}),
+	)
+	informer := factory.Discovery().V1().EndpointSlices().Informer()
+
+	errCh := make(chan error, 1)
+	push := func() {
+		addrs := readyAddresses(informer.GetStore().List(), target.Port)
+		if err := cc.UpdateState(resolver.State{Addresses: addrs}); err != nil {
+			select {
+			case errCh <- fmt.Errorf("update resolver state: %w", err):
+			default:
+			}
+		}
+	}
+
+	handle, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(any) { push() },
+		UpdateFunc: func(any, any) { push() },
+		DeleteFunc: func(any) { push() },
+	})
+	if err != nil {
+		return fmt.Errorf("add event handler: %w", err)
+	}
+	defer informer.RemoveEventHandler(handle) //nolint:errcheck
+
+	go informer.Run(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return fmt.Errorf("cache sync canceled")
+	}
+	push()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errCh:
+		return err
+	}
+}
+
+// readyAddresses flattens the ready endpoints across a Service's
+// EndpointSlices into resolver addresses on port. Endpoints explicitly
+// marked not-ready are skipped so a rolling deployment never routes to a pod
+// before its readiness probe passes.
+func readyAddresses(slices []any, port int) []resolver.Address {
+	var addrs []resolver.Address
+	for _, obj := range slices {
+		slice, ok := obj.(*discoveryv1.EndpointSlice)
+		if !ok {
+			continue
+		}
+		for _, ep := range slice.Endpoints {
+			if ep.Conditions.Ready != nil && !*ep.Conditions.Ready {
+				continue
+			}
+			for _, ip := range ep.Addresses {
+				addrs = append(addrs, resolver.Address{Addr: net.JoinHostPort(ip, strconv.Itoa(port))})
+			}
+		}
+	}
+	return addrs
+}
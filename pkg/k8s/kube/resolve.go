@@ -3,7 +3,6 @@ package kube
 import (
 	"context"
 	"fmt"
-	"sort"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -11,35 +10,112 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
-// GetFirstPodForDeployment returns the name of the best candidate pod owned by
-// the given deployment. It fetches the deployment's label selector, lists
-// matching pods, filters out ineligible ones (deleting, terminal phase,
-// unassigned), and ranks the rest using the same criteria as kubectl's
-// ByLogging sort â€” preferring running, ready, long-lived, low-restart pods.
+// defaultSelector is used by GetFirstPodForWorkload and pickBestPod, whose
+// callers don't care about pluggable strategies. It approximates the original
+// hard-coded ranking (see DefaultPodScoreWeights).
+var defaultSelector PodSelector = ScoreBasedSelector{Weights: DefaultPodScoreWeights()}
+
+// workloadSelector fetches the label selector a workload of the given kind
+// uses to own its pods.
+var workloadSelector = map[string]func(ctx context.Context, cs kubernetes.Interface, namespace, name string) (*metav1.LabelSelector, error){
+	"deployment": func(ctx context.Context, cs kubernetes.Interface, namespace, name string) (*metav1.LabelSelector, error) {
+		w, err := cs.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return w.Spec.Selector, nil
+	},
+	"statefulset": func(ctx context.Context, cs kubernetes.Interface, namespace, name string) (*metav1.LabelSelector, error) {
+		w, err := cs.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return w.Spec.Selector, nil
+	},
+	"daemonset": func(ctx context.Context, cs kubernetes.Interface, namespace, name string) (*metav1.LabelSelector, error) {
+		w, err := cs.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return w.Spec.Selector, nil
+	},
+	"replicaset": func(ctx context.Context, cs kubernetes.Interface, namespace, name string) (*metav1.LabelSelector, error) {
+		w, err := cs.AppsV1().ReplicaSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return w.Spec.Selector, nil
+	},
+}
+
+// GetFirstPodForDeployment returns the name of the best candidate pod owned
+// by the given deployment. It's a thin wrapper around GetFirstPodForWorkload
+// kept for callers that only ever dealt with deployments.
 func GetFirstPodForDeployment(ctx context.Context, cs kubernetes.Interface, namespace, deploymentName string) (string, error) {
-	deploy, err := cs.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+	return GetFirstPodForWorkload(ctx, cs, namespace, "deployment", deploymentName)
+}
+
+// GetFirstPodForWorkload returns the name of the best candidate pod owned by
+// the named workload of the given kind ("deployment", "statefulset",
+// "daemonset", or "replicaset"). It fetches the workload's label selector,
+// lists matching pods, filters out ineligible ones (deleting, terminal
+// phase, unassigned), and ranks the rest using the default PodSelector
+// (ScoreBasedSelector with DefaultPodScoreWeights, approximating kubectl's
+// ByLogging ranking). Use GetFirstPodForWorkloadWithSelector for a
+// pluggable strategy.
+func GetFirstPodForWorkload(ctx context.Context, cs kubernetes.Interface, namespace, kind, name string) (string, error) {
+	return GetFirstPodForWorkloadWithSelector(ctx, cs, namespace, kind, name, defaultSelector, "")
+}
+
+// GetFirstPodForWorkloadWithSelector is GetFirstPodForWorkload with a
+// pluggable PodSelector, so callers that let operators choose a strategy
+// (see NewPodSelector) aren't stuck with the default ranking. key is passed
+// through to the selector (e.g. a device ID for StickySelector); selectors
+// that don't use it ignore it.
+func GetFirstPodForWorkloadWithSelector(ctx context.Context, cs kubernetes.Interface, namespace, kind, name string, selector PodSelector, key string) (string, error) {
+	getSelector, ok := workloadSelector[kind]
+	if !ok {
+		return "", fmt.Errorf("kube: unsupported workload kind %q", kind)
+	}
+
+	sel, err := getSelector(ctx, cs, namespace, name)
 	if err != nil {
-		return "", fmt.Errorf("get deployment %s/%s: %w", namespace, deploymentName, err)
+		return "", fmt.Errorf("get %s %s/%s: %w", kind, namespace, name, err)
 	}
 
-	sel := labels.Set(deploy.Spec.Selector.MatchLabels).String()
 	pods, err := cs.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
-		LabelSelector: sel,
+		LabelSelector: labels.Set(sel.MatchLabels).String(),
 	})
 	if err != nil {
-		return "", fmt.Errorf("list pods for deployment %s/%s: %w", namespace, deploymentName, err)
+		return "", fmt.Errorf("list pods for %s %s/%s: %w", kind, namespace, name, err)
 	}
 
-	best := pickBestPod(pods.Items)
+	best := pickBestPodWith(pods.Items, selector, key)
 	if best == nil {
-		return "", fmt.Errorf("no eligible pods for deployment %s/%s (%d total)", namespace, deploymentName, len(pods.Items))
+		return "", fmt.Errorf("no eligible pods for %s %s/%s (%d total)", kind, namespace, name, len(pods.Items))
 	}
 	return best.Name, nil
 }
 
-// pickBestPod filters out ineligible pods and returns the best candidate using
-// kubectl's ByLogging ranking. Returns nil if no eligible pods remain.
+// pickBestPod filters out ineligible pods and returns the best candidate
+// using the default PodSelector. Returns nil if no eligible pods remain.
 func pickBestPod(pods []corev1.Pod) *corev1.Pod {
+	return pickBestPodWith(pods, defaultSelector, "")
+}
+
+// pickBestPodWith filters out ineligible pods and hands the rest to
+// selector. Returns nil if no eligible pods remain.
+func pickBestPodWith(pods []corev1.Pod, selector PodSelector, key string) *corev1.Pod {
+	eligible := eligiblePods(pods)
+	if len(eligible) == 0 {
+		return nil
+	}
+	return selector.Select(eligible, key)
+}
+
+// eligiblePods returns the pods not marked for deletion, in a terminal
+// phase, or unassigned to a node.
+func eligiblePods(pods []corev1.Pod) []*corev1.Pod {
 	var eligible []*corev1.Pod
 	for i := range pods {
 		p := &pods[i]
@@ -57,67 +133,7 @@ func pickBestPod(pods []corev1.Pod) *corev1.Pod {
 		}
 		eligible = append(eligible, p)
 	}
-	if len(eligible) == 0 {
-		return nil
-	}
-
-	// Sort best-first using kubectl's ByLogging criteria.
-	sort.Sort(byHealth(eligible))
-	return eligible[0]
-}
-
-// byHealth sorts pods best-first, mirroring kubectl's ByLogging algorithm.
-// Criteria in priority order:
-//  1. Running > Unknown > Pending
-//  2. Ready > not ready
-//  3. Ready for longer > shorter
-//  4. Fewer container restarts > more
-//  5. Older (earlier creation) > newer
-type byHealth []*corev1.Pod
-
-func (s byHealth) Len() int      { return len(s) }
-func (s byHealth) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
-
-func (s byHealth) Less(i, j int) bool {
-	pi, pj := s[i], s[j]
-
-	// 1. Pod phase: Running < Unknown < Pending (lower = better).
-	phaseRank := map[corev1.PodPhase]int{
-		corev1.PodRunning: 0,
-		corev1.PodUnknown: 1,
-		corev1.PodPending: 2,
-	}
-	ri, rj := phaseRank[pi.Status.Phase], phaseRank[pj.Status.Phase]
-	if ri != rj {
-		return ri < rj
-	}
-
-	// 2. Ready > not ready.
-	readyI, readyJ := isPodReady(pi), isPodReady(pj)
-	if readyI != readyJ {
-		return readyI
-	}
-
-	// 3. Ready for longer is better.
-	if readyI && readyJ {
-		ti, tj := podReadyTime(pi), podReadyTime(pj)
-		if !ti.Equal(tj) {
-			return afterOrZero(tj, ti)
-		}
-	}
-
-	// 4. Fewer total container restarts is better.
-	ri, rj = maxContainerRestarts(pi), maxContainerRestarts(pj)
-	if ri != rj {
-		return ri < rj
-	}
-
-	// 5. Older pod is better.
-	if !pi.CreationTimestamp.Equal(&pj.CreationTimestamp) {
-		return afterOrZero(&pj.CreationTimestamp, &pi.CreationTimestamp)
-	}
-
-	return false
+	return eligible
 }
 
 // isPodReady returns true when the pod has a PodReady condition set to True.
@@ -156,12 +172,3 @@ func maxContainerRestarts(pod *corev1.Pod) int {
 	}
 	return int(m)
 }
-
-// afterOrZero returns true if t1 is after t2, treating a zero time as "after
-// everything" (i.e. unknown/empty sorts last).
-func afterOrZero(t1, t2 *metav1.Time) bool {
-	if t1.IsZero() || t2.IsZero() {
-		return t2.IsZero()
-	}
-	return t1.After(t2.Time)
-}
@@ -1,11 +1,15 @@
 package kube
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func pod(name string, opts ...func(*corev1.Pod)) corev1.Pod {
@@ -168,3 +172,143 @@ func TestPickBestPod(t *testing.T) {
 		})
 	}
 }
+
+func withAnnotation(key, value string) func(*corev1.Pod) {
+	return func(p *corev1.Pod) {
+		if p.Annotations == nil {
+			p.Annotations = map[string]string{}
+		}
+		p.Annotations[key] = value
+	}
+}
+
+func withCPULimit(milli int64) func(*corev1.Pod) {
+	return func(p *corev1.Pod) {
+		p.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Resources: &corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU: *resource.NewMilliQuantity(milli, resource.DecimalSI),
+				},
+			},
+		}}
+	}
+}
+
+func TestPickBestPodLeastLoaded(t *testing.T) {
+	selector := ScoreBasedSelector{Weights: LeastLoadedPodScoreWeights()}
+
+	pods := []corev1.Pod{
+		pod("heavy", withReady(time.Now()), withCPULimit(2000)),
+		pod("light", withReady(time.Now()), withCPULimit(100)),
+	}
+
+	got := pickBestPodWith(pods, selector, "")
+	if got == nil || got.Name != "light" {
+		name := "<nil>"
+		if got != nil {
+			name = got.Name
+		}
+		t.Fatalf("least-loaded pickBestPodWith() = %q, want %q", name, "light")
+	}
+}
+
+func TestRandomSelectorPicksAmongEligible(t *testing.T) {
+	pods := []*corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "only"}}}
+
+	got := RandomSelector{}.Select(pods, "")
+	if got == nil || got.Name != "only" {
+		t.Fatalf("RandomSelector.Select() with one pod = %v, want %q", got, "only")
+	}
+
+	if got := (RandomSelector{}).Select(nil, ""); got != nil {
+		t.Fatalf("RandomSelector.Select(nil) = %v, want nil", got)
+	}
+}
+
+func TestAnnotationSelector(t *testing.T) {
+	pods := []corev1.Pod{
+		pod("low", withAnnotation("weight", "1")),
+		pod("high", withAnnotation("weight", "10")),
+		pod("unparseable", withAnnotation("weight", "not-a-number")),
+	}
+
+	got := pickBestPodWith(pods, AnnotationSelector{Key: "weight"}, "")
+	if got == nil || got.Name != "high" {
+		name := "<nil>"
+		if got != nil {
+			name = got.Name
+		}
+		t.Fatalf("annotation pickBestPodWith() = %q, want %q", name, "high")
+	}
+}
+
+func TestStickySelectorStableAcrossReorderings(t *testing.T) {
+	base := []*corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-b"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-c"}},
+	}
+	reordered := []*corev1.Pod{base[2], base[0], base[1]}
+
+	selector := StickySelector{}
+	const key = "device-abc123"
+
+	want := selector.Select(base, key)
+	got := selector.Select(reordered, key)
+	if want == nil || got == nil || want.Name != got.Name {
+		t.Fatalf("StickySelector.Select() not stable across reorderings: %v vs %v", want, got)
+	}
+}
+
+func TestStickySelectorSameKeyIsDeterministic(t *testing.T) {
+	pods := []*corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-b"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-c"}},
+	}
+
+	selector := StickySelector{}
+	got1 := selector.Select(pods, "device-1")
+	got2 := selector.Select(pods, "device-1")
+	if got1.Name != got2.Name {
+		t.Fatalf("StickySelector.Select() not deterministic for the same key: %q vs %q", got1.Name, got2.Name)
+	}
+}
+
+func TestNewPodSelectorUnknownStrategy(t *testing.T) {
+	if _, err := NewPodSelector("bogus"); err == nil {
+		t.Fatal("NewPodSelector(\"bogus\") succeeded, want error")
+	}
+}
+
+func TestGetFirstPodForWorkloadUnsupportedKind(t *testing.T) {
+	cs := fake.NewSimpleClientset()
+	_, err := GetFirstPodForWorkload(context.Background(), cs, "ns", "cronjob", "name")
+	if err == nil {
+		t.Fatal("GetFirstPodForWorkload() with unsupported kind succeeded, want error")
+	}
+}
+
+func TestGetFirstPodForWorkloadStatefulSet(t *testing.T) {
+	cs := fake.NewSimpleClientset(
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "ns"},
+			Spec: appsv1.StatefulSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "ns", Labels: map[string]string{"app": "db"}},
+			Spec:       corev1.PodSpec{NodeName: "node-1"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+
+	got, err := GetFirstPodForWorkload(context.Background(), cs, "ns", "statefulset", "db")
+	if err != nil {
+		t.Fatalf("GetFirstPodForWorkload() error: %v", err)
+	}
+	if want := "db-0"; got != want {
+		t.Errorf("GetFirstPodForWorkload() = %q, want %q", got, want)
+	}
+}
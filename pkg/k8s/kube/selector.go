@@ -0,0 +1,281 @@
+package kube
+
+import (
+	"crypto/rand"
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// PodSelector picks the best eligible pod for a workload out of pods, which
+// pickBestPod has already filtered down to non-deleting, non-terminal,
+// node-assigned candidates. Returns nil if pods is empty.
+//
+// key is an opaque stickiness key (typically a device ID) that
+// StickySelector uses to keep a given caller landing on the same pod across
+// reconnects; selectors that don't care about affinity ignore it.
+type PodSelector interface {
+	Select(pods []*corev1.Pod, key string) *corev1.Pod
+}
+
+// NewPodSelector builds the PodSelector named by strategy:
+//
+//   - "" or "most-stable": ScoreBasedSelector with DefaultPodScoreWeights,
+//     approximating the original hard-coded phase/ready/age/restart ranking
+//     (see DefaultPodScoreWeights).
+//   - "least-loaded": ScoreBasedSelector weighted toward low restart counts
+//     and low declared resource footprint instead of uptime.
+//   - "random": RandomSelector.
+//   - "sticky-by-device-id": StickySelector, hashed on key so the same device
+//     keeps landing on the same replica across reconnects while the set of
+//     eligible pods stays the same.
+//   - "annotation:<key>": AnnotationSelector, highest parsed value for
+//     annotation <key> wins.
+//
+// Any other strategy string is an error.
+func NewPodSelector(strategy string) (PodSelector, error) {
+	switch {
+	case strategy == "" || strategy == "most-stable":
+		return ScoreBasedSelector{Weights: DefaultPodScoreWeights()}, nil
+	case strategy == "least-loaded":
+		return ScoreBasedSelector{Weights: LeastLoadedPodScoreWeights()}, nil
+	case strategy == "random":
+		return RandomSelector{}, nil
+	case strategy == "sticky-by-device-id":
+		return StickySelector{}, nil
+	case strings.HasPrefix(strategy, "annotation:"):
+		return AnnotationSelector{Key: strings.TrimPrefix(strategy, "annotation:")}, nil
+	default:
+		return nil, fmt.Errorf("kube: unknown pod selection strategy %q", strategy)
+	}
+}
+
+// PodScoreWeights tunes how ScoreBasedSelector scores each eligible pod.
+// Every pod starts at score 0 and each signal below adds (or subtracts) its
+// weighted contribution; the highest-scoring pod wins, with ties broken by
+// input order for determinism.
+type PodScoreWeights struct {
+	// NotRunningPenalty and NotReadyPenalty are large fixed penalties so
+	// phase and readiness still dominate the ranking the way the original
+	// hard-coded ByLogging order did, regardless of the other weights below.
+	NotRunningPenalty float64
+	NotReadyPenalty   float64
+
+	// ReadySeconds is added once per second a ready pod has stayed ready,
+	// capped at ReadySecondsCap.
+	ReadySeconds    float64
+	ReadySecondsCap float64
+
+	// RestartPenalty is subtracted once per container restart (the highest
+	// restart count across the pod's containers).
+	RestartPenalty float64
+
+	// ResourcePressurePenalty is subtracted once per declared milli-core of
+	// CPU limit plus megabyte of memory limit across the pod's containers
+	// (see containerResourceFootprint). This is a static proxy for load —
+	// there's no metrics-server integration here to read live utilization
+	// from — but it's enough to prefer pods with a smaller configured
+	// footprint over a busier-looking one.
+	ResourcePressurePenalty float64
+
+	// PodAgeSeconds is added once per second since pod creation, kept tiny
+	// relative to the other signals so it only breaks otherwise-exact ties
+	// (preferring the older pod), matching the original algorithm's final
+	// tiebreaker. Pods with no creation timestamp contribute 0.
+	PodAgeSeconds float64
+
+	// NodeAffinityBonus is added if the pod's Spec.NodeName equals
+	// PreferredNode. PreferredNode == "" disables this signal.
+	NodeAffinityBonus float64
+	PreferredNode     string
+
+	// LabelAffinityBonus is added once per key/value pair in LabelAffinity
+	// that matches the pod's labels.
+	LabelAffinityBonus float64
+	LabelAffinity      map[string]string
+}
+
+// DefaultPodScoreWeights approximates the original hard-coded ranking as a
+// weighted sum: phase and readiness dominate; time ready (one point per
+// second, capped at an hour) and restarts (100 points each) are traded off
+// against each other rather than compared strictly in that order; pod age
+// only breaks near-ties. Unknown and Pending phases score the same.
+func DefaultPodScoreWeights() PodScoreWeights {
+	return PodScoreWeights{
+		NotRunningPenalty: 1_000_000,
+		NotReadyPenalty:   10_000,
+		ReadySeconds:      1,
+		ReadySecondsCap:   3600,
+		RestartPenalty:    100,
+		PodAgeSeconds:     0.0001,
+	}
+}
+
+// LeastLoadedPodScoreWeights is DefaultPodScoreWeights with uptime dropped
+// and declared resource footprint weighted instead, so a freshly-scaled
+// replica with no backlog can outrank a long-lived but heavier one.
+func LeastLoadedPodScoreWeights() PodScoreWeights {
+	w := DefaultPodScoreWeights()
+	w.ReadySeconds = 0
+	w.ResourcePressurePenalty = 1
+	return w
+}
+
+// score computes pod's weighted score as of now.
+func (w PodScoreWeights) score(pod *corev1.Pod, now time.Time) float64 {
+	var score float64
+
+	if pod.Status.Phase != corev1.PodRunning {
+		score -= w.NotRunningPenalty
+	}
+
+	if isPodReady(pod) {
+		age := now.Sub(podReadyTime(pod).Time).Seconds()
+		if age > w.ReadySecondsCap {
+			age = w.ReadySecondsCap
+		}
+		if age > 0 {
+			score += w.ReadySeconds * age
+		}
+	} else {
+		score -= w.NotReadyPenalty
+	}
+
+	score -= w.RestartPenalty * float64(maxContainerRestarts(pod))
+	score -= w.ResourcePressurePenalty * float64(containerResourceFootprint(pod))
+
+	if !pod.CreationTimestamp.IsZero() {
+		score += w.PodAgeSeconds * now.Sub(pod.CreationTimestamp.Time).Seconds()
+	}
+
+	if w.PreferredNode != "" && pod.Spec.NodeName == w.PreferredNode {
+		score += w.NodeAffinityBonus
+	}
+
+	for k, v := range w.LabelAffinity {
+		if pod.Labels[k] == v {
+			score += w.LabelAffinityBonus
+		}
+	}
+
+	return score
+}
+
+// containerResourceFootprint sums the CPU (milli-cores) and memory (MB)
+// limits declared across pod's containers, from the actual resources the
+// kubelet reported admitting (ContainerStatuses[].Resources) rather than
+// the pod spec's requests, so it reflects what was actually scheduled.
+func containerResourceFootprint(pod *corev1.Pod) int64 {
+	var total int64
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Resources == nil {
+			continue
+		}
+		if cpu := cs.Resources.Limits.Cpu(); cpu != nil {
+			total += cpu.MilliValue()
+		}
+		if mem := cs.Resources.Limits.Memory(); mem != nil {
+			total += mem.Value() / (1024 * 1024)
+		}
+	}
+	return total
+}
+
+// ScoreBasedSelector picks the highest-scoring pod according to Weights.
+type ScoreBasedSelector struct {
+	Weights PodScoreWeights
+}
+
+func (s ScoreBasedSelector) Select(pods []*corev1.Pod, _ string) *corev1.Pod {
+	if len(pods) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	best := pods[0]
+	bestScore := s.Weights.score(best, now)
+	for _, p := range pods[1:] {
+		if sc := s.Weights.score(p, now); sc > bestScore {
+			best, bestScore = p, sc
+		}
+	}
+	return best
+}
+
+// RandomSelector picks a uniformly random eligible pod, e.g. for spreading
+// load across replicas with no other preference.
+type RandomSelector struct{}
+
+func (RandomSelector) Select(pods []*corev1.Pod, _ string) *corev1.Pod {
+	if len(pods) == 0 {
+		return nil
+	}
+	return pods[randomIndex(len(pods))]
+}
+
+func randomIndex(n int) int {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0
+	}
+	return int(i.Int64())
+}
+
+// StickySelector hashes key against the sorted set of eligible pod names, so
+// the same key keeps landing on the same pod across calls regardless of the
+// order pods were listed in. It's a simple modulo hash rather than a full
+// consistent-hash ring, so adding or removing pods can reshuffle most keys'
+// assignments — acceptable here since workload pod counts change rarely
+// compared to how often a given device reconnects.
+type StickySelector struct{}
+
+func (StickySelector) Select(pods []*corev1.Pod, key string) *corev1.Pod {
+	if len(pods) == 0 {
+		return nil
+	}
+	if key == "" {
+		// No stickiness key to hash on: fall back to the deterministic
+		// default ranking instead of an arbitrary pick.
+		return ScoreBasedSelector{Weights: DefaultPodScoreWeights()}.Select(pods, key)
+	}
+
+	sorted := append([]*corev1.Pod(nil), pods...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	idx := h.Sum64() % uint64(len(sorted))
+	return sorted[idx]
+}
+
+// AnnotationSelector picks the pod with the highest parseable
+// float value for annotation Key. Pods missing the annotation, or whose
+// value doesn't parse as a float, are treated as 0.
+type AnnotationSelector struct {
+	Key string
+}
+
+func (s AnnotationSelector) Select(pods []*corev1.Pod, _ string) *corev1.Pod {
+	if len(pods) == 0 {
+		return nil
+	}
+
+	best := pods[0]
+	bestVal, _ := strconv.ParseFloat(best.Annotations[s.Key], 64)
+	for _, p := range pods[1:] {
+		val, err := strconv.ParseFloat(p.Annotations[s.Key], 64)
+		if err != nil {
+			val = 0
+		}
+		if val > bestVal {
+			best, bestVal = p, val
+		}
+	}
+	return best
+}
@@ -3,7 +3,9 @@ package kube
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -35,6 +37,17 @@ const (
 	ReasonStartError         = "StartError"
 )
 
+// Event reasons (set by kube-scheduler/kubelet as string literals) that
+// WaitForPod treats as Warning-level signal worth surfacing, even though
+// none of them are terminal on their own.
+const (
+	EventReasonFailedScheduling       = "FailedScheduling"
+	EventReasonFailedMount            = "FailedMount"
+	EventReasonBackOff                = "BackOff"
+	EventReasonUnhealthy              = "Unhealthy"
+	EventReasonFailedCreatePodSandBox = "FailedCreatePodSandBox"
+)
+
 // reasonHints maps container state reasons to human-readable hints.
 var reasonHints = map[string]string{
 	ReasonCrashLoopBackOff:           "container keeps crashing and restarting — check application logs",
@@ -62,49 +75,291 @@ var terminalReasons = map[string]bool{
 	ReasonRunContainerError:          true,
 }
 
-// WaitForPod polls until a pod matching the label selector in the given
+// defaultFailedSchedulingGracePeriod is how long WaitForPod tolerates
+// repeated FailedScheduling events for the same pod before giving up early,
+// even though FailedScheduling alone isn't a terminalReasons-style failure:
+// the scheduler may still find room once, say, a cluster autoscaler adds a
+// node.
+const defaultFailedSchedulingGracePeriod = 60 * time.Second
+
+// maxTrackedEvents bounds how many of a pod's recent warning events
+// podError reports, so a pod stuck complaining for minutes doesn't produce
+// an unbounded error message.
+const maxTrackedEvents = 5
+
+// gracePeriodCheckInterval is how often the wait loop re-checks
+// FailedSchedulingGracePeriod independently of incoming watch events, since
+// the scheduler doesn't necessarily keep re-emitting FailedScheduling while
+// a pod sits unschedulable.
+const gracePeriodCheckInterval = 5 * time.Second
+
+// WaitOptions configures the optional parts of WaitForPod.
+type WaitOptions struct {
+	// OnEvent, if set, is called for every Kubernetes Event observed for
+	// the pod once it's been discovered — Normal and Warning alike — so
+	// callers like connectAdmin can stream human-readable progress lines
+	// instead of waiting silently.
+	OnEvent func(*corev1.Event)
+
+	// FailedSchedulingGracePeriod bounds how long repeated
+	// FailedScheduling events are tolerated before WaitForPod gives up
+	// early instead of waiting out the full timeout. Defaults to
+	// defaultFailedSchedulingGracePeriod.
+	FailedSchedulingGracePeriod time.Duration
+}
+
+// WaitForPod watches until a pod matching the label selector in the given
 // namespace is running with all containers ready. It returns early with an
 // error if a terminal failure is detected (e.g., CrashLoopBackOff,
-// ImagePullBackOff, CreateContainerConfigError). On timeout it returns the
-// best error it can glean from container statuses.
-func WaitForPod(ctx context.Context, client kubernetes.Interface, ns, labelSelector string, timeout time.Duration) (string, error) {
-	deadline := time.After(timeout)
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
+// ImagePullBackOff, CreateContainerConfigError, or FailedScheduling
+// persisting beyond its grace period). On timeout it returns the best error
+// it can glean from container statuses and recent Warning events.
+func WaitForPod(ctx context.Context, client kubernetes.Interface, ns, labelSelector string, timeout time.Duration, opts ...WaitOptions) (string, error) {
+	var o WaitOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.FailedSchedulingGracePeriod <= 0 {
+		o.FailedSchedulingGracePeriod = defaultFailedSchedulingGracePeriod
+	}
 
-	var lastPodStatus string
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	w := &podWaiter{
+		client:   client,
+		ns:       ns,
+		selector: labelSelector,
+		opts:     o,
+		events:   newEventTracker(),
+	}
+	return w.run(ctx)
+}
+
+// podWaiter holds the state a single WaitForPod call accumulates across
+// re-watches: the events seen so far for the discovered pod, and when
+// FailedScheduling was first observed (for the grace-period timeout). mu
+// guards firstFailedScheduling and lastPod, since watchEvents updates the
+// former from a background goroutine while run reads both from its own.
+type podWaiter struct {
+	client   kubernetes.Interface
+	ns       string
+	selector string
+	opts     WaitOptions
+
+	events *eventTracker
+
+	mu                    sync.Mutex
+	firstFailedScheduling time.Time
+	lastPod               *corev1.Pod
+}
+
+func (w *podWaiter) setLastPod(pod *corev1.Pod) {
+	w.mu.Lock()
+	w.lastPod = pod
+	w.mu.Unlock()
+}
+
+func (w *podWaiter) getLastPod() *corev1.Pod {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastPod
+}
+
+func (w *podWaiter) noteFailedScheduling() {
+	w.mu.Lock()
+	if w.firstFailedScheduling.IsZero() {
+		w.firstFailedScheduling = time.Now()
+	}
+	w.mu.Unlock()
+}
+
+func (w *podWaiter) run(ctx context.Context) (string, error) {
+	pods, err := w.client.CoreV1().Pods(w.ns).List(ctx, metav1.ListOptions{LabelSelector: w.selector})
+	if err != nil {
+		return "", fmt.Errorf("list pods in %s: %w", w.ns, err)
+	}
 
+	var podName string
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if podReady(pod) {
+			return pod.Name, nil
+		}
+		podName = pod.Name
+		w.setLastPod(pod)
+	}
+	if podName != "" {
+		w.watchEvents(ctx, podName)
+	}
+
+	resourceVersion := pods.ResourceVersion
+	graceTicker := time.NewTicker(w.gracePeriodCheckInterval())
+	defer graceTicker.Stop()
+
+watchLoop:
 	for {
-		select {
-		case <-ctx.Done():
-			return "", ctx.Err()
-		case <-deadline:
-			msg := fmt.Sprintf("timed out waiting for pod in %s (selector: %s)", ns, labelSelector)
-			if lastPodStatus != "" {
-				msg += ": " + lastPodStatus
-			}
-			return "", fmt.Errorf("%s", msg)
-		case <-ticker.C:
-			pods, err := client.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{
-				LabelSelector: labelSelector,
-			})
-			if err != nil {
-				continue
-			}
+		watcher, err := w.client.CoreV1().Pods(w.ns).Watch(ctx, metav1.ListOptions{
+			LabelSelector:   w.selector,
+			ResourceVersion: resourceVersion,
+		})
+		if err != nil {
+			return "", fmt.Errorf("watch pods in %s: %w", w.ns, err)
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				watcher.Stop()
+				return "", w.timeoutError(ctx.Err())
+
+			case <-graceTicker.C:
+				if reason := w.gracePeriodExceeded(); reason != "" {
+					watcher.Stop()
+					return "", fmt.Errorf("pod %s in %s has terminal failure: %s", podName, w.ns, reason)
+				}
+
+			case event, ok := <-watcher.ResultChan():
+				if !ok {
+					watcher.Stop()
+					continue watchLoop // the watch closed (e.g. Expired); re-establish it
+				}
 
-			for _, pod := range pods.Items {
-				if podReady(&pod) {
+				if status, ok := event.Object.(*metav1.Status); ok {
+					if status.Reason == metav1.StatusReasonExpired {
+						resourceVersion = "" // bookmark is gone; fall back to a fresh list next time around
+					}
+					continue
+				}
+
+				pod, ok := event.Object.(*corev1.Pod)
+				if !ok {
+					continue
+				}
+				resourceVersion = pod.ResourceVersion
+				w.setLastPod(pod)
+
+				if podName == "" {
+					podName = pod.Name
+					w.watchEvents(ctx, podName)
+				}
+
+				if podReady(pod) {
+					watcher.Stop()
 					return pod.Name, nil
 				}
-				if reason := podTerminalReason(&pod); reason != "" {
-					return "", fmt.Errorf("pod %s in %s has terminal failure: %s", pod.Name, ns, podError(&pod))
+				if reason := podTerminalReason(pod); reason != "" {
+					watcher.Stop()
+					return "", fmt.Errorf("pod %s in %s has terminal failure: %s", pod.Name, w.ns, w.podError(pod))
+				}
+			}
+		}
+	}
+}
+
+// watchEvents starts a background watch over Events involving podName,
+// folding Warning events into w.events (and into the FailedScheduling
+// grace-period clock) and invoking opts.OnEvent for every event observed. It
+// stops on its own once ctx is done.
+func (w *podWaiter) watchEvents(ctx context.Context, podName string) {
+	watcher, err := w.client.CoreV1().Events(w.ns).Watch(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.name=" + podName,
+	})
+	if err != nil {
+		return
+	}
+
+	go func() {
+		defer watcher.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-watcher.ResultChan():
+				if !ok {
+					return
+				}
+				event, ok := e.Object.(*corev1.Event)
+				if !ok {
+					continue
+				}
+
+				if w.opts.OnEvent != nil {
+					w.opts.OnEvent(event)
 				}
-				if errMsg := podError(&pod); errMsg != "" {
-					lastPodStatus = errMsg
+				if event.Type != corev1.EventTypeWarning {
+					continue
+				}
+
+				w.events.add(event)
+				if event.Reason == EventReasonFailedScheduling {
+					w.noteFailedScheduling()
 				}
 			}
 		}
+	}()
+}
+
+// gracePeriodCheckInterval returns how often run polls for
+// FailedSchedulingGracePeriod having elapsed: gracePeriodCheckInterval, or a
+// quarter of the grace period itself if that's shorter (e.g. in tests that
+// use a short grace period to avoid waiting out the default interval).
+func (w *podWaiter) gracePeriodCheckInterval() time.Duration {
+	if quarter := w.opts.FailedSchedulingGracePeriod / 4; quarter < gracePeriodCheckInterval {
+		if quarter <= 0 {
+			return time.Millisecond
+		}
+		return quarter
 	}
+	return gracePeriodCheckInterval
+}
+
+// gracePeriodExceeded returns the error detail for podError once
+// FailedScheduling has persisted beyond opts.FailedSchedulingGracePeriod, or
+// empty string if the grace period hasn't elapsed (or hasn't started).
+func (w *podWaiter) gracePeriodExceeded() string {
+	w.mu.Lock()
+	firstFailedScheduling := w.firstFailedScheduling
+	w.mu.Unlock()
+
+	if firstFailedScheduling.IsZero() {
+		return ""
+	}
+	if time.Since(firstFailedScheduling) < w.opts.FailedSchedulingGracePeriod {
+		return ""
+	}
+	if pod := w.getLastPod(); pod != nil {
+		return w.podError(pod)
+	}
+	return "pod could not be scheduled: " + w.events.summary()
+}
+
+func (w *podWaiter) timeoutError(cause error) error {
+	msg := fmt.Sprintf("timed out waiting for pod in %s (selector: %s)", w.ns, w.selector)
+	var detail string
+	if pod := w.getLastPod(); pod != nil {
+		detail = w.podError(pod)
+	} else {
+		detail = w.events.summary()
+	}
+	if detail != "" {
+		msg += ": " + detail
+	}
+	if cause != nil && cause != context.DeadlineExceeded {
+		return fmt.Errorf("%s: %w", msg, cause)
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// podError extracts the most useful error string for pod, including its
+// phase, container-level failure details with human-readable hints, and the
+// most recent Warning events observed for it.
+func (w *podWaiter) podError(pod *corev1.Pod) string {
+	parts := []string{podError(pod)}
+	if summary := w.events.summary(); summary != "" {
+		parts = append(parts, summary)
+	}
+	return joinNonEmpty(parts, "; ")
 }
 
 // podTerminalReason returns the first terminal failure reason found on a pod,
@@ -193,3 +448,79 @@ func terminatedError(name string, t *corev1.ContainerStateTerminated) string {
 	}
 	return detail
 }
+
+// trackedEvent is the deduplicated, time-ordered record eventTracker keeps
+// for a single (Reason, Message) pair.
+type trackedEvent struct {
+	Reason   string
+	Message  string
+	LastSeen time.Time
+}
+
+// eventTracker deduplicates a pod's recent Warning events by (Reason,
+// Message) and reports the most recent maxTrackedEvents of them, time-ordered
+// newest first. mu guards byKey since add runs on watchEvents' background
+// goroutine while summary is called from run's.
+type eventTracker struct {
+	mu    sync.Mutex
+	byKey map[string]*trackedEvent
+}
+
+func newEventTracker() *eventTracker {
+	return &eventTracker{byKey: make(map[string]*trackedEvent)}
+}
+
+func (t *eventTracker) add(event *corev1.Event) {
+	key := event.Reason + "\x00" + event.Message
+	lastSeen := event.LastTimestamp.Time
+	if lastSeen.IsZero() {
+		lastSeen = time.Now()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if existing, ok := t.byKey[key]; ok {
+		if lastSeen.After(existing.LastSeen) {
+			existing.LastSeen = lastSeen
+		}
+		return
+	}
+	t.byKey[key] = &trackedEvent{Reason: event.Reason, Message: event.Message, LastSeen: lastSeen}
+}
+
+// summary renders up to maxTrackedEvents of the tracked events, most recent
+// first, as "Reason: Message" pairs.
+func (t *eventTracker) summary() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.byKey) == 0 {
+		return ""
+	}
+
+	events := make([]*trackedEvent, 0, len(t.byKey))
+	for _, e := range t.byKey {
+		events = append(events, e)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].LastSeen.After(events[j].LastSeen) })
+	if len(events) > maxTrackedEvents {
+		events = events[:maxTrackedEvents]
+	}
+
+	parts := make([]string, len(events))
+	for i, e := range events {
+		parts[i] = fmt.Sprintf("%s: %s", e.Reason, e.Message)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func joinNonEmpty(parts []string, sep string) string {
+	var nonEmpty []string
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, sep)
+}
@@ -0,0 +1,153 @@
+package kube
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func waitForPodSpec(name string, opts ...func(*corev1.Pod)) *corev1.Pod {
+	p := pod(name, opts...)
+	p.Namespace = "ns"
+	p.Labels = map[string]string{"app": "demo"}
+	return &p
+}
+
+func withContainerReady(ready bool) func(*corev1.Pod) {
+	return func(p *corev1.Pod) {
+		p.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "main", Ready: ready}}
+	}
+}
+
+func withWaitingReason(reason string) func(*corev1.Pod) {
+	return func(p *corev1.Pod) {
+		p.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  "main",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}},
+		}}
+	}
+}
+
+func TestWaitForPodAlreadyReady(t *testing.T) {
+	cs := fake.NewSimpleClientset(waitForPodSpec("demo-0", withContainerReady(true)))
+
+	got, err := WaitForPod(context.Background(), cs, "ns", "app=demo", time.Second)
+	if err != nil {
+		t.Fatalf("WaitForPod() error: %v", err)
+	}
+	if want := "demo-0"; got != want {
+		t.Errorf("WaitForPod() = %q, want %q", got, want)
+	}
+}
+
+func TestWaitForPodBecomesReadyViaWatch(t *testing.T) {
+	cs := fake.NewSimpleClientset(waitForPodSpec("demo-0", withPhase(corev1.PodPending), withContainerReady(false)))
+
+	type result struct {
+		name string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		name, err := WaitForPod(context.Background(), cs, "ns", "app=demo", 5*time.Second)
+		done <- result{name, err}
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	ready := waitForPodSpec("demo-0", withContainerReady(true))
+	if _, err := cs.CoreV1().Pods("ns").Update(context.Background(), ready, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update pod: %v", err)
+	}
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			t.Fatalf("WaitForPod() error: %v", r.err)
+		}
+		if want := "demo-0"; r.name != want {
+			t.Errorf("WaitForPod() = %q, want %q", r.name, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WaitForPod() did not return in time")
+	}
+}
+
+func TestWaitForPodTerminalFailureViaWatch(t *testing.T) {
+	cs := fake.NewSimpleClientset(waitForPodSpec("demo-0", withPhase(corev1.PodPending), withContainerReady(false)))
+
+	type result struct {
+		name string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		name, err := WaitForPod(context.Background(), cs, "ns", "app=demo", 5*time.Second)
+		done <- result{name, err}
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	crashing := waitForPodSpec("demo-0", withWaitingReason(ReasonCrashLoopBackOff))
+	if _, err := cs.CoreV1().Pods("ns").Update(context.Background(), crashing, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update pod: %v", err)
+	}
+
+	select {
+	case r := <-done:
+		if r.err == nil {
+			t.Fatalf("WaitForPod() = %q, nil, want terminal failure error", r.name)
+		}
+		if !strings.Contains(r.err.Error(), ReasonCrashLoopBackOff) {
+			t.Errorf("WaitForPod() error = %q, want it to mention %q", r.err, ReasonCrashLoopBackOff)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WaitForPod() did not return in time")
+	}
+}
+
+func TestWaitForPodFailedSchedulingGracePeriod(t *testing.T) {
+	cs := fake.NewSimpleClientset(waitForPodSpec("demo-0", withPhase(corev1.PodPending), withContainerReady(false)))
+
+	type result struct {
+		name string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		name, err := WaitForPod(context.Background(), cs, "ns", "app=demo", 5*time.Second, WaitOptions{
+			FailedSchedulingGracePeriod: 100 * time.Millisecond,
+		})
+		done <- result{name, err}
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo-0.failedscheduling", Namespace: "ns"},
+		InvolvedObject: corev1.ObjectReference{
+			Kind: "Pod", Name: "demo-0", Namespace: "ns",
+		},
+		Type:          corev1.EventTypeWarning,
+		Reason:        EventReasonFailedScheduling,
+		Message:       "0/3 nodes are available: insufficient cpu",
+		LastTimestamp: metav1.Now(),
+	}
+	if _, err := cs.CoreV1().Events("ns").Create(context.Background(), event, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create event: %v", err)
+	}
+
+	select {
+	case r := <-done:
+		if r.err == nil {
+			t.Fatalf("WaitForPod() = %q, nil, want grace-period error", r.name)
+		}
+		if !strings.Contains(r.err.Error(), EventReasonFailedScheduling) {
+			t.Errorf("WaitForPod() error = %q, want it to mention %q", r.err, EventReasonFailedScheduling)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WaitForPod() did not return in time")
+	}
+}
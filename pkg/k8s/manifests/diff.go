@@ -0,0 +1,257 @@
+package manifests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+// Diff reads a multi-document YAML file, performs placeholder substitutions,
+// and renders a unified diff between each document's live object and what
+// Apply would converge it to, without changing anything on the cluster; see
+// mergeForDiff for how the prospective "applied" side is computed.
+func Diff(ctx context.Context, cfg *rest.Config, path string, substitutions map[string]string) (string, error) {
+	docs, err := ReadDocuments(path, substitutions)
+	if err != nil {
+		return "", err
+	}
+
+	dc, mapper, err := clients(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	for i, doc := range docs {
+		desired, gvk, err := decodeDocument(doc)
+		if err != nil {
+			return "", fmt.Errorf("document %d in %s: %w", i, path, err)
+		}
+
+		resource, err := resourceFor(dc, mapper, *gvk, desired.GetNamespace())
+		if err != nil {
+			return "", fmt.Errorf("document %d in %s: %w", i, path, err)
+		}
+
+		live, err := resource.Get(ctx, desired.GetName(), metav1.GetOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return "", fmt.Errorf("get %s %s: %w", gvk.Kind, desired.GetName(), err)
+		}
+		if errors.IsNotFound(err) {
+			live = nil
+		}
+
+		merged, err := mergeForDiff(*gvk, live, desired)
+		if err != nil {
+			return "", fmt.Errorf("compute diff for %s %s: %w", gvk.Kind, desired.GetName(), err)
+		}
+
+		liveText, err := renderObject(live)
+		if err != nil {
+			return "", fmt.Errorf("render live %s %s: %w", gvk.Kind, desired.GetName(), err)
+		}
+		mergedText, err := renderObject(merged)
+		if err != nil {
+			return "", fmt.Errorf("render desired %s %s: %w", gvk.Kind, desired.GetName(), err)
+		}
+
+		label := fmt.Sprintf("%s %s/%s", gvk.Kind, desired.GetNamespace(), desired.GetName())
+		out.WriteString(unifiedDiff(liveText, mergedText, label+" (live)", label+" (applied)"))
+	}
+
+	return out.String(), nil
+}
+
+// mergeForDiff computes what a Server-Side Apply of desired would produce
+// given live, the client-side approximation Diff renders against since it
+// never touches the cluster. A nil live means the object doesn't exist yet,
+// so the merge result is simply desired. Known built-in types (anything
+// registered in client-go's scheme) are merged with strategic-merge-patch
+// semantics; CRDs fall back to a plain JSON merge patch since there's no Go
+// struct to drive a strategic merge.
+func mergeForDiff(gvk schema.GroupVersionKind, live, desired *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if live == nil {
+		return desired, nil
+	}
+
+	liveJSON, err := json.Marshal(live.Object)
+	if err != nil {
+		return nil, fmt.Errorf("marshal live object: %w", err)
+	}
+	desiredJSON, err := json.Marshal(desired.Object)
+	if err != nil {
+		return nil, fmt.Errorf("marshal desired object: %w", err)
+	}
+
+	var mergedJSON []byte
+	if t, err := scheme.Scheme.New(gvk); err == nil {
+		mergedJSON, err = strategicpatch.StrategicMergePatch(liveJSON, desiredJSON, t)
+		if err != nil {
+			return nil, fmt.Errorf("strategic merge patch: %w", err)
+		}
+	} else {
+		var originalMap, patchMap map[string]interface{}
+		if err := json.Unmarshal(liveJSON, &originalMap); err != nil {
+			return nil, fmt.Errorf("unmarshal live object: %w", err)
+		}
+		if err := json.Unmarshal(desiredJSON, &patchMap); err != nil {
+			return nil, fmt.Errorf("unmarshal desired object: %w", err)
+		}
+		mergedJSON, err = json.Marshal(jsonMergePatch(originalMap, patchMap))
+		if err != nil {
+			return nil, fmt.Errorf("marshal merged object: %w", err)
+		}
+	}
+
+	merged := &unstructured.Unstructured{}
+	if err := json.Unmarshal(mergedJSON, &merged.Object); err != nil {
+		return nil, fmt.Errorf("unmarshal merged object: %w", err)
+	}
+	return merged, nil
+}
+
+func renderObject(obj *unstructured.Unstructured) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+	data, err := json.MarshalIndent(obj.Object, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// unifiedDiff renders a minimal unified diff between a and b, computed with
+// a straightforward longest-common-subsequence line diff; manifests are
+// small enough that this never needs to be fast.
+func unifiedDiff(a, b, aLabel, bLabel string) string {
+	if a == b {
+		return ""
+	}
+
+	aLines := splitLines(a)
+	bLines := splitLines(b)
+	ops := lcsDiff(aLines, bLines)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", aLabel, bLabel)
+	for _, op := range ops {
+		switch op.kind {
+		case diffEqual:
+			out.WriteString("  " + op.line + "\n")
+		case diffRemove:
+			out.WriteString("- " + op.line + "\n")
+		case diffAdd:
+			out.WriteString("+ " + op.line + "\n")
+		}
+	}
+	return out.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+type diffOpKind int
+
+const (
+	diffEqual diffOpKind = iota
+	diffRemove
+	diffAdd
+)
+
+type diffOp struct {
+	kind diffOpKind
+	line string
+}
+
+// lcsDiff returns a's and b's differences as a sequence of equal/remove/add
+// operations, derived from their longest common subsequence.
+func lcsDiff(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	lengths := make([][]int, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{diffEqual, a[i]})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			ops = append(ops, diffOp{diffRemove, a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{diffAdd, b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{diffRemove, a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{diffAdd, b[j]})
+	}
+	return ops
+}
+
+// jsonMergePatch applies an RFC 7386 JSON merge patch of patch onto
+// original, the fallback mergeForDiff uses for types with no registered Go
+// struct to drive a strategic merge.
+func jsonMergePatch(original, patch map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(original))
+	for k, v := range original {
+		out[k] = v
+	}
+
+	keys := make([]string, 0, len(patch))
+	for k := range patch {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := patch[k]
+		if v == nil {
+			delete(out, k)
+			continue
+		}
+
+		patchMap, patchIsMap := v.(map[string]interface{})
+		originalMap, originalIsMap := out[k].(map[string]interface{})
+		if patchIsMap && originalIsMap {
+			out[k] = jsonMergePatch(originalMap, patchMap)
+		} else {
+			out[k] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,81 @@
+package manifests
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestMergeForDiffNilLiveReturnsDesired(t *testing.T) {
+	desired := configMap("ns", "demo", map[string]string{"k": "v"})
+
+	merged, err := mergeForDiff(configMapGVK, nil, desired)
+	if err != nil {
+		t.Fatalf("mergeForDiff() error: %v", err)
+	}
+	if merged != desired {
+		t.Errorf("mergeForDiff() with nil live = %v, want the desired object unchanged", merged)
+	}
+}
+
+func TestMergeForDiffStrategicMergeForBuiltinType(t *testing.T) {
+	// ConfigMap is registered in client-go's scheme, so this exercises the
+	// strategic-merge-patch branch of mergeForDiff.
+	live := configMap("ns", "demo", map[string]string{"k": "old", "untouched": "keep"})
+	desired := configMap("ns", "demo", map[string]string{"k": "new"})
+
+	merged, err := mergeForDiff(configMapGVK, live, desired)
+	if err != nil {
+		t.Fatalf("mergeForDiff() error: %v", err)
+	}
+
+	data, _, err := unstructured.NestedStringMap(merged.Object, "data")
+	if err != nil {
+		t.Fatalf("read merged data: %v", err)
+	}
+	if data["k"] != "new" {
+		t.Errorf("merged data[k] = %q, want %q", data["k"], "new")
+	}
+	if data["untouched"] != "keep" {
+		t.Errorf("merged data[untouched] = %q, want it preserved from live", data["untouched"])
+	}
+}
+
+func TestMergeForDiffJSONMergePatchFallbackForCRD(t *testing.T) {
+	crdGVK := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+
+	live := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": "demo"},
+		"spec": map[string]interface{}{
+			"size":  "small",
+			"color": "red",
+		},
+	}}
+	desired := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": "demo"},
+		"spec": map[string]interface{}{
+			"size": "large",
+		},
+	}}
+
+	merged, err := mergeForDiff(crdGVK, live, desired)
+	if err != nil {
+		t.Fatalf("mergeForDiff() error: %v", err)
+	}
+
+	spec, _, err := unstructured.NestedStringMap(merged.Object, "spec")
+	if err != nil {
+		t.Fatalf("read merged spec: %v", err)
+	}
+	if spec["size"] != "large" {
+		t.Errorf("merged spec[size] = %q, want %q", spec["size"], "large")
+	}
+	if spec["color"] != "red" {
+		t.Errorf("merged spec[color] = %q, want it preserved from live via the JSON merge patch fallback", spec["color"])
+	}
+}
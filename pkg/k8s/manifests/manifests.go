@@ -5,6 +5,7 @@ package manifests
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -13,20 +14,158 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
 )
 
-// Apply reads a multi-document YAML file, performs placeholder substitutions,
-// and applies each resource via the dynamic k8s API. Creates are idempotent â€”
-// existing resources are silently skipped.
+// defaultFieldManager is the field manager Apply uses when ApplyOptions
+// doesn't specify one.
+const defaultFieldManager = "bridge"
+
+// fieldManagerLabel is stamped onto every object Apply applies, with the
+// field manager name as its value, so Prune can later find every object a
+// given field manager is responsible for.
+const fieldManagerLabel = "app.kubernetes.io/managed-by"
+
+// Action describes what Apply did with a single document.
+type Action string
+
+const (
+	ActionCreated   Action = "created"
+	ActionUpdated   Action = "updated"
+	ActionUnchanged Action = "unchanged"
+	ActionPruned    Action = "pruned"
+)
+
+// Result is the outcome of applying or pruning a single object.
+type Result struct {
+	GVK             schema.GroupVersionKind
+	Namespace       string
+	Name            string
+	Action          Action
+	ResourceVersion string
+}
+
+// ApplyOptions configures how Apply converges a manifest's documents onto
+// the cluster.
+type ApplyOptions struct {
+	// FieldManager identifies this Apply's field ownership in each
+	// object's managedFields. Defaults to "bridge".
+	FieldManager string
+
+	// Force takes ownership of fields currently owned by a different
+	// field manager instead of failing with a conflict, mirroring
+	// `kubectl apply --force-conflicts`.
+	Force bool
+
+	// DryRun runs every patch and delete server-side without persisting
+	// it, so callers can preview what Apply would change.
+	DryRun bool
+
+	// Prune, if non-empty, deletes objects of these GroupVersionKinds
+	// that carry FieldManager's managed-by label but were not part of
+	// this Apply, mirroring `kubectl apply --prune`. PruneSelector must
+	// be set whenever Prune is non-empty.
+	Prune []schema.GroupVersionKind
+
+	// PruneSelector restricts which objects of a Prune GVK are
+	// considered for pruning.
+	PruneSelector string
+}
+
+// DefaultApplyOptions returns the ApplyOptions Apply uses when called
+// without an explicit set of options.
+func DefaultApplyOptions() ApplyOptions {
+	return ApplyOptions{FieldManager: defaultFieldManager}
+}
+
+// Apply reads a multi-document YAML file, performs placeholder
+// substitutions, and server-side applies each document via the dynamic k8s
+// API using DefaultApplyOptions. Subsequent invocations converge existing
+// objects toward the manifest instead of merely skipping ones that already
+// exist; see ApplyWithOptions for field-manager and pruning control.
 func Apply(ctx context.Context, cfg *rest.Config, path string, substitutions map[string]string) error {
+	_, err := ApplyWithOptions(ctx, cfg, path, substitutions, DefaultApplyOptions())
+	return err
+}
+
+// ApplyWithOptions reads a multi-document YAML file, performs placeholder
+// substitutions, and server-side applies each document via the dynamic k8s
+// API, returning a Result per document (plus one per pruned object, if
+// opts.Prune is set).
+func ApplyWithOptions(ctx context.Context, cfg *rest.Config, path string, substitutions map[string]string, opts ApplyOptions) ([]Result, error) {
+	if opts.FieldManager == "" {
+		opts.FieldManager = defaultFieldManager
+	}
+	if len(opts.Prune) > 0 && opts.PruneSelector == "" {
+		return nil, fmt.Errorf("apply %s: PruneSelector is required when Prune is set", path)
+	}
+
+	docs, err := ReadDocuments(path, substitutions)
+	if err != nil {
+		return nil, err
+	}
+
+	dc, mapper, err := clients(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[schema.GroupVersionKind]map[string]bool)
+	namespaces := make(map[string]bool)
+	var results []Result
+
+	for i, doc := range docs {
+		obj, gvk, err := decodeDocument(doc)
+		if err != nil {
+			return results, fmt.Errorf("document %d in %s: %w", i, path, err)
+		}
+
+		resource, err := resourceFor(dc, mapper, *gvk, obj.GetNamespace())
+		if err != nil {
+			return results, fmt.Errorf("document %d in %s: %w", i, path, err)
+		}
+
+		result, err := applyDocument(ctx, resource, *gvk, obj, opts)
+		if err != nil {
+			return results, fmt.Errorf("document %d in %s: %w", i, path, err)
+		}
+		results = append(results, result)
+
+		if applied[*gvk] == nil {
+			applied[*gvk] = make(map[string]bool)
+		}
+		applied[*gvk][objKey(result.Namespace, result.Name)] = true
+		if result.Namespace != "" {
+			namespaces[result.Namespace] = true
+		}
+	}
+
+	if len(opts.Prune) > 0 {
+		pruned, err := prune(ctx, dc, mapper, opts, applied, namespaces)
+		if err != nil {
+			return results, fmt.Errorf("prune %s: %w", path, err)
+		}
+		results = append(results, pruned...)
+	}
+
+	return results, nil
+}
+
+// ReadDocuments loads path, applies substitutions, and splits it into its
+// constituent YAML documents, skipping any that are blank. It's exported so
+// other packages translating these documents into non-dynamic-client
+// representations (e.g. pkg/admin/manifest) don't need to reimplement
+// placeholder substitution and document splitting.
+func ReadDocuments(path string, substitutions map[string]string) ([][]byte, error) {
 	raw, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("read manifest %s: %w", path, err)
+		return nil, fmt.Errorf("read manifest %s: %w", path, err)
 	}
 
 	content := string(raw)
@@ -34,60 +173,188 @@ func Apply(ctx context.Context, cfg *rest.Config, path string, substitutions map
 		content = strings.ReplaceAll(content, placeholder, value)
 	}
 
+	var docs [][]byte
+	for _, doc := range bytes.Split([]byte(content), []byte("\n---")) {
+		doc = bytes.TrimSpace(doc)
+		if len(doc) > 0 {
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
+// clients builds the dynamic client and REST mapper Apply and Diff both need
+// to resolve a document's GroupVersionKind to a namespaced or cluster-scoped
+// resource.
+func clients(cfg *rest.Config) (dynamic.Interface, meta.RESTMapper, error) {
 	dc, err := dynamic.NewForConfig(cfg)
 	if err != nil {
-		return fmt.Errorf("create dynamic client: %w", err)
+		return nil, nil, fmt.Errorf("create dynamic client: %w", err)
 	}
 
 	disco, err := discovery.NewDiscoveryClientForConfig(cfg)
 	if err != nil {
-		return fmt.Errorf("create discovery client: %w", err)
+		return nil, nil, fmt.Errorf("create discovery client: %w", err)
 	}
 
 	groupResources, err := restmapper.GetAPIGroupResources(disco)
 	if err != nil {
-		return fmt.Errorf("discover API groups: %w", err)
-	}
-	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
-
-	docs := bytes.Split([]byte(content), []byte("\n---"))
-	for i, doc := range docs {
-		doc = bytes.TrimSpace(doc)
-		if len(doc) == 0 {
-			continue
-		}
-		if err := applyDocument(ctx, dc, mapper, doc); err != nil {
-			return fmt.Errorf("document %d in %s: %w", i, path, err)
-		}
+		return nil, nil, fmt.Errorf("discover API groups: %w", err)
 	}
 
-	return nil
+	return dc, restmapper.NewDiscoveryRESTMapper(groupResources), nil
 }
 
 var yamlDecoder = yaml.NewDecodingSerializer(unstructured.UnstructuredJSONScheme)
 
-func applyDocument(ctx context.Context, dc dynamic.Interface, mapper meta.RESTMapper, doc []byte) error {
+// decodeDocument parses a single YAML document into an unstructured object.
+func decodeDocument(doc []byte) (*unstructured.Unstructured, *schema.GroupVersionKind, error) {
 	obj := &unstructured.Unstructured{}
 	_, gvk, err := yamlDecoder.Decode(doc, nil, obj)
 	if err != nil {
-		return fmt.Errorf("decode document: %w", err)
+		return nil, nil, fmt.Errorf("decode document: %w", err)
 	}
+	return obj, gvk, nil
+}
 
+// resourceFor resolves gvk to its dynamic resource interface, scoped to ns
+// if the resource is namespaced.
+func resourceFor(dc dynamic.Interface, mapper meta.RESTMapper, gvk schema.GroupVersionKind, ns string) (dynamic.ResourceInterface, error) {
 	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
 	if err != nil {
-		return fmt.Errorf("map %s to resource: %w", gvk, err)
+		return nil, fmt.Errorf("map %s to resource: %w", gvk, err)
 	}
 
-	var resource dynamic.ResourceInterface
 	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
-		resource = dc.Resource(mapping.Resource).Namespace(obj.GetNamespace())
-	} else {
-		resource = dc.Resource(mapping.Resource)
+		return dc.Resource(mapping.Resource).Namespace(ns), nil
 	}
+	return dc.Resource(mapping.Resource), nil
+}
 
-	if _, err := resource.Create(ctx, obj, metav1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
-		return fmt.Errorf("create %s %s: %w", gvk.Kind, obj.GetName(), err)
+// applyDocument server-side applies obj as a field-manager-owned patch,
+// taking ownership of the fields the manifest sets while leaving fields
+// other field managers own untouched (or reassigning them to this field
+// manager, if opts.Force is set and they conflict).
+func applyDocument(ctx context.Context, resource dynamic.ResourceInterface, gvk schema.GroupVersionKind, obj *unstructured.Unstructured, opts ApplyOptions) (Result, error) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string, 1)
 	}
+	labels[fieldManagerLabel] = opts.FieldManager
+	obj.SetLabels(labels)
+
+	data, err := json.Marshal(obj.Object)
+	if err != nil {
+		return Result{}, fmt.Errorf("marshal %s %s: %w", gvk.Kind, obj.GetName(), err)
+	}
+
+	force := opts.Force
+	patchOpts := metav1.PatchOptions{
+		FieldManager: opts.FieldManager,
+		Force:        &force,
+	}
+	if opts.DryRun {
+		patchOpts.DryRun = []string{metav1.DryRunAll}
+	}
+
+	existing, getErr := resource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if getErr != nil && !errors.IsNotFound(getErr) {
+		return Result{}, fmt.Errorf("get %s %s: %w", gvk.Kind, obj.GetName(), getErr)
+	}
+
+	applied, err := resource.Patch(ctx, obj.GetName(), types.ApplyPatchType, data, patchOpts)
+	if err != nil {
+		return Result{}, fmt.Errorf("apply %s %s: %w", gvk.Kind, obj.GetName(), err)
+	}
+
+	action := ActionUpdated
+	switch {
+	case errors.IsNotFound(getErr):
+		action = ActionCreated
+	case existing.GetResourceVersion() == applied.GetResourceVersion():
+		action = ActionUnchanged
+	}
+
+	return Result{
+		GVK:             gvk,
+		Namespace:       applied.GetNamespace(),
+		Name:            applied.GetName(),
+		Action:          action,
+		ResourceVersion: applied.GetResourceVersion(),
+	}, nil
+}
+
+// prune deletes every object of a Prune GVK that carries opts.FieldManager's
+// managed-by label, matches PruneSelector, and wasn't part of applied, the set-difference `kubectl apply --prune` itself
+// computes between a label selector's matches and the just-applied set.
+// Namespaced GVKs are only listed within namespaces, the namespaces the
+// apply actually touched — never cluster-wide — so Prune can't reach
+// objects in unrelated namespaces just because they share a label value
+// with this field manager's.
+func prune(ctx context.Context, dc dynamic.Interface, mapper meta.RESTMapper, opts ApplyOptions, applied map[schema.GroupVersionKind]map[string]bool, namespaces map[string]bool) ([]Result, error) {
+	var results []Result
+
+	selector := fieldManagerLabel + "=" + opts.FieldManager
+	if opts.PruneSelector != "" {
+		selector += "," + opts.PruneSelector
+	}
+
+	for _, gvk := range opts.Prune {
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return results, fmt.Errorf("map %s to resource: %w", gvk, err)
+		}
+
+		namespaced := mapping.Scope.Name() == meta.RESTScopeNameNamespace
+
+		var listNamespaces []string
+		if namespaced {
+			for ns := range namespaces {
+				listNamespaces = append(listNamespaces, ns)
+			}
+		} else {
+			// Cluster-scoped GVKs have no namespace to restrict; list once.
+			listNamespaces = []string{""}
+		}
+
+		deleteOpts := metav1.DeleteOptions{}
+		if opts.DryRun {
+			deleteOpts.DryRun = []string{metav1.DryRunAll}
+		}
+
+		for _, ns := range listNamespaces {
+			var resource dynamic.ResourceInterface = dc.Resource(mapping.Resource)
+			if namespaced {
+				resource = dc.Resource(mapping.Resource).Namespace(ns)
+			}
+
+			list, err := resource.List(ctx, metav1.ListOptions{LabelSelector: selector})
+			if err != nil {
+				return results, fmt.Errorf("list %s for prune: %w", gvk, err)
+			}
+
+			for _, obj := range list.Items {
+				if applied[gvk][objKey(obj.GetNamespace(), obj.GetName())] {
+					continue
+				}
+
+				if err := resource.Delete(ctx, obj.GetName(), deleteOpts); err != nil && !errors.IsNotFound(err) {
+					return results, fmt.Errorf("prune %s %s: %w", gvk.Kind, obj.GetName(), err)
+				}
+
+				results = append(results, Result{
+					GVK:       gvk,
+					Namespace: obj.GetNamespace(),
+					Name:      obj.GetName(),
+					Action:    ActionPruned,
+				})
+			}
+		}
+	}
+
+	return results, nil
+}
 
-	return nil
+func objKey(namespace, name string) string {
+	return namespace + "/" + name
 }
@@ -0,0 +1,183 @@
+package manifests
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var configMapGVK = schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"}
+var configMapGVR = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}
+
+func newTestDynamicClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	scheme := runtime.NewScheme()
+	listKinds := map[schema.GroupVersionResource]string{
+		configMapGVR: "ConfigMapList",
+	}
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, listKinds, objects...)
+}
+
+func newTestRESTMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{{Group: "", Version: "v1"}})
+	mapper.Add(configMapGVK, meta.RESTScopeNamespace)
+	return mapper
+}
+
+func configMap(ns, name string, data map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(configMapGVK)
+	obj.SetNamespace(ns)
+	obj.SetName(name)
+	if len(data) > 0 {
+		untyped := make(map[string]interface{}, len(data))
+		for k, v := range data {
+			untyped[k] = v
+		}
+		obj.Object["data"] = untyped
+	}
+	return obj
+}
+
+func TestApplyDocumentCreatesObject(t *testing.T) {
+	dc := newTestDynamicClient()
+	resource := dc.Resource(configMapGVR).Namespace("ns")
+	opts := DefaultApplyOptions()
+
+	result, err := applyDocument(context.Background(), resource, configMapGVK, configMap("ns", "demo", map[string]string{"k": "v"}), opts)
+	if err != nil {
+		t.Fatalf("applyDocument() error: %v", err)
+	}
+	if result.Action != ActionCreated {
+		t.Errorf("applyDocument() Action = %q, want %q", result.Action, ActionCreated)
+	}
+
+	got, err := resource.Get(context.Background(), "demo", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get applied object: %v", err)
+	}
+	if got.GetLabels()[fieldManagerLabel] != opts.FieldManager {
+		t.Errorf("applied object label %q = %q, want %q", fieldManagerLabel, got.GetLabels()[fieldManagerLabel], opts.FieldManager)
+	}
+}
+
+func TestApplyDocumentUnchangedOnRepeatedApply(t *testing.T) {
+	dc := newTestDynamicClient()
+	resource := dc.Resource(configMapGVR).Namespace("ns")
+	opts := DefaultApplyOptions()
+
+	if _, err := applyDocument(context.Background(), resource, configMapGVK, configMap("ns", "demo", map[string]string{"k": "v"}), opts); err != nil {
+		t.Fatalf("first applyDocument() error: %v", err)
+	}
+
+	result, err := applyDocument(context.Background(), resource, configMapGVK, configMap("ns", "demo", map[string]string{"k": "v"}), opts)
+	if err != nil {
+		t.Fatalf("second applyDocument() error: %v", err)
+	}
+	if result.Action != ActionUnchanged {
+		t.Errorf("applyDocument() Action = %q, want %q", result.Action, ActionUnchanged)
+	}
+}
+
+func TestApplyDocumentUpdatesOnChange(t *testing.T) {
+	dc := newTestDynamicClient()
+	resource := dc.Resource(configMapGVR).Namespace("ns")
+	opts := DefaultApplyOptions()
+
+	if _, err := applyDocument(context.Background(), resource, configMapGVK, configMap("ns", "demo", map[string]string{"k": "v"}), opts); err != nil {
+		t.Fatalf("first applyDocument() error: %v", err)
+	}
+
+	result, err := applyDocument(context.Background(), resource, configMapGVK, configMap("ns", "demo", map[string]string{"k": "v2"}), opts)
+	if err != nil {
+		t.Fatalf("second applyDocument() error: %v", err)
+	}
+	if result.Action != ActionUpdated {
+		t.Errorf("applyDocument() Action = %q, want %q", result.Action, ActionUpdated)
+	}
+}
+
+func TestPruneDeletesUnappliedObjectsInTouchedNamespace(t *testing.T) {
+	stale := configMap("ns", "stale", nil)
+	stale.SetLabels(map[string]string{fieldManagerLabel: defaultFieldManager})
+	dc := newTestDynamicClient(stale)
+	mapper := newTestRESTMapper()
+	opts := ApplyOptions{
+		FieldManager:  defaultFieldManager,
+		Prune:         []schema.GroupVersionKind{configMapGVK},
+		PruneSelector: fieldManagerLabel + "=" + defaultFieldManager,
+	}
+	applied := map[schema.GroupVersionKind]map[string]bool{
+		configMapGVK: {objKey("ns", "demo"): true},
+	}
+	namespaces := map[string]bool{"ns": true}
+
+	results, err := prune(context.Background(), dc, mapper, opts, applied, namespaces)
+	if err != nil {
+		t.Fatalf("prune() error: %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "stale" || results[0].Action != ActionPruned {
+		t.Fatalf("prune() results = %+v, want single pruned result for %q", results, "stale")
+	}
+
+	if _, err := dc.Resource(configMapGVR).Namespace("ns").Get(context.Background(), "stale", metav1.GetOptions{}); err == nil {
+		t.Error("prune() left stale object in place, want it deleted")
+	}
+}
+
+func TestPruneDoesNotTouchUntouchedNamespaces(t *testing.T) {
+	other := configMap("other-ns", "untouched", nil)
+	other.SetLabels(map[string]string{fieldManagerLabel: defaultFieldManager})
+	dc := newTestDynamicClient(other)
+	mapper := newTestRESTMapper()
+	opts := ApplyOptions{
+		FieldManager:  defaultFieldManager,
+		Prune:         []schema.GroupVersionKind{configMapGVK},
+		PruneSelector: fieldManagerLabel + "=" + defaultFieldManager,
+	}
+	applied := map[schema.GroupVersionKind]map[string]bool{}
+	// Only "ns" was touched by this apply; "other-ns" must be left alone even
+	// though its object carries the same managed-by label value.
+	namespaces := map[string]bool{"ns": true}
+
+	results, err := prune(context.Background(), dc, mapper, opts, applied, namespaces)
+	if err != nil {
+		t.Fatalf("prune() error: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("prune() results = %+v, want none since other-ns wasn't touched by this apply", results)
+	}
+
+	if _, err := dc.Resource(configMapGVR).Namespace("other-ns").Get(context.Background(), "untouched", metav1.GetOptions{}); err != nil {
+		t.Errorf("prune() deleted object in untouched namespace: %v", err)
+	}
+}
+
+func TestPruneSkipsAppliedObjects(t *testing.T) {
+	kept := configMap("ns", "demo", nil)
+	kept.SetLabels(map[string]string{fieldManagerLabel: defaultFieldManager})
+	dc := newTestDynamicClient(kept)
+	mapper := newTestRESTMapper()
+	opts := ApplyOptions{
+		FieldManager:  defaultFieldManager,
+		Prune:         []schema.GroupVersionKind{configMapGVK},
+		PruneSelector: fieldManagerLabel + "=" + defaultFieldManager,
+	}
+	applied := map[schema.GroupVersionKind]map[string]bool{
+		configMapGVK: {objKey("ns", "demo"): true},
+	}
+	namespaces := map[string]bool{"ns": true}
+
+	results, err := prune(context.Background(), dc, mapper, opts, applied, namespaces)
+	if err != nil {
+		t.Fatalf("prune() error: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("prune() results = %+v, want none since demo was part of this apply", results)
+	}
+}
@@ -19,6 +19,12 @@ const (
 	// LabelBridgeDeployment identifies the specific bridge deployment a pod belongs to.
 	LabelBridgeDeployment = "vercel.sh/bridge-deployment"
 
+	// AnnotationHealthProbes stores a bridge deployment's configured health
+	// probes (admin.ProbeSpec, JSON-encoded) for the health reconciler to
+	// pick up on its next tick. Absent on bridges that haven't had probes
+	// configured, which fall back to the reconciler's built-in default check.
+	AnnotationHealthProbes = "vercel.sh/bridge-health-probes"
+
 	// BridgeTypeProxy is the label value for bridge proxy resources.
 	BridgeTypeProxy = "proxy"
 
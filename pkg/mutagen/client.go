@@ -1,17 +1,30 @@
 package mutagen
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"os"
 	"os/exec"
-	"strings"
+	"path/filepath"
 	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	synchronizationsvc "github.com/mutagen-io/mutagen/pkg/service/synchronization"
 )
 
-// Client provides a Go API for interacting with mutagen sync sessions.
-// The mutagen binary must be installed separately using Install() before
-// creating a client.
+// Client provides a Go API for interacting with mutagen sync sessions. It
+// talks directly to the mutagen daemon's synchronization gRPC service over
+// its Unix socket (~/.mutagen/daemon/daemon.sock) instead of shelling out to
+// the mutagen CLI for every operation. The mutagen binary must still be
+// installed with Install() — it owns the daemon process and the sync agent
+// that runs on the remote side.
 type Client struct {
-	binaryPath string
+	conn   *grpc.ClientConn
+	client synchronizationsvc.SynchronizationClient
 }
 
 // SyncConfig configures a sync session.
@@ -24,11 +37,35 @@ type SyncConfig struct {
 	Target string
 	// IgnoreVCS ignores version control directories
 	IgnoreVCS bool
+	// Ignore lists additional gitignore-style patterns to ignore, on top of
+	// IgnoreVCS.
+	Ignore []string
 	// SyncMode is the sync mode (e.g., "two-way-resolved")
 	SyncMode string
 }
 
-// NewClient creates a new mutagen client.
+// SessionStatus is a structured snapshot of a sync session, replacing the
+// previous stringified scrape of `mutagen sync list` output.
+type SessionStatus struct {
+	Name           string
+	Status         string // e.g. "watching", "scanning", "staging-alpha", "staging-beta", "reconciling"
+	AlphaConnected bool
+	BetaConnected  bool
+	Conflicts      int
+	Problems       int
+	LastError      string
+}
+
+// SessionEvent is a single state transition for a sync session, emitted on
+// Client.Events.
+type SessionEvent struct {
+	Name   string
+	Status SessionStatus
+	Err    error
+}
+
+// NewClient creates a new mutagen client, connecting to the daemon and
+// starting it first if it isn't already running.
 // Returns an error if mutagen is not installed.
 // Call Install() first to ensure the binary is available.
 func NewClient() (*Client, error) {
@@ -36,95 +73,158 @@ func NewClient() (*Client, error) {
 		return nil, fmt.Errorf("mutagen is not installed; call Install() first")
 	}
 
+	conn, err := connectDaemon()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to mutagen daemon: %w", err)
+	}
+
 	return &Client{
-		binaryPath: BinaryPath(),
+		conn:   conn,
+		client: synchronizationsvc.NewSynchronizationClient(conn),
 	}, nil
 }
 
-// CreateSyncSession creates a new sync session with the given configuration.
-func (c *Client) CreateSyncSession(cfg SyncConfig) error {
-	// First, terminate any existing session with this name
-	termCmd := exec.Command(c.binaryPath, "sync", "terminate", cfg.Name)
-	termCmd.Run() // Ignore errors - session might not exist
+// Close releases the daemon connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// daemonSocketPath returns the path the mutagen daemon listens on, the same
+// location the mutagen CLI itself uses.
+func daemonSocketPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".mutagen", "daemon", "daemon.sock"), nil
+}
 
-	args := []string{
-		"sync", "create",
-		"--name", cfg.Name,
-		cfg.Source,
-		cfg.Target,
+// connectDaemon dials the daemon's gRPC socket, starting the daemon first
+// via `mutagen daemon start` if the socket doesn't exist yet, and confirms
+// it's actually reachable before returning.
+func connectDaemon() (*grpc.ClientConn, error) {
+	sockPath, err := daemonSocketPath()
+	if err != nil {
+		return nil, err
 	}
 
-	if cfg.IgnoreVCS {
-		args = append(args, "--ignore-vcs")
+	if _, err := os.Stat(sockPath); errors.Is(err, os.ErrNotExist) {
+		if err := exec.Command(BinaryPath(), "daemon", "start").Run(); err != nil {
+			return nil, fmt.Errorf("failed to start mutagen daemon: %w", err)
+		}
 	}
 
-	if cfg.SyncMode != "" {
-		args = append(args, fmt.Sprintf("--sync-mode=%s", cfg.SyncMode))
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", sockPath)
 	}
 
-	cmd := exec.Command(c.binaryPath, args...)
-	output, err := cmd.CombinedOutput()
+	conn, err := grpc.NewClient("passthrough:///mutagen-daemon",
+		grpc.WithContextDialer(dialer),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
 	if err != nil {
-		return fmt.Errorf("failed to create sync session: %w (output: %s)", err, string(output))
+		return nil, err
 	}
 
-	// Flush to ensure initial sync completes
-	flushCmd := exec.Command(c.binaryPath, "sync", "flush", cfg.Name)
-	if err := flushCmd.Run(); err != nil {
-		return fmt.Errorf("failed to flush sync session: %w", err)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := synchronizationsvc.NewSynchronizationClient(conn).List(ctx, &synchronizationsvc.ListRequest{}); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("daemon not reachable at %s: %w", sockPath, err)
 	}
 
-	return nil
+	return conn, nil
+}
+
+// CreateSyncSession creates a new sync session with the given configuration,
+// terminating any existing session with the same name first, and blocks
+// until the initial synchronization completes.
+func (c *Client) CreateSyncSession(cfg SyncConfig) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Best-effort: a session with this name might not exist yet.
+	_, _ = c.client.Terminate(ctx, &synchronizationsvc.TerminateRequest{Name: cfg.Name})
+
+	req := &synchronizationsvc.CreateRequest{
+		Name:      cfg.Name,
+		Alpha:     cfg.Source,
+		Beta:      cfg.Target,
+		IgnoreVCS: cfg.IgnoreVCS,
+		Ignores:   cfg.Ignore,
+		SyncMode:  cfg.SyncMode,
+	}
+	if _, err := c.client.Create(ctx, req); err != nil {
+		return fmt.Errorf("failed to create sync session: %w", err)
+	}
+
+	return c.FlushSyncSession(cfg.Name)
 }
 
 // TerminateSyncSession terminates a sync session by name.
 func (c *Client) TerminateSyncSession(name string) error {
-	cmd := exec.Command(c.binaryPath, "sync", "terminate", name)
-	if err := cmd.Run(); err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := c.client.Terminate(ctx, &synchronizationsvc.TerminateRequest{Name: name}); err != nil {
 		return fmt.Errorf("failed to terminate sync session: %w", err)
 	}
 	return nil
 }
 
-// FlushSyncSession forces a sync cycle for the named session and waits for it to complete.
+// FlushSyncSession forces a sync cycle for the named session and waits for
+// it to complete.
 func (c *Client) FlushSyncSession(name string) error {
-	cmd := exec.Command(c.binaryPath, "sync", "flush", name)
-	if err := cmd.Run(); err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	if _, err := c.client.Flush(ctx, &synchronizationsvc.FlushRequest{Name: name}); err != nil {
 		return fmt.Errorf("failed to flush sync session: %w", err)
 	}
 	return nil
 }
 
-// WaitForSyncReady waits for the sync session to be connected and ready.
+// WaitForSyncReady waits for the sync session to report the "watching" state
+// (the initial scan and staging have completed and it's now watching for
+// changes) or the "scanning" state, which it also accepts as ready. Instead of polling `mutagen sync list` and grepping its
+// output, it subscribes to session state changes via Client.Events.
 func (c *Client) WaitForSyncReady(name string, timeout time.Duration) error {
-	deadline := time.Now().Add(timeout)
-	for time.Now().Before(deadline) {
-		cmd := exec.Command(c.binaryPath, "sync", "list", name)
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			time.Sleep(100 * time.Millisecond)
-			continue
-		}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-		// Check if session is watching (ready)
-		outputStr := string(output)
-		if strings.Contains(outputStr, "Watching for changes") {
-			return nil
-		}
-		// Also accept "Scanning files" as a reasonable state
-		if strings.Contains(outputStr, "Scanning files") {
-			return nil
-		}
+	events, err := c.Events(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to watch sync session: %w", err)
+	}
 
-		time.Sleep(100 * time.Millisecond)
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return fmt.Errorf("sync session %q: event stream closed before becoming ready", name)
+			}
+			if ev.Name != name {
+				continue
+			}
+			if ev.Err != nil {
+				return fmt.Errorf("sync session %q failed: %w", name, ev.Err)
+			}
+			if ev.Status.Status == "watching" || ev.Status.Status == "scanning" {
+				return nil
+			}
+		case <-ctx.Done():
+			return fmt.Errorf("timeout waiting for sync session %q to be ready", name)
+		}
 	}
-	return fmt.Errorf("timeout waiting for sync session to be ready")
 }
 
 // PauseSyncSession pauses the named sync session.
 func (c *Client) PauseSyncSession(name string) error {
-	cmd := exec.Command(c.binaryPath, "sync", "pause", name)
-	if err := cmd.Run(); err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := c.client.Pause(ctx, &synchronizationsvc.PauseRequest{Name: name}); err != nil {
 		return fmt.Errorf("failed to pause sync session: %w", err)
 	}
 	return nil
@@ -132,47 +232,77 @@ func (c *Client) PauseSyncSession(name string) error {
 
 // ResumeSyncSession resumes the named sync session.
 func (c *Client) ResumeSyncSession(name string) error {
-	cmd := exec.Command(c.binaryPath, "sync", "resume", name)
-	if err := cmd.Run(); err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := c.client.Resume(ctx, &synchronizationsvc.ResumeRequest{Name: name}); err != nil {
 		return fmt.Errorf("failed to resume sync session: %w", err)
 	}
 	return nil
 }
 
-// GetSyncStatus returns a brief status string for the named sync session.
-func (c *Client) GetSyncStatus(name string) (string, error) {
-	cmd := exec.Command(c.binaryPath, "sync", "list", name)
-	output, err := cmd.CombinedOutput()
+// GetSyncStatus returns a structured status for the named sync session,
+// built from the daemon's own session state rather than scraping the text
+// output of `mutagen sync list`.
+func (c *Client) GetSyncStatus(name string) (SessionStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := c.client.List(ctx, &synchronizationsvc.ListRequest{Name: name})
 	if err != nil {
-		return "", fmt.Errorf("failed to get sync status: %w", err)
-	}
-
-	// Extract status and synchronizable contents
-	outputStr := string(output)
-	var status string
-	var alphaContents, betaContents string
-	inAlpha := false
-	inBeta := false
-	for _, line := range strings.Split(outputStr, "\n") {
-		trimmed := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmed, "Status:") {
-			status = trimmed
-		}
-		if strings.HasPrefix(trimmed, "Alpha:") {
-			inAlpha = true
-			inBeta = false
-		}
-		if strings.HasPrefix(trimmed, "Beta:") {
-			inAlpha = false
-			inBeta = true
-		}
-		if strings.Contains(line, "files") || strings.Contains(line, "symbolic links") {
-			if inAlpha {
-				alphaContents += trimmed + " "
-			} else if inBeta {
-				betaContents += trimmed + " "
+		return SessionStatus{}, fmt.Errorf("failed to get sync status: %w", err)
+	}
+	if len(resp.Sessions) == 0 {
+		return SessionStatus{}, fmt.Errorf("no sync session named %q", name)
+	}
+	return sessionStatusFromState(resp.Sessions[0]), nil
+}
+
+// Events returns a channel that emits a SessionEvent every time any sync
+// session's state changes, until ctx is canceled or the daemon connection is
+// lost, letting callers (e.g. bridge intercept) react to sync errors and
+// state transitions without parsing CLI output. The channel is closed when
+// the subscription ends.
+func (c *Client) Events(ctx context.Context) (<-chan SessionEvent, error) {
+	stream, err := c.client.Monitor(ctx, &synchronizationsvc.MonitorRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to sync session events: %w", err)
+	}
+
+	events := make(chan SessionEvent)
+	go func() {
+		defer close(events)
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			for _, session := range resp.Sessions {
+				ev := SessionEvent{Name: session.Name, Status: sessionStatusFromState(session)}
+				if session.LastError != "" {
+					ev.Err = errors.New(session.LastError)
+				}
+				select {
+				case events <- ev:
+				case <-ctx.Done():
+					return
+				}
 			}
 		}
+	}()
+	return events, nil
+}
+
+// sessionStatusFromState converts a daemon-reported session state into the
+// SessionStatus shape callers consume.
+func sessionStatusFromState(s *synchronizationsvc.SessionState) SessionStatus {
+	return SessionStatus{
+		Name:           s.Name,
+		Status:         s.Status,
+		AlphaConnected: s.AlphaConnected,
+		BetaConnected:  s.BetaConnected,
+		Conflicts:      len(s.Conflicts),
+		Problems:       len(s.AlphaProblems) + len(s.BetaProblems),
+		LastError:      s.LastError,
 	}
-	return fmt.Sprintf("%s | Alpha: %s| Beta: %s", status, alphaContents, betaContents), nil
 }
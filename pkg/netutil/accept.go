@@ -2,12 +2,15 @@ package netutil
 
 import (
 	"context"
+	"log/slog"
 	"net"
+	"runtime/debug"
 )
 
 // AcceptLoop accepts connections on the listener and calls handler in a
 // goroutine for each connection. It blocks until the context is canceled
-// or the listener is closed.
+// or the listener is closed. A panic inside handler is recovered by
+// HandleCrash rather than taking down the process.
 func AcceptLoop(ctx context.Context, ln net.Listener, handler func(net.Conn)) error {
 	// Close the listener when context is canceled
 	go func() {
@@ -28,6 +31,29 @@ func AcceptLoop(ctx context.Context, ln net.Listener, handler func(net.Conn)) er
 			return err
 		}
 
-		go handler(conn)
+		go func(conn net.Conn) {
+			defer HandleCrash(slog.Default(), conn)
+			handler(conn)
+		}(conn)
+	}
+}
+
+// HandleCrash recovers a panic in the calling goroutine, logging it with a
+// stack trace via logger (falling back to slog.Default() when nil) and
+// closing conn so that one bad connection's handler can't take the rest of
+// the process down with it. Callers other than AcceptLoop that spawn their
+// own per-connection goroutines use it the same way:
+//
+//	go func() {
+//	    defer netutil.HandleCrash(logger, conn)
+//	    handle(conn)
+//	}()
+func HandleCrash(logger *slog.Logger, conn net.Conn) {
+	if r := recover(); r != nil {
+		if logger == nil {
+			logger = slog.Default()
+		}
+		logger.Error("recovered from panic in connection handler", "panic", r, "stack", string(debug.Stack()))
+		conn.Close()
 	}
 }
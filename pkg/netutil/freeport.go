@@ -1,13 +1,10 @@
 package netutil
 
-import "net"
-
-// FindFreePort returns an available TCP port on localhost.
-func FindFreePort() (int, error) {
-	listener, err := net.Listen("tcp", "127.0.0.1:0")
-	if err != nil {
-		return 0, err
-	}
-	defer listener.Close()
-	return listener.Addr().(*net.TCPAddr).Port, nil
+// FindFreePort reserves an available TCP port on localhost from the
+// process-wide DefaultAllocator. It's a thin wrapper kept for callers that
+// don't need UDP or a custom range: unlike opening and closing a bare
+// listener, the port stays reserved against concurrent callers until the
+// returned Reservation is closed.
+func FindFreePort() (*Reservation, error) {
+	return DefaultAllocator.Reserve(ReserveOptions{})
 }
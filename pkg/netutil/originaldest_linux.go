@@ -0,0 +1,69 @@
+//go:build linux
+
+package netutil
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+	"unsafe"
+)
+
+// soOriginalDst is IP_TRANSPARENT's companion getsockopt, SO_ORIGINAL_DST,
+// which recovers the pre-iptables-redirect destination of a connection
+// accepted on a REDIRECT or TPROXY target.
+const soOriginalDst = 80
+
+type sockaddrIn struct {
+	Family uint16
+	Port   [2]byte
+	Addr   [4]byte
+	Zero   [8]byte
+}
+
+// OriginalDest returns the original destination of conn via SO_ORIGINAL_DST,
+// mirroring what originaldest_pf.go does with pf's DIOCNATLOOK on BSD.
+//
+// It issues the getsockopt directly against conn's RawConn instead of going
+// through TCPConn.File(), which duplicates the descriptor and, via File.Fd(),
+// forces it (and the original conn, since they share the same underlying
+// open file description) into blocking mode — defeating the runtime's
+// netpoller for every transparently-proxied connection.
+func OriginalDest(conn net.Conn) (string, error) {
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return "", fmt.Errorf("not a TCP connection")
+	}
+
+	raw, err := tcpConn.SyscallConn()
+	if err != nil {
+		return "", err
+	}
+
+	var addr sockaddrIn
+	var errno syscall.Errno
+	controlErr := raw.Control(func(fd uintptr) {
+		addrLen := uint32(unsafe.Sizeof(addr))
+		_, _, errno = syscall.Syscall6(
+			syscall.SYS_GETSOCKOPT,
+			fd,
+			uintptr(syscall.IPPROTO_IP),
+			uintptr(soOriginalDst),
+			uintptr(unsafe.Pointer(&addr)),
+			uintptr(unsafe.Pointer(&addrLen)),
+			0,
+		)
+	})
+	if controlErr != nil {
+		return "", controlErr
+	}
+	if errno != 0 {
+		return "", fmt.Errorf("getsockopt SO_ORIGINAL_DST failed: %v", errno)
+	}
+
+	// Port is in network byte order (big endian)
+	port := int(addr.Port[0])<<8 + int(addr.Port[1])
+	ip := net.IPv4(addr.Addr[0], addr.Addr[1], addr.Addr[2], addr.Addr[3])
+
+	return fmt.Sprintf("%s:%d", ip, port), nil
+}
@@ -0,0 +1,141 @@
+//go:build darwin || freebsd
+
+package netutil
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// pf ioctl constants from net/pfvar.h. DIOCNATLOOK is the only one we need:
+// it asks the kernel's pf state table for the real (pre-NAT/pre-redirect)
+// address of a connection, given the address 4-tuple pf currently sees.
+const (
+	diocNatLook = 0xc04c4417 // _IOWR('D', 23, struct pfioc_natlook)
+
+	pfAddrV4 = 2 // AF_INET
+	pfAddrV6 = 0x1e
+
+	pfInOut = 2 // PF_OUT: lookup from the perspective of the socket we hold
+
+	pfProtoTCP = 6
+	pfProtoUDP = 17
+)
+
+// pfAddr mirrors the "struct pf_addr" union: 16 bytes wide so it can hold
+// either an IPv4 or IPv6 address, with the unused tail zeroed.
+type pfAddr [16]byte
+
+// pfiocNatlook mirrors FreeBSD/macOS's "struct pfioc_natlook".
+type pfiocNatlook struct {
+	Saddr        pfAddr
+	Daddr        pfAddr
+	Rsaddr       pfAddr
+	Rdaddr       pfAddr
+	Sxport       [2]byte
+	Dxport       [2]byte
+	Rsxport      [2]byte
+	Rdxport      [2]byte
+	Af           uint8
+	Proto        uint8
+	ProtoVariant uint8
+	Direction    uint8
+}
+
+var (
+	pfDeviceOnce  sync.Once
+	pfDeviceFile  *os.File
+	pfUnsupported bool
+)
+
+// openPFDevice probes /dev/pf once per process and caches the result. If
+// /dev/pf can't be opened (missing pf.ko/kext, no permission, or a
+// container without the device node) every later lookup short-circuits
+// instead of retrying a syscall that's known to fail.
+func openPFDevice() (*os.File, error) {
+	pfDeviceOnce.Do(func() {
+		f, err := os.OpenFile("/dev/pf", os.O_RDWR, 0)
+		if err != nil {
+			pfUnsupported = true
+			return
+		}
+		pfDeviceFile = f
+	})
+	if pfUnsupported {
+		return nil, fmt.Errorf("pf not supported: /dev/pf unavailable")
+	}
+	return pfDeviceFile, nil
+}
+
+func setPFAddr(a *pfAddr, ip net.IP) uint8 {
+	if v4 := ip.To4(); v4 != nil {
+		copy(a[:4], v4)
+		return pfAddrV4
+	}
+	copy(a[:16], ip.To16())
+	return pfAddrV6
+}
+
+// OriginalDest returns the pre-redirect destination of conn by asking pf's
+// state table via DIOCNATLOOK, the BSD equivalent of Linux's
+// SO_ORIGINAL_DST. This only returns a meaningful answer when the
+// connection actually arrived through a pf `rdr`/divert-to rule; otherwise
+// it falls back to the connection's own addresses.
+func OriginalDest(conn net.Conn) (string, error) {
+	local, lok := conn.LocalAddr().(*net.TCPAddr)
+	remote, rok := conn.RemoteAddr().(*net.TCPAddr)
+	if !lok || !rok {
+		return conn.RemoteAddr().String(), nil
+	}
+
+	file, err := openPFDevice()
+	if err != nil {
+		// No pf device: there's no redirect happening, so RemoteAddr is
+		// already correct.
+		return remote.String(), nil
+	}
+
+	var nl pfiocNatlook
+	nl.Af = setPFAddr(&nl.Saddr, remote.IP)
+	setPFAddr(&nl.Daddr, local.IP)
+	nl.Proto = pfProtoTCP
+	nl.Direction = pfInOut
+	putPort(nl.Sxport[:], remote.Port)
+	putPort(nl.Dxport[:], local.Port)
+
+	if err := ioctl(file.Fd(), diocNatLook, uintptr(unsafe.Pointer(&nl))); err != nil {
+		// No matching state: not redirected, use the raw addresses.
+		return remote.String(), nil
+	}
+
+	rdIP := addrToIP(nl.Rdaddr, nl.Af)
+	rdPort := getPort(nl.Rdxport[:])
+	return fmt.Sprintf("%s:%d", rdIP, rdPort), nil
+}
+
+func putPort(b []byte, port int) {
+	b[0] = byte(port >> 8)
+	b[1] = byte(port)
+}
+
+func getPort(b []byte) int {
+	return int(b[0])<<8 | int(b[1])
+}
+
+func addrToIP(a pfAddr, af uint8) net.IP {
+	if af == pfAddrV4 {
+		return net.IP(a[:4])
+	}
+	return net.IP(a[:16])
+}
+
+func ioctl(fd uintptr, req uintptr, arg uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, arg); errno != 0 {
+		return errno
+	}
+	return nil
+}
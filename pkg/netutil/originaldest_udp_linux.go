@@ -0,0 +1,88 @@
+//go:build linux
+
+package netutil
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// ipTransparent is IP_TRANSPARENT from linux/in.h; the syscall package
+// doesn't define it. Mirrors the constant of the same name in
+// pkg/proxy/transparent_linux.go.
+const ipTransparent = 19
+
+// ipRecvOrigDstAddr is IP_RECVORIGDSTADDR from linux/in.h (aliased there to
+// IP_ORIGDSTADDR, the control message type the kernel attaches once it's
+// set): the syscall package doesn't define either name.
+const ipRecvOrigDstAddr = 20
+
+// ListenTransparentUDP opens a UDP socket with IP_TRANSPARENT (so the
+// kernel accepts datagrams a TPROXY rule redirected to an address this
+// process doesn't own) and IP_RECVORIGDSTADDR (so ReadFromUDPWithOrigDst
+// can recover that address per-datagram) set, then binds it to addr.
+func ListenTransparentUDP(addr string) (*net.UDPConn, error) {
+	lc := net.ListenConfig{
+		Control: func(_, _ string, c syscall.RawConn) error {
+			var sockErr error
+			if err := c.Control(func(fd uintptr) {
+				if sockErr = syscall.SetsockoptInt(int(fd), syscall.SOL_IP, ipTransparent, 1); sockErr != nil {
+					return
+				}
+				sockErr = syscall.SetsockoptInt(int(fd), syscall.SOL_IP, ipRecvOrigDstAddr, 1)
+			}); err != nil {
+				return err
+			}
+			return sockErr
+		},
+	}
+
+	conn, err := lc.ListenPacket(context.Background(), "udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return conn.(*net.UDPConn), nil
+}
+
+// ReadFromUDPWithOrigDst reads one datagram from conn (which must have been
+// opened with ListenTransparentUDP) into b, returning the client's source
+// address and the datagram's pre-TPROXY destination recovered from the
+// IP_ORIGDSTADDR control message the kernel attaches to it.
+func ReadFromUDPWithOrigDst(conn *net.UDPConn, b []byte) (n int, src *net.UDPAddr, dst string, err error) {
+	oob := make([]byte, 128)
+
+	n, oobn, _, srcAddr, err := conn.ReadMsgUDP(b, oob)
+	if err != nil {
+		return 0, nil, "", err
+	}
+
+	dst, err = parseOrigDstCmsg(oob[:oobn])
+	return n, srcAddr, dst, err
+}
+
+// parseOrigDstCmsg extracts the original destination address from the
+// IP_ORIGDSTADDR control message attached to a datagram read from a socket
+// with IP_RECVORIGDSTADDR set. The control message's payload is a
+// struct sockaddr_in: 2 bytes of family, 2 bytes of port (network byte
+// order) and 4 bytes of IPv4 address.
+func parseOrigDstCmsg(oob []byte) (string, error) {
+	msgs, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return "", fmt.Errorf("parse control messages: %w", err)
+	}
+
+	for _, msg := range msgs {
+		if msg.Header.Level != syscall.SOL_IP || msg.Header.Type != ipRecvOrigDstAddr {
+			continue
+		}
+		if len(msg.Data) < 8 {
+			continue
+		}
+		port := int(msg.Data[2])<<8 + int(msg.Data[3])
+		ip := net.IPv4(msg.Data[4], msg.Data[5], msg.Data[6], msg.Data[7])
+		return fmt.Sprintf("%s:%d", ip, port), nil
+	}
+	return "", fmt.Errorf("IP_ORIGDSTADDR control message not found")
+}
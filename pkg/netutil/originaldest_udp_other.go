@@ -0,0 +1,19 @@
+//go:build !linux
+
+package netutil
+
+import (
+	"fmt"
+	"net"
+)
+
+// ListenTransparentUDP is only supported on Linux: IP_TRANSPARENT and
+// TPROXY are Linux-specific, so UDP interception has no equivalent here.
+func ListenTransparentUDP(addr string) (*net.UDPConn, error) {
+	return nil, fmt.Errorf("transparent UDP listening is only supported on Linux")
+}
+
+// ReadFromUDPWithOrigDst is only supported on Linux; see ListenTransparentUDP.
+func ReadFromUDPWithOrigDst(conn *net.UDPConn, b []byte) (n int, src *net.UDPAddr, dst string, err error) {
+	return 0, nil, "", fmt.Errorf("transparent UDP reading is only supported on Linux")
+}
@@ -0,0 +1,251 @@
+package netutil
+
+import (
+	"fmt"
+	"net"
+	"sync"
+)
+
+// Protocol distinguishes the port spaces tracked by a PortAllocator: TCP and
+// UDP port numbers are independent, so the same number can be reserved in
+// both at once.
+type Protocol string
+
+const (
+	TCP Protocol = "tcp"
+	UDP Protocol = "udp"
+)
+
+// Default ephemeral range a PortAllocator draws from when none is given,
+// matching the Linux kernel's default ephemeral range
+// (net.ipv4.ip_local_port_range).
+const (
+	defaultRangeMin = 32768
+	defaultRangeMax = 60999
+)
+
+// DefaultAllocator is the process-wide PortAllocator backing FindFreePort.
+// Anything that just needs "a free ephemeral port" without managing its own
+// range should use it rather than constructing a PortAllocator per call.
+var DefaultAllocator = NewPortAllocator(defaultRangeMin, defaultRangeMax)
+
+// portKey identifies a single port reservation.
+type portKey struct {
+	host     string
+	protocol Protocol
+	port     int
+}
+
+// PortAllocator hands out ports from a configurable range, tracking in-use
+// ports per (host, protocol) so concurrent callers are never handed the
+// same port — unlike opening and closing a probe listener, which leaves a
+// window for the kernel to hand that port to someone else before the
+// caller gets around to using it. It's modeled in spirit after Docker
+// libnetwork's portallocator.
+type PortAllocator struct {
+	mu       sync.Mutex
+	rangeMin int
+	rangeMax int
+	next     int
+	inUse    map[portKey]bool
+}
+
+// NewPortAllocator creates a PortAllocator that hands out ports in
+// [rangeMin, rangeMax], falling back to an OS-assigned ephemeral port if the
+// whole range is exhausted.
+func NewPortAllocator(rangeMin, rangeMax int) *PortAllocator {
+	return &PortAllocator{
+		rangeMin: rangeMin,
+		rangeMax: rangeMax,
+		next:     rangeMin,
+		inUse:    make(map[portKey]bool),
+	}
+}
+
+// ReserveOptions configures a single allocation.
+type ReserveOptions struct {
+	// Host is the address to probe/bind, e.g. "127.0.0.1". Defaults to
+	// "127.0.0.1".
+	Host string
+	// Protocol selects the port space to allocate from. Defaults to TCP.
+	Protocol Protocol
+	// KeepOpen keeps the probing socket open, with SO_REUSEADDR already
+	// set, instead of closing it before Reserve returns. This lets the
+	// caller hand its fd straight to something like iptables without a
+	// window where the kernel could hand the same port to another process.
+	KeepOpen bool
+}
+
+// Reservation is a port held by a PortAllocator. Close releases it back to
+// the pool; until then, no other caller can be handed the same
+// (host, protocol, port).
+type Reservation struct {
+	allocator *PortAllocator
+	host      string
+	protocol  Protocol
+	port      int
+
+	listener   net.Listener   // set if KeepOpen was requested for a TCP reservation
+	packetConn net.PacketConn // set if KeepOpen was requested for a UDP reservation
+
+	closeOnce sync.Once
+}
+
+// Port returns the reserved port number.
+func (r *Reservation) Port() int { return r.port }
+
+// Listener returns the probing TCP listener if the reservation was made
+// with KeepOpen, or nil otherwise (including for UDP reservations).
+func (r *Reservation) Listener() net.Listener { return r.listener }
+
+// PacketConn returns the probing UDP socket if the reservation was made
+// with KeepOpen, or nil otherwise (including for TCP reservations).
+func (r *Reservation) PacketConn() net.PacketConn { return r.packetConn }
+
+// Close releases the reservation, closing the probe socket first if
+// KeepOpen held it open.
+func (r *Reservation) Close() error {
+	var err error
+	r.closeOnce.Do(func() {
+		if r.listener != nil {
+			err = r.listener.Close()
+		}
+		if r.packetConn != nil {
+			err = r.packetConn.Close()
+		}
+		r.allocator.release(r.host, r.protocol, r.port)
+	})
+	return err
+}
+
+// Reserve finds and reserves a free port according to opts, preferring the
+// allocator's configured range and falling back to an OS-assigned ephemeral
+// port if the range is exhausted.
+func (a *PortAllocator) Reserve(opts ReserveOptions) (*Reservation, error) {
+	host, protocol := normalizeOptions(opts)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	start := a.next
+	for {
+		port := a.next
+		a.next++
+		if a.next > a.rangeMax {
+			a.next = a.rangeMin
+		}
+
+		key := portKey{host, protocol, port}
+		wrapped := a.next == start
+		if a.inUse[key] {
+			if wrapped {
+				break
+			}
+			continue
+		}
+
+		res, err := a.probe(host, protocol, port, opts.KeepOpen)
+		if err != nil {
+			if wrapped {
+				break
+			}
+			continue
+		}
+		a.inUse[key] = true
+		return res, nil
+	}
+
+	// The whole range is in use or unbindable — fall back to whatever port
+	// the OS assigns.
+	res, err := a.probe(host, protocol, 0, opts.KeepOpen)
+	if err != nil {
+		return nil, fmt.Errorf("netutil: no free port available in [%d, %d]: %w", a.rangeMin, a.rangeMax, err)
+	}
+	a.inUse[portKey{host, protocol, res.port}] = true
+	return res, nil
+}
+
+// ReservePort reserves a specific port, failing if it's already tracked as
+// in-use or can't be bound. Tests that need a deterministic address should
+// use this instead of Reserve.
+func (a *PortAllocator) ReservePort(port int, opts ReserveOptions) (*Reservation, error) {
+	host, protocol := normalizeOptions(opts)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := portKey{host, protocol, port}
+	if a.inUse[key] {
+		return nil, fmt.Errorf("netutil: port %d/%s already reserved on %s", port, protocol, host)
+	}
+
+	res, err := a.probe(host, protocol, port, opts.KeepOpen)
+	if err != nil {
+		return nil, err
+	}
+	a.inUse[key] = true
+	return res, nil
+}
+
+// Release frees a port reserved on host/protocol, allowing it to be handed
+// out again. Prefer closing the *Reservation returned by Reserve/ReservePort
+// instead — this is for callers that only kept the port number around.
+func (a *PortAllocator) Release(host string, protocol Protocol, port int) {
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	if protocol == "" {
+		protocol = TCP
+	}
+	a.release(host, protocol, port)
+}
+
+func (a *PortAllocator) release(host string, protocol Protocol, port int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.inUse, portKey{host, protocol, port})
+}
+
+// probe binds port (or an OS-assigned one if port == 0) to confirm it's
+// free, returning a Reservation for it. Must be called with a.mu held.
+func (a *PortAllocator) probe(host string, protocol Protocol, port int, keepOpen bool) (*Reservation, error) {
+	addr := net.JoinHostPort(host, fmt.Sprintf("%d", port))
+
+	if protocol == UDP {
+		pc, err := reusePacketConn("udp", addr)
+		if err != nil {
+			return nil, err
+		}
+		res := &Reservation{allocator: a, host: host, protocol: protocol, port: pc.LocalAddr().(*net.UDPAddr).Port}
+		if keepOpen {
+			res.packetConn = pc
+		} else {
+			pc.Close()
+		}
+		return res, nil
+	}
+
+	ln, err := reuseListener("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	res := &Reservation{allocator: a, host: host, protocol: protocol, port: ln.Addr().(*net.TCPAddr).Port}
+	if keepOpen {
+		res.listener = ln
+	} else {
+		ln.Close()
+	}
+	return res, nil
+}
+
+func normalizeOptions(opts ReserveOptions) (host string, protocol Protocol) {
+	host = opts.Host
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	protocol = opts.Protocol
+	if protocol == "" {
+		protocol = TCP
+	}
+	return host, protocol
+}
@@ -0,0 +1,16 @@
+//go:build !linux && !darwin && !freebsd
+
+package netutil
+
+import "net"
+
+// reuseListener and reusePacketConn fall back to plain net.Listen/ListenPacket
+// on platforms outside the syscall package's SO_REUSEADDR support: probing
+// still works, but ReserveOptions.KeepOpen loses its no-window guarantee.
+func reuseListener(network, address string) (net.Listener, error) {
+	return net.Listen(network, address)
+}
+
+func reusePacketConn(network, address string) (net.PacketConn, error) {
+	return net.ListenPacket(network, address)
+}
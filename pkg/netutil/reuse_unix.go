@@ -0,0 +1,33 @@
+//go:build linux || darwin || freebsd
+
+package netutil
+
+import (
+	"context"
+	"net"
+	"syscall"
+)
+
+// reuseControl sets SO_REUSEADDR on the probing socket before it's bound, so
+// a reservation kept open with ReserveOptions.KeepOpen can be handed
+// straight to something like iptables without the usual window where the
+// kernel could hand the same port to another process in between.
+func reuseControl(_, _ string, c syscall.RawConn) error {
+	var sockErr error
+	if err := c.Control(func(fd uintptr) {
+		sockErr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1)
+	}); err != nil {
+		return err
+	}
+	return sockErr
+}
+
+func reuseListener(network, address string) (net.Listener, error) {
+	lc := net.ListenConfig{Control: reuseControl}
+	return lc.Listen(context.Background(), network, address)
+}
+
+func reusePacketConn(network, address string) (net.PacketConn, error) {
+	lc := net.ListenConfig{Control: reuseControl}
+	return lc.ListenPacket(context.Background(), network, address)
+}
@@ -1,17 +1,46 @@
 package plumbing
 
 import (
+	"errors"
 	"io"
+	"log/slog"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// tunnelConnInitialWindow is the number of bytes of unacknowledged data a
+// TunnelConn may have in flight before Write blocks waiting for the peer to
+// report it has drained its buffer, the same size as yamux's default stream
+// window.
+const tunnelConnInitialWindow = 256 * 1024
+
+// tunnelConnMaxWriteChunk caps how much of a single Write call goes out in
+// one SendFn call, so a large write doesn't have to wait for the full send
+// window before making any progress.
+const tunnelConnMaxWriteChunk = 32 * 1024
+
+// tunnelConnReconnectRetryInterval is how long Write waits before retrying a
+// SendFn call that returned ErrReconnecting, rather than busy-looping while
+// the caller re-establishes its transport.
+const tunnelConnReconnectRetryInterval = 100 * time.Millisecond
+
+// ErrReconnecting is returned by SendFn to indicate the underlying
+// transport is momentarily down but expected to recover. Write treats it as
+// transient and retries until the write deadline elapses, instead of
+// failing the caller for what's usually a sub-second blip.
+var ErrReconnecting = errors.New("plumbing: connection is reconnecting")
+
 // TunnelConn wraps one side of a multiplexed tunnel connection as a net.Conn.
 //
 // Reads come from a channel fed by a central read loop (demuxed by connection
 // ID). Writes go through a caller-provided send function that handles stream
-// serialization and any mutex.
+// serialization and any mutex. Per-connection credit-based backpressure keeps
+// a slow reader on the far side from forcing the sender to buffer an
+// unbounded amount of data: SendFn consumes send-window credit and
+// GrantCredit restores it when a WINDOW_UPDATE frame arrives for this
+// connection, the same scheme HTTP/2 and yamux use.
 type TunnelConn struct {
 	readCh    chan []byte
 	closeCh   chan struct{}
@@ -23,6 +52,17 @@ type TunnelConn struct {
 	remoteAddr net.Addr
 
 	buf []byte // leftover from a partial Read
+
+	mu            sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+	recvWindow    int64
+
+	sendWindow       atomic.Int64
+	windowUpdated    chan struct{}
+	sendWindowUpdate func(credit int64) error
+
+	logger *slog.Logger
 }
 
 // TunnelConnConfig configures a TunnelConn.
@@ -31,24 +71,51 @@ type TunnelConnConfig struct {
 	// any mutex and message framing.
 	SendFn func([]byte) error
 
+	// SendWindowUpdate reports to the peer that n additional bytes of
+	// buffer have been drained by Read, so it may grant that much send
+	// credit back. Optional — when nil, Write never blocks on credit,
+	// preserving the old unbounded-buffering behavior.
+	SendWindowUpdate func(n int64) error
+
 	// OnClose is called once when Close is invoked (e.g. to remove the conn
 	// from a tracking map). May be nil.
 	OnClose func()
 
 	LocalAddr  net.Addr
 	RemoteAddr net.Addr
+
+	// Logger carries fixed contextual attributes (e.g. connection_id,
+	// remote_addr) onto every log line this TunnelConn emits. Defaults to
+	// slog.Default() when nil.
+	Logger *slog.Logger
 }
 
 // NewTunnelConn creates a TunnelConn ready for use with bidi.New.
 func NewTunnelConn(cfg TunnelConnConfig) *TunnelConn {
-	return &TunnelConn{
-		readCh:     make(chan []byte, 64),
-		closeCh:    make(chan struct{}),
-		sendFn:     cfg.SendFn,
-		onClose:    cfg.OnClose,
-		localAddr:  cfg.LocalAddr,
-		remoteAddr: cfg.RemoteAddr,
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	tc := &TunnelConn{
+		readCh:           make(chan []byte, 64),
+		closeCh:          make(chan struct{}),
+		sendFn:           cfg.SendFn,
+		onClose:          cfg.OnClose,
+		localAddr:        cfg.LocalAddr,
+		remoteAddr:       cfg.RemoteAddr,
+		windowUpdated:    make(chan struct{}, 1),
+		sendWindowUpdate: cfg.SendWindowUpdate,
+		logger:           logger,
 	}
+	if tc.sendWindowUpdate != nil {
+		tc.sendWindow.Store(tunnelConnInitialWindow)
+	} else {
+		// No flow-control callback configured — give Write an effectively
+		// unlimited window so it never blocks, matching prior behavior.
+		tc.sendWindow.Store(1<<63 - 1)
+	}
+	return tc
 }
 
 // Deliver pushes data into the read buffer. Called by the central read loop
@@ -60,6 +127,20 @@ func (tc *TunnelConn) Deliver(data []byte) {
 	}
 }
 
+// GrantCredit increases the connection's send window by n bytes. Called by
+// the central read loop when a WINDOW_UPDATE frame arrives for this
+// connection's ID.
+func (tc *TunnelConn) GrantCredit(n int64) {
+	if n <= 0 {
+		return
+	}
+	tc.sendWindow.Add(n)
+	select {
+	case tc.windowUpdated <- struct{}{}:
+	default:
+	}
+}
+
 // SignalClose tears down the connection from the remote side.
 func (tc *TunnelConn) SignalClose() {
 	tc.closeOnce.Do(func() { close(tc.closeCh) })
@@ -73,6 +154,12 @@ func (tc *TunnelConn) Read(b []byte) (int, error) {
 		tc.buf = tc.buf[n:]
 		return n, nil
 	}
+
+	timeoutCh, stop := tc.timerFor(tc.readDeadlineValue())
+	if stop != nil {
+		defer stop()
+	}
+
 	select {
 	case data, ok := <-tc.readCh:
 		if !ok {
@@ -82,17 +169,86 @@ func (tc *TunnelConn) Read(b []byte) (int, error) {
 		if n < len(data) {
 			tc.buf = data[n:]
 		}
+		tc.grantReadCredit(len(data))
 		return n, nil
+	case <-timeoutCh:
+		return 0, timeoutError{}
 	case <-tc.closeCh:
 		return 0, io.EOF
 	}
 }
 
+// grantReadCredit accumulates bytes Read has taken off the read channel
+// (including any leftover it keeps in buf for the next Read) and, once they cross
+// half the initial window, reports them back via SendWindowUpdate so the
+// peer's Write can keep making progress.
+func (tc *TunnelConn) grantReadCredit(n int) {
+	if tc.sendWindowUpdate == nil {
+		return
+	}
+
+	tc.mu.Lock()
+	tc.recvWindow += int64(n)
+	var credit int64
+	if tc.recvWindow >= tunnelConnInitialWindow/2 {
+		credit = tc.recvWindow
+		tc.recvWindow = 0
+	}
+	tc.mu.Unlock()
+
+	if credit > 0 {
+		_ = tc.sendWindowUpdate(credit)
+	}
+}
+
 func (tc *TunnelConn) Write(b []byte) (int, error) {
-	if err := tc.sendFn(b); err != nil {
-		return 0, err
+	timeoutCh, stop := tc.timerFor(tc.writeDeadlineValue())
+	if stop != nil {
+		defer stop()
+	}
+
+	written := 0
+	for written < len(b) {
+		chunk := b[written:]
+		if len(chunk) > tunnelConnMaxWriteChunk {
+			chunk = chunk[:tunnelConnMaxWriteChunk]
+		}
+
+		for tc.sendWindow.Load() <= 0 {
+			select {
+			case <-tc.windowUpdated:
+			case <-timeoutCh:
+				return written, timeoutError{}
+			case <-tc.closeCh:
+				return written, io.ErrClosedPipe
+			}
+		}
+
+		if avail := tc.sendWindow.Load(); int64(len(chunk)) > avail {
+			chunk = chunk[:avail]
+		}
+
+		for {
+			err := tc.sendFn(chunk)
+			if err == nil {
+				break
+			}
+			if !errors.Is(err, ErrReconnecting) {
+				return written, err
+			}
+			select {
+			case <-time.After(tunnelConnReconnectRetryInterval):
+			case <-timeoutCh:
+				return written, timeoutError{}
+			case <-tc.closeCh:
+				return written, io.ErrClosedPipe
+			}
+		}
+		tc.sendWindow.Add(-int64(len(chunk)))
+		written += len(chunk)
 	}
-	return len(b), nil
+
+	return written, nil
 }
 
 func (tc *TunnelConn) Close() error {
@@ -103,10 +259,66 @@ func (tc *TunnelConn) Close() error {
 	return nil
 }
 
-func (tc *TunnelConn) LocalAddr() net.Addr                { return tc.localAddr }
-func (tc *TunnelConn) RemoteAddr() net.Addr               { return tc.remoteAddr }
-func (tc *TunnelConn) SetDeadline(_ time.Time) error      { return nil }
-func (tc *TunnelConn) SetReadDeadline(_ time.Time) error  { return nil }
-func (tc *TunnelConn) SetWriteDeadline(_ time.Time) error { return nil }
+func (tc *TunnelConn) LocalAddr() net.Addr  { return tc.localAddr }
+func (tc *TunnelConn) RemoteAddr() net.Addr { return tc.remoteAddr }
+
+func (tc *TunnelConn) SetDeadline(t time.Time) error {
+	tc.mu.Lock()
+	tc.readDeadline = t
+	tc.writeDeadline = t
+	tc.mu.Unlock()
+	return nil
+}
+
+func (tc *TunnelConn) SetReadDeadline(t time.Time) error {
+	tc.mu.Lock()
+	tc.readDeadline = t
+	tc.mu.Unlock()
+	return nil
+}
+
+func (tc *TunnelConn) SetWriteDeadline(t time.Time) error {
+	tc.mu.Lock()
+	tc.writeDeadline = t
+	tc.mu.Unlock()
+	return nil
+}
+
+func (tc *TunnelConn) readDeadlineValue() time.Time {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.readDeadline
+}
+
+func (tc *TunnelConn) writeDeadlineValue() time.Time {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.writeDeadline
+}
+
+// timerFor returns a channel that fires when deadline elapses, along with a
+// stop function to release the timer. Both are nil/no-op when deadline is
+// the zero value, meaning no deadline is set.
+func (tc *TunnelConn) timerFor(deadline time.Time) (<-chan time.Time, func()) {
+	if deadline.IsZero() {
+		return nil, nil
+	}
+	d := time.Until(deadline)
+	if d <= 0 {
+		fired := make(chan time.Time, 1)
+		fired <- time.Now()
+		return fired, func() {}
+	}
+	timer := time.NewTimer(d)
+	return timer.C, func() { timer.Stop() }
+}
+
+// timeoutError implements net.Error for deadline expiry on TunnelConn.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "plumbing: i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
 
 var _ net.Conn = (*TunnelConn)(nil)
+var _ net.Error = timeoutError{}
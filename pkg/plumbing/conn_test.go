@@ -0,0 +1,219 @@
+package plumbing
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestTunnelConn(t *testing.T, cfg TunnelConnConfig) *TunnelConn {
+	t.Helper()
+	if cfg.SendFn == nil {
+		cfg.SendFn = func([]byte) error { return nil }
+	}
+	return NewTunnelConn(cfg)
+}
+
+func TestTunnelConnWriteBlocksUntilCreditGrantedThenSends(t *testing.T) {
+	var mu sync.Mutex
+	var sent [][]byte
+	tc := newTestTunnelConn(t, TunnelConnConfig{
+		SendFn: func(b []byte) error {
+			mu.Lock()
+			sent = append(sent, append([]byte(nil), b...))
+			mu.Unlock()
+			return nil
+		},
+		SendWindowUpdate: func(int64) error { return nil },
+	})
+	// Drain the initial window so Write has to wait for fresh credit.
+	tc.sendWindow.Store(0)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		n, err := tc.Write([]byte("payload"))
+		if err != nil {
+			t.Errorf("Write() error: %v", err)
+		}
+		if n != len("payload") {
+			t.Errorf("Write() n = %d, want %d", n, len("payload"))
+		}
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Write() returned before any credit was granted")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	tc.GrantCredit(int64(len("payload")))
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Write() did not unblock after GrantCredit")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != 1 || string(sent[0]) != "payload" {
+		t.Errorf("sent = %q, want a single \"payload\" chunk", sent)
+	}
+}
+
+func TestTunnelConnWriteDeadlineExpiresWhileWindowExhausted(t *testing.T) {
+	tc := newTestTunnelConn(t, TunnelConnConfig{SendWindowUpdate: func(int64) error { return nil }})
+	tc.sendWindow.Store(0)
+	if err := tc.SetWriteDeadline(time.Now().Add(20 * time.Millisecond)); err != nil {
+		t.Fatalf("SetWriteDeadline() error: %v", err)
+	}
+
+	_, err := tc.Write([]byte("hello"))
+	te, ok := err.(timeoutError)
+	if !ok || !te.Timeout() {
+		t.Errorf("Write() error = %v, want a timeoutError", err)
+	}
+}
+
+func TestTunnelConnWithoutSendWindowUpdateNeverBlocksOnCredit(t *testing.T) {
+	// Per NewTunnelConn, a nil SendWindowUpdate means flow control is
+	// disabled entirely and Write gets an effectively unlimited window.
+	tc := newTestTunnelConn(t, TunnelConnConfig{})
+
+	if _, err := tc.Write([]byte("hello")); err != nil {
+		t.Errorf("Write() error: %v, want no blocking with flow control disabled", err)
+	}
+}
+
+func TestTunnelConnGrantCreditReplenishesSendWindow(t *testing.T) {
+	tc := newTestTunnelConn(t, TunnelConnConfig{SendWindowUpdate: func(int64) error { return nil }})
+	tc.sendWindow.Store(0)
+
+	tc.GrantCredit(100)
+	if got := tc.sendWindow.Load(); got != 100 {
+		t.Errorf("sendWindow = %d, want 100", got)
+	}
+
+	select {
+	case <-tc.windowUpdated:
+	default:
+		t.Error("GrantCredit did not signal windowUpdated")
+	}
+}
+
+func TestTunnelConnGrantCreditIgnoresNonPositive(t *testing.T) {
+	tc := newTestTunnelConn(t, TunnelConnConfig{SendWindowUpdate: func(int64) error { return nil }})
+	tc.sendWindow.Store(0)
+
+	tc.GrantCredit(0)
+	tc.GrantCredit(-5)
+	if got := tc.sendWindow.Load(); got != 0 {
+		t.Errorf("sendWindow = %d, want 0", got)
+	}
+}
+
+func TestTunnelConnReadGrantsWindowUpdateAfterHalfWindowConsumed(t *testing.T) {
+	var mu sync.Mutex
+	var credited int64
+	tc := newTestTunnelConn(t, TunnelConnConfig{
+		SendWindowUpdate: func(n int64) error {
+			mu.Lock()
+			credited += n
+			mu.Unlock()
+			return nil
+		},
+	})
+
+	belowHalf := tunnelConnInitialWindow/2 - 1
+	tc.Deliver(make([]byte, belowHalf))
+	tc.Deliver(make([]byte, 2))
+
+	buf := make([]byte, belowHalf)
+	if _, err := tc.Read(buf); err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	mu.Lock()
+	if credited != 0 {
+		t.Errorf("credited = %d after consuming less than half the window, want 0", credited)
+	}
+	mu.Unlock()
+
+	if _, err := tc.Read(buf[:2]); err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if credited != int64(belowHalf+2) {
+		t.Errorf("credited = %d, want %d once recvWindow crosses half the initial window", credited, belowHalf+2)
+	}
+}
+
+func TestTunnelConnReadDeadlineExpires(t *testing.T) {
+	tc := newTestTunnelConn(t, TunnelConnConfig{})
+	if err := tc.SetReadDeadline(time.Now().Add(20 * time.Millisecond)); err != nil {
+		t.Fatalf("SetReadDeadline() error: %v", err)
+	}
+
+	_, err := tc.Read(make([]byte, 16))
+	te, ok := err.(timeoutError)
+	if !ok || !te.Timeout() {
+		t.Errorf("Read() error = %v, want a timeoutError", err)
+	}
+}
+
+func TestTunnelConnReadReturnsEOFAfterSignalClose(t *testing.T) {
+	tc := newTestTunnelConn(t, TunnelConnConfig{})
+	tc.SignalClose()
+
+	_, err := tc.Read(make([]byte, 16))
+	if err != io.EOF {
+		t.Errorf("Read() error = %v, want io.EOF", err)
+	}
+}
+
+func TestTunnelConnWriteRetriesOnErrReconnecting(t *testing.T) {
+	var attempts int
+	tc := newTestTunnelConn(t, TunnelConnConfig{
+		SendFn: func([]byte) error {
+			attempts++
+			if attempts < 3 {
+				return ErrReconnecting
+			}
+			return nil
+		},
+	})
+
+	if _, err := tc.Write([]byte("hi")); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestTunnelConnWriteFailsOnNonReconnectingSendError(t *testing.T) {
+	wantErr := errors.New("boom")
+	tc := newTestTunnelConn(t, TunnelConnConfig{
+		SendFn: func([]byte) error { return wantErr },
+	})
+
+	_, err := tc.Write([]byte("hi"))
+	if !errors.Is(err, wantErr) {
+		t.Errorf("Write() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestTunnelConnCloseCallsOnClose(t *testing.T) {
+	var closed bool
+	tc := newTestTunnelConn(t, TunnelConnConfig{OnClose: func() { closed = true }})
+
+	if err := tc.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	if !closed {
+		t.Error("Close() did not call OnClose")
+	}
+}
@@ -0,0 +1,119 @@
+// Package probe normalizes a Pod's HTTP-based liveness/readiness/startup
+// probes and preStop lifecycle hook into a single concrete form: resolved
+// port numbers, a scheme, and headers, all using the same host/port
+// resolution kubelet applies when it actually issues these requests.
+//
+// Before Kubernetes' ConsistentHTTPGetHandlers feature gate, kubelet's
+// probers and its PreStop lifecycle handler resolved corev1.HTTPGetAction
+// through separate code paths that could disagree on defaults (notably
+// Host). This package picks one resolution and applies it everywhere, so a
+// bridge feature running inside the devcontainer can forward probe/lifecycle
+// traffic to the developer's app with the same semantics the real kubelet
+// would use in-cluster.
+package probe
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Handler is one normalized HTTP GET handler: a resolved port, scheme, path,
+// and headers ready to issue as a real request.
+type Handler struct {
+	// Scheme is "HTTP" or "HTTPS", defaulting to "HTTP" like corev1.
+	Scheme string
+	// Host is the HTTPGetAction.Host override, or "" when unset. An empty
+	// Host means the caller should target the app's own listen address
+	// rather than sending an explicit Host header, matching kubelet's
+	// behavior of defaulting to the pod IP when Host is empty.
+	Host string
+	// Port is the resolved container port number. Named ports (e.g.
+	// "http") are looked up against the container's declared ports; 0 means
+	// the name couldn't be resolved.
+	Port int32
+	Path string
+	// Headers holds HTTPHeaders by name, last one wins on duplicates,
+	// matching corev1's own documented behavior for HTTPGetAction.
+	Headers map[string]string
+}
+
+// Spec normalizes every HTTP handler for one container in a Pod.
+// A nil field means that container has no probe (or preStop hook) of that
+// kind, or it's not HTTP-based (e.g. exec or tcpSocket).
+type Spec struct {
+	ContainerName string
+	Liveness      *Handler
+	Readiness     *Handler
+	Startup       *Handler
+	PreStop       *Handler
+}
+
+// FromPodSpec normalizes the HTTP probe and preStop handlers of every
+// container in spec. Containers with no HTTP handlers at all are omitted.
+func FromPodSpec(spec *corev1.PodSpec) []Spec {
+	var specs []Spec
+	for _, c := range spec.Containers {
+		s := Spec{
+			ContainerName: c.Name,
+			Liveness:      handlerFromProbe(c.LivenessProbe, c.Ports),
+			Readiness:     handlerFromProbe(c.ReadinessProbe, c.Ports),
+			Startup:       handlerFromProbe(c.StartupProbe, c.Ports),
+			PreStop:       handlerFromLifecycle(c.Lifecycle, c.Ports),
+		}
+		if s.Liveness == nil && s.Readiness == nil && s.Startup == nil && s.PreStop == nil {
+			continue
+		}
+		specs = append(specs, s)
+	}
+	return specs
+}
+
+func handlerFromProbe(p *corev1.Probe, ports []corev1.ContainerPort) *Handler {
+	if p == nil || p.HTTPGet == nil {
+		return nil
+	}
+	return handlerFromHTTPGet(p.HTTPGet, ports)
+}
+
+func handlerFromLifecycle(lc *corev1.Lifecycle, ports []corev1.ContainerPort) *Handler {
+	if lc == nil || lc.PreStop == nil || lc.PreStop.HTTPGet == nil {
+		return nil
+	}
+	return handlerFromHTTPGet(lc.PreStop.HTTPGet, ports)
+}
+
+func handlerFromHTTPGet(h *corev1.HTTPGetAction, ports []corev1.ContainerPort) *Handler {
+	scheme := string(h.Scheme)
+	if scheme == "" {
+		scheme = string(corev1.URISchemeHTTP)
+	}
+
+	headers := make(map[string]string, len(h.HTTPHeaders))
+	for _, hdr := range h.HTTPHeaders {
+		headers[hdr.Name] = hdr.Value
+	}
+
+	return &Handler{
+		Scheme:  scheme,
+		Host:    h.Host,
+		Port:    resolvePort(h.Port, ports),
+		Path:    h.Path,
+		Headers: headers,
+	}
+}
+
+// resolvePort resolves an IntOrString port against a container's declared
+// ports by name, or returns it directly if it's already numeric. Returns 0
+// if a named port isn't declared, matching kubelet's own validation-time
+// rejection of that case rather than guessing.
+func resolvePort(port intstr.IntOrString, ports []corev1.ContainerPort) int32 {
+	if port.Type == intstr.Int {
+		return port.IntVal
+	}
+	for _, p := range ports {
+		if p.Name == port.StrVal {
+			return p.ContainerPort
+		}
+	}
+	return 0
+}
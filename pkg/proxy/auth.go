@@ -0,0 +1,325 @@
+package proxy
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenVerifier verifies a bearer token presented on tunnel registration and
+// returns its claims, or an error if the token's signature is invalid or it's
+// expired. WSServerConfig.TokenVerifier lets callers bring their own (e.g.
+// Vercel OIDC) in place of HS256Verifier/JWKSVerifier.
+type TokenVerifier interface {
+	Verify(token string) (TokenClaims, error)
+}
+
+// TokenClaims are the claims handleTunnel enforces against a registration,
+// on top of whatever a TokenVerifier itself checks (signature, expiry).
+type TokenClaims struct {
+	// Subject identifies the caller, surfaced in logs as "auth_subject".
+	Subject string
+	// Sandbox must match the WSServer's configured name. Empty skips the check.
+	Sandbox string
+	// Role must be "client" or "server" and match the registration's
+	// is_server flag. Empty skips the check.
+	Role string
+	// ConnectionKey, if non-empty, must match a server registration's
+	// connection_key.
+	ConnectionKey string
+	// Expiry is the token's exp claim.
+	Expiry time.Time
+}
+
+// jwtHeader is the subset of a JWT header this package inspects.
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// jwtPayload is the set of claims handleTunnel's registration auth cares
+// about, deliberately narrower than a general-purpose JWT library's claim set.
+type jwtPayload struct {
+	Sub           string        `json:"sub"`
+	Exp           int64         `json:"exp"`
+	Aud           audienceClaim `json:"aud"`
+	Sandbox       string        `json:"sandbox"`
+	Role          string        `json:"role"`
+	ConnectionKey string        `json:"connection_key"`
+}
+
+// audienceClaim accepts a JWT "aud" claim that's either a single string or
+// an array of strings, per RFC 7519 §4.1.3.
+type audienceClaim []string
+
+func (a *audienceClaim) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audienceClaim{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = audienceClaim(many)
+	return nil
+}
+
+func (a audienceClaim) contains(want string) bool {
+	for _, v := range a {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+// splitJWT splits a compact JWT into its base64url header/payload segments
+// plus the decoded signature, without yet verifying anything.
+func splitJWT(token string) (headerB64, payloadB64 string, signedPart string, sig []byte, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", "", "", nil, fmt.Errorf("malformed token: expected 3 segments, got %d", len(parts))
+	}
+	sig, err = base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", "", "", nil, fmt.Errorf("decode signature: %w", err)
+	}
+	return parts[0], parts[1], parts[0] + "." + parts[1], sig, nil
+}
+
+func decodeJWTHeader(headerB64 string) (jwtHeader, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(headerB64)
+	if err != nil {
+		return jwtHeader{}, fmt.Errorf("decode header: %w", err)
+	}
+	var hdr jwtHeader
+	if err := json.Unmarshal(raw, &hdr); err != nil {
+		return jwtHeader{}, fmt.Errorf("parse header: %w", err)
+	}
+	return hdr, nil
+}
+
+// claimsFromPayload decodes and validates the exp claim (rejecting tokens
+// that omit it, since a missing exp is indistinguishable from "never
+// expires") and, if wantAudience is non-empty, the aud claim. Sandbox/
+// role/connection_key checks are left to the caller since those depend on
+// the registration being authenticated.
+func claimsFromPayload(payloadB64, wantAudience string) (TokenClaims, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(payloadB64)
+	if err != nil {
+		return TokenClaims{}, fmt.Errorf("decode payload: %w", err)
+	}
+	var p jwtPayload
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return TokenClaims{}, fmt.Errorf("parse claims: %w", err)
+	}
+
+	if p.Exp == 0 {
+		return TokenClaims{}, fmt.Errorf("token has no exp claim")
+	}
+	claims := TokenClaims{
+		Subject:       p.Sub,
+		Sandbox:       p.Sandbox,
+		Role:          p.Role,
+		ConnectionKey: p.ConnectionKey,
+		Expiry:        time.Unix(p.Exp, 0),
+	}
+	if time.Now().After(claims.Expiry) {
+		return TokenClaims{}, fmt.Errorf("token expired at %s", claims.Expiry)
+	}
+	if wantAudience != "" && !p.Aud.contains(wantAudience) {
+		return TokenClaims{}, fmt.Errorf("token audience %v does not include %q", []string(p.Aud), wantAudience)
+	}
+	return claims, nil
+}
+
+// HS256Verifier verifies tokens signed with a single static HMAC-SHA256
+// secret, the "shared secret" option alongside JWKSVerifier's asymmetric one.
+type HS256Verifier struct {
+	Secret []byte
+	// Audience, if set, must appear in the token's aud claim. Empty skips
+	// the check.
+	Audience string
+}
+
+// Verify implements TokenVerifier.
+func (v HS256Verifier) Verify(token string) (TokenClaims, error) {
+	headerB64, payloadB64, signedPart, sig, err := splitJWT(token)
+	if err != nil {
+		return TokenClaims{}, err
+	}
+	hdr, err := decodeJWTHeader(headerB64)
+	if err != nil {
+		return TokenClaims{}, err
+	}
+	if hdr.Alg != "HS256" {
+		return TokenClaims{}, fmt.Errorf("unsupported signing algorithm %q, want HS256", hdr.Alg)
+	}
+
+	mac := hmac.New(sha256.New, v.Secret)
+	mac.Write([]byte(signedPart))
+	if !hmac.Equal(mac.Sum(nil), sig) {
+		return TokenClaims{}, fmt.Errorf("signature verification failed")
+	}
+
+	return claimsFromPayload(payloadB64, v.Audience)
+}
+
+// JWKSVerifier verifies RS256-signed tokens against RSA public keys fetched
+// from a JWKS URL, caching keys by kid until RefreshInterval elapses.
+type JWKSVerifier struct {
+	// URL is fetched directly as a JWKS document ({"keys": [...]}), not an
+	// OIDC discovery document.
+	URL string
+	// RefreshInterval bounds how long fetched keys are trusted before
+	// JWKSVerifier re-fetches the JWKS document. Defaults to 10 minutes.
+	RefreshInterval time.Duration
+	// HTTPClient is used to fetch URL. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// Audience, if set, must appear in the token's aud claim. Empty skips
+	// the check.
+	Audience string
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time
+}
+
+type jwksDocument struct {
+	Keys []jwkKey `json:"keys"`
+}
+
+type jwkKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// Verify implements TokenVerifier.
+func (v *JWKSVerifier) Verify(token string) (TokenClaims, error) {
+	headerB64, payloadB64, signedPart, sig, err := splitJWT(token)
+	if err != nil {
+		return TokenClaims{}, err
+	}
+	hdr, err := decodeJWTHeader(headerB64)
+	if err != nil {
+		return TokenClaims{}, err
+	}
+	if hdr.Alg != "RS256" {
+		return TokenClaims{}, fmt.Errorf("unsupported signing algorithm %q, want RS256", hdr.Alg)
+	}
+
+	key, err := v.keyForKid(hdr.Kid)
+	if err != nil {
+		return TokenClaims{}, fmt.Errorf("resolve signing key: %w", err)
+	}
+
+	digest := sha256.Sum256([]byte(signedPart))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+		return TokenClaims{}, fmt.Errorf("signature verification failed: %w", err)
+	}
+
+	return claimsFromPayload(payloadB64, v.Audience)
+}
+
+func (v *JWKSVerifier) keyForKid(kid string) (*rsa.PublicKey, error) {
+	v.mu.Lock()
+	refresh := v.keys == nil || time.Since(v.fetched) > v.refreshInterval()
+	v.mu.Unlock()
+
+	if refresh {
+		if err := v.fetchKeys(); err != nil {
+			v.mu.Lock()
+			stale := v.keys
+			v.mu.Unlock()
+			if stale == nil {
+				return nil, err
+			}
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	key, ok := v.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("no key found for kid %q", kid)
+	}
+	return key, nil
+}
+
+func (v *JWKSVerifier) refreshInterval() time.Duration {
+	if v.RefreshInterval > 0 {
+		return v.RefreshInterval
+	}
+	return 10 * time.Minute
+}
+
+func (v *JWKSVerifier) fetchKeys() error {
+	client := v.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Get(v.URL)
+	if err != nil {
+		return fmt.Errorf("fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch JWKS: unexpected status %s", resp.Status)
+	}
+
+	var doc jwksDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return fmt.Errorf("decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		pub, err := rsaPublicKeyFromJWK(k.N, k.E)
+		if err != nil {
+			return fmt.Errorf("parse key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = pub
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.fetched = time.Now()
+	v.mu.Unlock()
+	return nil
+}
+
+// rsaPublicKeyFromJWK decodes the base64url-encoded modulus (n) and exponent
+// (e) fields of an RSA JWK into an *rsa.PublicKey.
+func rsaPublicKeyFromJWK(nB64, eB64 string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(nB64)
+	if err != nil {
+		return nil, fmt.Errorf("decode modulus: %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(eB64)
+	if err != nil {
+		return nil, fmt.Errorf("decode exponent: %w", err)
+	}
+
+	e := new(big.Int).SetBytes(eBytes)
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(e.Int64()),
+	}, nil
+}
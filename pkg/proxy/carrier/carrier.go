@@ -0,0 +1,167 @@
+// Package carrier implements the client half of "ssh -o ProxyCommand bridge
+// ssh-proxy ...": it dials a WSServer's /ssh endpoint and exposes it as a
+// plain byte stream, the way cloudflared's carrier lets SSH tunnel over a
+// WebSocket edge without a local TCP listener.
+package carrier
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/vercel-eddie/bridge/pkg/bidi"
+	"github.com/vercel-eddie/bridge/pkg/proxy"
+)
+
+// headerProtectionBypass is the Vercel preview-deployment protection bypass
+// header, forwarded to the tunnel server the same way curl/kubectl would
+// when reaching a protected deployment directly.
+const headerProtectionBypass = "x-vercel-protection-bypass"
+
+// Config configures a carrier dial.
+type Config struct {
+	// TunnelURL is the ws(s):// or http(s):// URL of the tunnel server's
+	// /ssh endpoint.
+	TunnelURL string
+
+	// ProtectionBypassSecret, when set, is sent as the
+	// x-vercel-protection-bypass header on every dial.
+	ProtectionBypassSecret string
+
+	// Headers carries arbitrary extra headers (e.g. from repeated -H flags)
+	// onto every dial.
+	Headers http.Header
+
+	// Backoff controls the delay between dial retries. Zero value uses
+	// proxy.BackoffConfig's own defaults.
+	Backoff proxy.BackoffConfig
+
+	// Logger carries fixed contextual attributes onto every log line the
+	// carrier emits. Defaults to slog.Default() when nil.
+	Logger *slog.Logger
+}
+
+func (cfg Config) logger() *slog.Logger {
+	if cfg.Logger != nil {
+		return cfg.Logger
+	}
+	return slog.Default()
+}
+
+func (cfg Config) dialHeader() http.Header {
+	header := http.Header{}
+	for k, vs := range cfg.Headers {
+		for _, v := range vs {
+			header.Add(k, v)
+		}
+	}
+	if cfg.ProtectionBypassSecret != "" {
+		header.Set(headerProtectionBypass, cfg.ProtectionBypassSecret)
+	}
+	return header
+}
+
+// RunStdio dials cfg.TunnelURL and pipes os.Stdin/os.Stdout through it until
+// either side closes or ctx is canceled, the "ProxyCommand" variant: ssh
+// already owns the TCP connection to us (its own stdin/stdout pipes), so we
+// just need to carry bytes between them and the WebSocket.
+func RunStdio(ctx context.Context, cfg Config) error {
+	conn, err := dialWithRetry(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("dial tunnel: %w", err)
+	}
+
+	cfg.logger().Info("carrier: tunnel established, piping stdio", "url", cfg.TunnelURL)
+	return bidi.New(stdio{}, conn).Wait(ctx)
+}
+
+// RunListener is the "StartServer" variant: it listens on listenAddr and,
+// for each accepted TCP connection, dials a fresh WebSocket to
+// cfg.TunnelURL and bidi-pipes the two together, so e.g. a plain `ssh -p
+// 2222 localhost` works without any ProxyCommand configuration.
+func RunListener(ctx context.Context, cfg Config, listenAddr string) error {
+	logger := cfg.logger()
+
+	lc := net.ListenConfig{}
+	ln, err := lc.Listen(ctx, "tcp", listenAddr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", listenAddr, err)
+	}
+	defer ln.Close()
+
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	logger.Info("carrier: listening", "addr", ln.Addr().String())
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("accept: %w", err)
+		}
+		go serveListenerConn(ctx, cfg, conn)
+	}
+}
+
+func serveListenerConn(ctx context.Context, cfg Config, conn net.Conn) {
+	logger := cfg.logger().With("remote_addr", conn.RemoteAddr().String())
+	defer conn.Close()
+
+	tunnel, err := dialWithRetry(ctx, cfg)
+	if err != nil {
+		logger.Error("carrier: failed to dial tunnel for accepted connection", "error", err)
+		return
+	}
+
+	logger.Debug("carrier: tunnel established for accepted connection")
+	if err := bidi.New(conn, tunnel).Wait(ctx); err != nil && ctx.Err() == nil {
+		logger.Debug("carrier: tunnel closed", "error", err)
+	}
+}
+
+// dialWithRetry dials cfg.TunnelURL, retrying transient failures with
+// backoff until it succeeds or ctx is canceled.
+func dialWithRetry(ctx context.Context, cfg Config) (io.ReadWriteCloser, error) {
+	dialer := proxy.NewWSDialerFromConfig(proxy.WSDialerConfig{
+		TunnelURL: cfg.TunnelURL,
+		Logger:    cfg.logger(),
+	})
+	backoff := cfg.Backoff.WithDefaults()
+	header := cfg.dialHeader()
+
+	for attempt := 0; ; attempt++ {
+		conn, _, err := dialer.DialWithHeader(ctx, header)
+		if err == nil {
+			return conn, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		cfg.logger().Debug("carrier: dial failed, retrying", "attempt", attempt+1, "error", err)
+		select {
+		case <-time.After(backoff.Next(attempt)):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// stdio adapts os.Stdin/os.Stdout to an io.ReadWriteCloser. Close only
+// closes Stdin: ssh itself owns the process lifetime, and closing Stdout
+// would just make our own log lines fail.
+type stdio struct{}
+
+func (stdio) Read(p []byte) (int, error)  { return os.Stdin.Read(p) }
+func (stdio) Write(p []byte) (int, error) { return os.Stdout.Write(p) }
+func (stdio) Close() error                { return os.Stdin.Close() }
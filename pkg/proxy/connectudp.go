@@ -0,0 +1,138 @@
+package proxy
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+)
+
+// handleConnectUDP implements CONNECT-UDP (RFC 9298-style): a client sends
+// "CONNECT host:port HTTP/1.1" with "Upgrade: connect-udp" and, once
+// hijacked, the connection carries UDP datagrams framed with
+// writeUDPFrame/readUDPFrame instead of a raw TCP byte stream.
+//
+// Unlike handleConnect, which hands the hijacked connection off through
+// Conns() for an external consumer to dial and pipe, the server dials the
+// destination UDP socket itself and shuttles datagrams in both directions:
+// there's no stream-oriented bidi.Pipe equivalent for datagrams, and the
+// destination pod is always reachable directly from where Server runs.
+func (p *Server) handleConnectUDP(w http.ResponseWriter, r *http.Request) {
+	p.metrics.ConnectsTotal.Inc()
+
+	select {
+	case <-p.draining:
+		p.metrics.ConnectsDropped.WithLabelValues("draining").Inc()
+		http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+		return
+	default:
+	}
+
+	if p.authToken != "" && !validProxyAuth(r, p.authToken) {
+		p.metrics.ConnectsDropped.WithLabelValues("auth").Inc()
+		w.Header().Set("Proxy-Authenticate", `Bearer realm="bridge"`)
+		http.Error(w, "Proxy authentication required", http.StatusProxyAuthRequired)
+		return
+	}
+
+	requested, err := p.evaluateDestination(r.Host)
+	if err != nil {
+		p.metrics.ConnectsDropped.WithLabelValues("policy").Inc()
+		slog.Warn("CONNECT-UDP rejected by policy", "remote", r.RemoteAddr, "host", r.Host, "error", err, "reason", "policy")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	waitCtx, cancel := context.WithTimeout(r.Context(), p.maxQueueWait)
+	defer cancel()
+	select {
+	case p.sem <- struct{}{}:
+	case <-waitCtx.Done():
+		p.metrics.ConnectsDropped.WithLabelValues("queue_full").Inc()
+		slog.Warn("dropping CONNECT-UDP, proxy at max concurrency", "remote", r.RemoteAddr, "reason", "queue_full", "max_concurrent", cap(p.sem))
+		http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	udpConn, err := net.Dial("udp", requested)
+	if err != nil {
+		<-p.sem
+		slog.Error("failed to dial udp destination", "error", err, "destination", requested)
+		http.Error(w, "Bad gateway", http.StatusBadGateway)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		<-p.sem
+		udpConn.Close()
+		http.Error(w, "Hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	tunnelConn, _, err := hijacker.Hijack()
+	if err != nil {
+		<-p.sem
+		udpConn.Close()
+		p.metrics.HijackErrorsTotal.Inc()
+		slog.Error("failed to hijack connect-udp connection", "error", err)
+		return
+	}
+
+	slog.Debug("connect-udp request", "remote", r.RemoteAddr, "destination", requested)
+
+	p.metrics.ActiveConnections.Inc()
+	p.tracked.Add(1)
+	go p.pumpUDPTunnel(tunnelConn, udpConn, requested)
+}
+
+// pumpUDPTunnel shuttles datagrams between a hijacked CONNECT-UDP connection
+// and the UDP socket dialed for it until either side closes, then releases
+// this flow's concurrency slot and tracked-connection count.
+func (p *Server) pumpUDPTunnel(tunnelConn, udpConn net.Conn, destination string) {
+	defer func() {
+		tunnelConn.Close()
+		udpConn.Close()
+		p.metrics.ActiveConnections.Dec()
+		<-p.sem
+		p.tracked.Done()
+	}()
+
+	done := make(chan struct{}, 2)
+
+	go func() {
+		defer func() { done <- struct{}{} }()
+		buf := make([]byte, maxUDPFrameSize)
+		for {
+			n, err := readUDPFrame(tunnelConn, buf)
+			if err != nil {
+				return
+			}
+			p.metrics.BytesIn.Add(float64(n))
+			if _, err := udpConn.Write(buf[:n]); err != nil {
+				slog.Debug("failed to write to udp destination", "error", err, "destination", destination)
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer func() { done <- struct{}{} }()
+		buf := make([]byte, maxUDPFrameSize)
+		for {
+			n, err := udpConn.Read(buf)
+			if err != nil {
+				return
+			}
+			p.metrics.BytesOut.Add(float64(n))
+			if err := writeUDPFrame(tunnelConn, buf[:n]); err != nil {
+				slog.Debug("failed to write udp frame to tunnel", "error", err, "destination", destination)
+				return
+			}
+		}
+	}()
+
+	<-done
+}
@@ -0,0 +1,73 @@
+package proxy
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the Prometheus instrumentation for a Server. Use NewMetrics
+// to build one with its own registry, or share a *prometheus.Registry
+// across several servers by registering the collectors yourself and passing
+// them through Config.Metrics.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	ConnectsTotal     prometheus.Counter
+	ConnectsDropped   *prometheus.CounterVec // labeled by reason: queue_full, policy, auth, draining
+	ActiveConnections prometheus.Gauge
+	HijackErrorsTotal prometheus.Counter
+	BytesIn           prometheus.Counter
+	BytesOut          prometheus.Counter
+}
+
+// NewMetrics builds a Metrics with a private registry, so multiple Server
+// instances in the same process (e.g. in tests) don't collide on metric
+// names.
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		ConnectsTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "bridge_proxy_connects_total",
+			Help: "Total CONNECT requests received.",
+		}),
+		ConnectsDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bridge_proxy_connects_dropped_total",
+			Help: "CONNECT requests dropped before being handed off, by reason.",
+		}, []string{"reason"}),
+		ActiveConnections: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "bridge_proxy_active_connections",
+			Help: "Hijacked connections currently handed off and not yet closed.",
+		}),
+		HijackErrorsTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "bridge_proxy_hijack_errors_total",
+			Help: "Failures to hijack an accepted CONNECT request.",
+		}),
+		BytesIn: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "bridge_proxy_bytes_in_total",
+			Help: "Bytes read from hijacked connections.",
+		}),
+		BytesOut: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "bridge_proxy_bytes_out_total",
+			Help: "Bytes written to hijacked connections.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		m.ConnectsTotal,
+		m.ConnectsDropped,
+		m.ActiveConnections,
+		m.HijackErrorsTotal,
+		m.BytesIn,
+		m.BytesOut,
+	)
+
+	return m
+}
+
+// Handler returns an http.Handler serving these metrics in the Prometheus
+// text exposition format, mountable alongside /health.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
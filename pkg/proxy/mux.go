@@ -0,0 +1,111 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/hashicorp/yamux"
+)
+
+// MuxConfig configures a yamux session shared by MuxDialer and WSServer's
+// mux endpoint.
+type MuxConfig struct {
+	// KeepAliveInterval is how often the session pings its peer to detect a
+	// connection that's gone dead silently (e.g. through a proxy that drops
+	// idle TCP without a FIN). Defaults to yamux's own default (30s) when
+	// zero.
+	KeepAliveInterval time.Duration
+
+	// StreamWindowSize caps how much unacknowledged data a single stream may
+	// have in flight before its Write blocks, the same credit-based idea as
+	// tunnel.Conn's send window but enforced by yamux here. Defaults to
+	// yamux's own default (256KB) when zero.
+	StreamWindowSize uint32
+}
+
+func (cfg MuxConfig) yamuxConfig() *yamux.Config {
+	c := yamux.DefaultConfig()
+	c.EnableKeepAlive = true
+	if cfg.KeepAliveInterval > 0 {
+		c.KeepAliveInterval = cfg.KeepAliveInterval
+	}
+	if cfg.StreamWindowSize > 0 {
+		c.MaxStreamWindowSize = cfg.StreamWindowSize
+	}
+	return c
+}
+
+// MuxDialer multiplexes many logical connections over a single underlying
+// transport (typically a WSDialer's WebSocket connection), so callers pay
+// one WS handshake - and one CONNECT round trip through any intermediate
+// proxy - instead of one per logical connection.
+type MuxDialer struct {
+	session *yamux.Session
+}
+
+// NewMuxDialer dials the underlying transport once via dialer and
+// establishes a yamux client session on top of it.
+func NewMuxDialer(ctx context.Context, dialer Dialer, cfg MuxConfig) (*MuxDialer, error) {
+	conn, err := dialer.Dial(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("dial transport: %w", err)
+	}
+
+	session, err := yamux.Client(conn, cfg.yamuxConfig())
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("establish yamux session: %w", err)
+	}
+
+	return &MuxDialer{session: session}, nil
+}
+
+// Open opens a new logical stream, unblocking early with ctx's error if it's
+// canceled before the stream is established.
+func (m *MuxDialer) Open(ctx context.Context) (net.Conn, error) {
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+	resCh := make(chan result, 1)
+	go func() {
+		conn, err := m.session.OpenStream()
+		resCh <- result{conn, err}
+	}()
+
+	select {
+	case res := <-resCh:
+		return res.conn, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Accept waits for the peer to open the next logical stream.
+func (m *MuxDialer) Accept() (net.Conn, error) {
+	return m.session.Accept()
+}
+
+// DialTunnel opens a new stream and stamps it with connectionKey, the
+// multiplexed equivalent of a client registering on /tunnel with a fresh
+// WebSocket: the server side of a multiplex=true registration reads the
+// header (see readMuxStreamHeader) to pair the stream with the matching
+// pending client instead of requiring a whole new WS handshake per dial.
+func (m *MuxDialer) DialTunnel(ctx context.Context, connectionKey string) (net.Conn, error) {
+	stream, err := m.Open(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("open stream: %w", err)
+	}
+	if err := writeMuxStreamHeader(stream, connectionKey); err != nil {
+		stream.Close()
+		return nil, fmt.Errorf("write connection key header: %w", err)
+	}
+	return stream, nil
+}
+
+// Close tears down the underlying transport along with every open stream.
+func (m *MuxDialer) Close() error {
+	return m.session.Close()
+}
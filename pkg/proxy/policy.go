@@ -0,0 +1,150 @@
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"sync/atomic"
+)
+
+// PolicyAction is the disposition a Rule assigns to a matching CONNECT
+// request.
+type PolicyAction string
+
+const (
+	ActionAllow     PolicyAction = "allow"
+	ActionDeny      PolicyAction = "deny"
+	ActionRewriteTo PolicyAction = "rewrite-to"
+)
+
+// Rule is one entry in an ordered Policy. Policy.Evaluate stops at the first
+// Rule whose CIDR, Host, and port bounds all match (fields left zero match
+// anything).
+type Rule struct {
+	// CIDR, set, matches when the requested host is a literal IP inside
+	// this network.
+	CIDR string
+	// Host, if set, matches the requested hostname against a glob pattern
+	// (path.Match syntax, e.g. "*.internal.example.com").
+	Host string
+	// PortMin/PortMax bound the requested port, inclusive. Zero PortMin
+	// means "any port"; a zero PortMax with a non-zero PortMin means
+	// "exactly PortMin".
+	PortMin int
+	PortMax int
+	// Action is what happens to a matching request.
+	Action PolicyAction
+	// RewriteTo is the replacement "host:port" used when Action is
+	// ActionRewriteTo.
+	RewriteTo string
+}
+
+func (r Rule) matches(host string, port int) bool {
+	if r.CIDR != "" {
+		_, network, err := net.ParseCIDR(r.CIDR)
+		if err != nil {
+			return false
+		}
+		ip := net.ParseIP(host)
+		if ip == nil || !network.Contains(ip) {
+			return false
+		}
+	}
+	if r.Host != "" {
+		if ok, err := path.Match(r.Host, host); err != nil || !ok {
+			return false
+		}
+	}
+	if r.PortMin != 0 {
+		max := r.PortMax
+		if max == 0 {
+			max = r.PortMin
+		}
+		if port < r.PortMin || port > max {
+			return false
+		}
+	}
+	return true
+}
+
+// Policy is an ordered allow/deny/rewrite list evaluated against each
+// CONNECT request's requested destination.
+type Policy struct {
+	rules []Rule
+	// DefaultAllow controls what Evaluate does when no rule matches. It
+	// defaults to false (deny), since a configured Policy is an ACL and a
+	// forgotten catch-all rule should fail closed, not silently allow
+	// whatever the rules don't happen to cover. Set it to true to opt into
+	// the historical "default-allow" behavior (e.g. for a Policy whose rules
+	// only carve out exceptions to deny).
+	DefaultAllow bool
+}
+
+// NewPolicy builds a Policy from an ordered rule list; earlier rules take
+// precedence. A request matching no rule is denied; set DefaultAllow on the
+// returned Policy to change that.
+func NewPolicy(rules []Rule) *Policy {
+	return &Policy{rules: append([]Rule(nil), rules...)}
+}
+
+// Evaluate returns the destination a CONNECT request to host:port should
+// actually use, or an error if it's denied. A nil Policy (including a
+// PolicyStore that was never given one) allows every destination unchanged,
+// so callers that don't configure a policy keep today's blind-forward
+// behavior. A non-nil Policy whose rules don't match the request denies it,
+// unless DefaultAllow is set.
+func (p *Policy) Evaluate(host string, port int) (string, error) {
+	requested := net.JoinHostPort(host, fmt.Sprintf("%d", port))
+	if p == nil {
+		return requested, nil
+	}
+
+	for _, r := range p.rules {
+		if !r.matches(host, port) {
+			continue
+		}
+		switch r.Action {
+		case ActionAllow, "":
+			return requested, nil
+		case ActionDeny:
+			return "", fmt.Errorf("proxy: destination %s denied by policy", requested)
+		case ActionRewriteTo:
+			return r.RewriteTo, nil
+		default:
+			return "", fmt.Errorf("proxy: policy rule has unknown action %q", r.Action)
+		}
+	}
+	if p.DefaultAllow {
+		return requested, nil
+	}
+	return "", fmt.Errorf("proxy: destination %s denied by policy: no rule matched", requested)
+}
+
+// PolicyStore holds a Policy that can be swapped atomically while a Server
+// is running, so e.g. the administrator service can push updated ACLs
+// without restarting proxies.
+type PolicyStore struct {
+	policy atomic.Pointer[Policy]
+}
+
+// NewPolicyStore creates a PolicyStore seeded with initial, which may be nil
+// to start out allowing everything.
+func NewPolicyStore(initial *Policy) *PolicyStore {
+	s := &PolicyStore{}
+	s.policy.Store(initial)
+	return s
+}
+
+// Load returns the current Policy. Safe to call on a nil *PolicyStore,
+// returning nil (allow everything) in that case.
+func (s *PolicyStore) Load() *Policy {
+	if s == nil {
+		return nil
+	}
+	return s.policy.Load()
+}
+
+// Store atomically replaces the current Policy.
+func (s *PolicyStore) Store(p *Policy) {
+	s.policy.Store(p)
+}
@@ -0,0 +1,127 @@
+package proxy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPolicyEvaluateNilPolicyAllowsEverything(t *testing.T) {
+	var p *Policy
+
+	got, err := p.Evaluate("example.com", 443)
+	if err != nil {
+		t.Fatalf("Evaluate() error: %v", err)
+	}
+	if want := "example.com:443"; got != want {
+		t.Errorf("Evaluate() = %q, want %q", got, want)
+	}
+}
+
+func TestPolicyEvaluateMatchingAllowRule(t *testing.T) {
+	p := NewPolicy([]Rule{{Host: "*.internal.example.com", Action: ActionAllow}})
+
+	got, err := p.Evaluate("api.internal.example.com", 443)
+	if err != nil {
+		t.Fatalf("Evaluate() error: %v", err)
+	}
+	if want := "api.internal.example.com:443"; got != want {
+		t.Errorf("Evaluate() = %q, want %q", got, want)
+	}
+}
+
+func TestPolicyEvaluateMatchingDenyRule(t *testing.T) {
+	p := NewPolicy([]Rule{{Host: "*.blocked.example.com", Action: ActionDeny}})
+
+	_, err := p.Evaluate("evil.blocked.example.com", 443)
+	if err == nil {
+		t.Fatal("Evaluate() error = nil, want denial")
+	}
+}
+
+func TestPolicyEvaluateRewriteRule(t *testing.T) {
+	p := NewPolicy([]Rule{{Host: "legacy.example.com", Action: ActionRewriteTo, RewriteTo: "new.example.com:8443"}})
+
+	got, err := p.Evaluate("legacy.example.com", 443)
+	if err != nil {
+		t.Fatalf("Evaluate() error: %v", err)
+	}
+	if want := "new.example.com:8443"; got != want {
+		t.Errorf("Evaluate() = %q, want %q", got, want)
+	}
+}
+
+func TestPolicyEvaluateNoMatchDeniesByDefault(t *testing.T) {
+	p := NewPolicy([]Rule{{Host: "*.internal.example.com", Action: ActionAllow}})
+
+	_, err := p.Evaluate("public.example.com", 443)
+	if err == nil {
+		t.Fatal("Evaluate() error = nil, want a configured Policy to deny a request no rule matched")
+	}
+	if !strings.Contains(err.Error(), "no rule matched") {
+		t.Errorf("Evaluate() error = %q, want it to mention no rule matched", err)
+	}
+}
+
+func TestPolicyEvaluateNoMatchAllowsWithDefaultAllowOptIn(t *testing.T) {
+	p := NewPolicy([]Rule{{Host: "*.blocked.example.com", Action: ActionDeny}})
+	p.DefaultAllow = true
+
+	got, err := p.Evaluate("public.example.com", 443)
+	if err != nil {
+		t.Fatalf("Evaluate() error: %v", err)
+	}
+	if want := "public.example.com:443"; got != want {
+		t.Errorf("Evaluate() = %q, want %q", got, want)
+	}
+}
+
+func TestPolicyEvaluateEarlierRuleTakesPrecedence(t *testing.T) {
+	p := NewPolicy([]Rule{
+		{Host: "api.example.com", Action: ActionAllow},
+		{Host: "*.example.com", Action: ActionDeny},
+	})
+
+	got, err := p.Evaluate("api.example.com", 443)
+	if err != nil {
+		t.Fatalf("Evaluate() error: %v", err)
+	}
+	if want := "api.example.com:443"; got != want {
+		t.Errorf("Evaluate() = %q, want %q", got, want)
+	}
+}
+
+func TestRuleMatchesCIDR(t *testing.T) {
+	r := Rule{CIDR: "10.0.0.0/8", Action: ActionAllow}
+
+	if !r.matches("10.1.2.3", 80) {
+		t.Error("matches() = false, want true for an IP inside the CIDR")
+	}
+	if r.matches("192.168.1.1", 80) {
+		t.Error("matches() = true, want false for an IP outside the CIDR")
+	}
+	if r.matches("example.com", 80) {
+		t.Error("matches() = true, want false for a hostname when CIDR is set")
+	}
+}
+
+func TestRuleMatchesPortRange(t *testing.T) {
+	r := Rule{PortMin: 8000, PortMax: 9000, Action: ActionAllow}
+
+	if !r.matches("example.com", 8080) {
+		t.Error("matches() = false, want true for a port inside the range")
+	}
+	if r.matches("example.com", 80) {
+		t.Error("matches() = true, want false for a port outside the range")
+	}
+}
+
+func TestRuleMatchesExactPort(t *testing.T) {
+	r := Rule{PortMin: 443, Action: ActionAllow}
+
+	if !r.matches("example.com", 443) {
+		t.Error("matches() = false, want true for the exact port")
+	}
+	if r.matches("example.com", 80) {
+		t.Error("matches() = true, want false for a different port")
+	}
+}
@@ -0,0 +1,192 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Kubernetes' portforward.k8s.io/v1 streamed sub-protocol prefixes every
+// message with a channel byte identifying which logical stream it belongs
+// to: 0 for the data stream, 1 for the error stream. We reuse that framing
+// since a single WebSocket only carries one "connection" per /portforward
+// request (unlike the SPDY version, which multiplexes several ports over
+// one connection).
+const (
+	streamTypeData  byte = 0
+	streamTypeError byte = 1
+)
+
+var portForwardUpgrader = websocket.Upgrader{
+	ReadBufferSize:  32 * 1024,
+	WriteBufferSize: 32 * 1024,
+	CheckOrigin: func(r *http.Request) bool {
+		return true // Allow all origins, matching WSServer's tunnel upgrader.
+	},
+}
+
+// handlePortForward implements a kubectl-style streamed port-forward: a
+// client opens one WebSocket to /portforward?port=N and the server hands the
+// demultiplexed data stream off through Conns() like any other Conn, so the
+// same consumer loop that dials Requested and pipes it with bidi.Pipe for a
+// CONNECT tunnel can do the same here. Conn.WriteError lets that consumer
+// report a dial failure back to the client on the error stream instead of
+// just closing the socket.
+func (p *Server) handlePortForward(w http.ResponseWriter, r *http.Request) {
+	portStr := r.URL.Query().Get("port")
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 {
+		http.Error(w, fmt.Sprintf("invalid port %q", portStr), http.StatusBadRequest)
+		return
+	}
+
+	requested, err := p.evaluateDestination(net.JoinHostPort(p.targetHost(), portStr))
+	if err != nil {
+		p.metrics.ConnectsDropped.WithLabelValues("policy").Inc()
+		slog.Warn("port-forward rejected by policy", "remote", r.RemoteAddr, "port", port, "error", err)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	select {
+	case <-p.draining:
+		p.metrics.ConnectsDropped.WithLabelValues("draining").Inc()
+		http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+		return
+	default:
+	}
+
+	waitCtx, cancel := context.WithTimeout(r.Context(), p.maxQueueWait)
+	defer cancel()
+	select {
+	case p.sem <- struct{}{}:
+	case <-waitCtx.Done():
+		p.metrics.ConnectsDropped.WithLabelValues("queue_full").Inc()
+		slog.Warn("dropping port-forward request, proxy at max concurrency", "remote", r.RemoteAddr, "port", port)
+		http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	wsConn, err := portForwardUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		<-p.sem
+		p.metrics.HijackErrorsTotal.Inc()
+		slog.Error("failed to upgrade port-forward websocket", "error", err, "remote", r.RemoteAddr)
+		return
+	}
+
+	p.metrics.ConnectsTotal.Inc()
+	p.metrics.ActiveConnections.Inc()
+	p.tracked.Add(1)
+
+	pf := &portForwardConn{conn: wsConn, metrics: p.metrics, sem: p.sem, tracked: &p.tracked}
+
+	select {
+	case p.connCh <- Conn{Net: pf, Requested: requested, Port: port, WriteError: pf.writeError}:
+	default:
+		p.metrics.ConnectsDropped.WithLabelValues("queue_full").Inc()
+		slog.Warn("connection channel full, dropping port-forward connection", "remote", r.RemoteAddr, "port", port)
+		pf.Close()
+	}
+}
+
+// targetHost returns the host portion of the server's configured target,
+// used with a port-forward request's own port instead of the target's.
+func (p *Server) targetHost() string {
+	host, _, err := net.SplitHostPort(p.target)
+	if err != nil {
+		return p.target
+	}
+	return host
+}
+
+// portForwardConn adapts a /portforward WebSocket to net.Conn, demultiplexing
+// the leading channel byte on Read (keeping only streamTypeData) and
+// prefixing Writes with it. writeError sends on the error stream instead.
+type portForwardConn struct {
+	conn    *websocket.Conn
+	metrics *Metrics
+	sem     chan struct{}
+	tracked *sync.WaitGroup
+
+	closeOnce sync.Once
+	readMu    sync.Mutex
+	buf       []byte
+	writeMu   sync.Mutex
+}
+
+func (c *portForwardConn) Read(b []byte) (int, error) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+
+	for len(c.buf) == 0 {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return 0, err
+		}
+		if len(data) == 0 || data[0] != streamTypeData {
+			continue
+		}
+		c.buf = data[1:]
+	}
+
+	n := copy(b, c.buf)
+	c.buf = c.buf[n:]
+	c.metrics.BytesIn.Add(float64(n))
+	return n, nil
+}
+
+func (c *portForwardConn) Write(b []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	framed := make([]byte, 0, len(b)+1)
+	framed = append(framed, streamTypeData)
+	framed = append(framed, b...)
+	if err := c.conn.WriteMessage(websocket.BinaryMessage, framed); err != nil {
+		return 0, err
+	}
+	c.metrics.BytesOut.Add(float64(len(b)))
+	return len(b), nil
+}
+
+// writeError sends msg to the client on the error stream.
+func (c *portForwardConn) writeError(msg string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	framed := append([]byte{streamTypeError}, []byte(msg)...)
+	return c.conn.WriteMessage(websocket.BinaryMessage, framed)
+}
+
+func (c *portForwardConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		err = c.conn.Close()
+		c.metrics.ActiveConnections.Dec()
+		<-c.sem
+		c.tracked.Done()
+	})
+	return err
+}
+
+func (c *portForwardConn) LocalAddr() net.Addr  { return c.conn.LocalAddr() }
+func (c *portForwardConn) RemoteAddr() net.Addr { return c.conn.RemoteAddr() }
+
+func (c *portForwardConn) SetDeadline(t time.Time) error {
+	if err := c.conn.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.conn.SetWriteDeadline(t)
+}
+
+func (c *portForwardConn) SetReadDeadline(t time.Time) error { return c.conn.SetReadDeadline(t) }
+
+func (c *portForwardConn) SetWriteDeadline(t time.Time) error { return c.conn.SetWriteDeadline(t) }
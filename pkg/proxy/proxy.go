@@ -1,26 +1,56 @@
 package proxy
 
 import (
+	"bytes"
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
-// Conn represents a hijacked HTTP CONNECT connection.
+// Conn represents a hijacked HTTP CONNECT connection, or a demultiplexed
+// data stream from the streamed port-forward entrypoint (see
+// handlePortForward).
 type Conn struct {
 	Net net.Conn
+	// Requested is the "host:port" the client actually asked for, after
+	// policy evaluation (rewritten if a rewrite-to rule matched). Downstream
+	// tunnel consumers should dial this instead of a static target so
+	// per-connection destinations work.
+	Requested string
+	// Port is the destination port requested via /portforward?port=N. Zero
+	// for Conns that arrived via CONNECT, where the port is already part of
+	// Requested.
+	Port int
+	// WriteError, if non-nil, sends msg back to the client on a side-channel
+	// error stream instead of just closing Net, so a consumer that fails to
+	// dial Requested can say why. CONNECT-derived Conns leave this nil,
+	// since CONNECT has no error stream of its own.
+	WriteError func(msg string) error
 }
 
 // Server is an HTTP CONNECT proxy that forwards all connections to a configured target.
 type Server struct {
-	httpServer *http.Server
-	addr       string
-	target     string
-	name       string
-	connCh     chan Conn
+	httpServer    *http.Server
+	addr          string
+	target        string
+	name          string
+	authToken     string
+	proxyProtocol bool
+	policy        *PolicyStore
+	maxQueueWait  time.Duration
+	sem           chan struct{}
+	metrics       *Metrics
+	connCh        chan Conn
+	tracked       sync.WaitGroup
+	draining      chan struct{}
+	shutdownOnce  sync.Once
 }
 
 // Config configures the proxy server.
@@ -29,6 +59,35 @@ type Config struct {
 	Port   int
 	Target string // Target address to forward all connections to
 	Name   string // Name of the sandbox (returned in x-bridge-name header)
+
+	// AuthToken, if set, requires CONNECT requests to carry a matching
+	// "Proxy-Authorization: Bearer <token>" header. Requests without a
+	// matching token get a 407 and are never hijacked.
+	AuthToken string
+
+	// ProxyProtocol prepends a PROXY protocol v2 header (see
+	// writeProxyProtocolV2) to each accepted connection before handing it
+	// off via Conns: the first bytes read from Conn.Net are the header,
+	// carrying the CONNECT client's address, so whatever the consumer pipes
+	// them to sees that address instead of the proxy's own.
+	ProxyProtocol bool
+
+	// Policy, if set, decides whether each CONNECT request's host:port is
+	// allowed, denied, or rewritten (see Policy). A nil Policy allows every
+	// destination, matching the previous blind-forward behavior. Wrap it in
+	// a PolicyStore so rules can be hot-reloaded without restarting the
+	// server.
+	Policy *PolicyStore
+
+	// MaxConcurrent bounds how many hijacked connections can be handed off
+	// via Conns without having been closed yet (default 100).
+	MaxConcurrent int
+	// MaxQueueWait bounds how long a CONNECT request waits for a free
+	// concurrency slot before being dropped with a 503 (default 5s).
+	MaxQueueWait time.Duration
+	// Metrics receives this server's Prometheus instrumentation. Defaults to
+	// a fresh Metrics with its own registry if nil.
+	Metrics *Metrics
 }
 
 // New creates a new HTTP CONNECT proxy server.
@@ -39,14 +98,30 @@ func New(cfg Config) *Server {
 	if cfg.Port == 0 {
 		cfg.Port = 3000
 	}
+	if cfg.MaxConcurrent == 0 {
+		cfg.MaxConcurrent = 100
+	}
+	if cfg.MaxQueueWait == 0 {
+		cfg.MaxQueueWait = 5 * time.Second
+	}
+	if cfg.Metrics == nil {
+		cfg.Metrics = NewMetrics()
+	}
 
 	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
 
 	p := &Server{
-		addr:   addr,
-		target: cfg.Target,
-		name:   cfg.Name,
-		connCh: make(chan Conn, 100),
+		addr:          addr,
+		target:        cfg.Target,
+		name:          cfg.Name,
+		authToken:     cfg.AuthToken,
+		proxyProtocol: cfg.ProxyProtocol,
+		policy:        cfg.Policy,
+		maxQueueWait:  cfg.MaxQueueWait,
+		sem:           make(chan struct{}, cfg.MaxConcurrent),
+		metrics:       cfg.Metrics,
+		connCh:        make(chan Conn, cfg.MaxConcurrent),
+		draining:      make(chan struct{}),
 	}
 
 	p.httpServer = &http.Server{
@@ -69,8 +144,17 @@ func (p *Server) Target() string {
 	return p.target
 }
 
+// Metrics returns this server's Prometheus instrumentation.
+func (p *Server) Metrics() *Metrics {
+	return p.metrics
+}
+
 func (p *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodConnect {
+		if r.Header.Get("Upgrade") == "connect-udp" {
+			p.handleConnectUDP(w, r)
+			return
+		}
 		p.handleConnect(w, r)
 		return
 	}
@@ -81,12 +165,62 @@ func (p *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.URL.Path == "/metrics" {
+		p.metrics.Handler().ServeHTTP(w, r)
+		return
+	}
+
+	if r.URL.Path == "/portforward" {
+		p.handlePortForward(w, r)
+		return
+	}
+
 	http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 }
 
 func (p *Server) handleConnect(w http.ResponseWriter, r *http.Request) {
+	p.metrics.ConnectsTotal.Inc()
+
+	select {
+	case <-p.draining:
+		p.metrics.ConnectsDropped.WithLabelValues("draining").Inc()
+		http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+		return
+	default:
+	}
+
+	if p.authToken != "" && !validProxyAuth(r, p.authToken) {
+		p.metrics.ConnectsDropped.WithLabelValues("auth").Inc()
+		w.Header().Set("Proxy-Authenticate", `Bearer realm="bridge"`)
+		http.Error(w, "Proxy authentication required", http.StatusProxyAuthRequired)
+		return
+	}
+
+	requested, err := p.evaluateDestination(r.Host)
+	if err != nil {
+		p.metrics.ConnectsDropped.WithLabelValues("policy").Inc()
+		slog.Warn("CONNECT rejected by policy", "remote", r.RemoteAddr, "host", r.Host, "error", err, "reason", "policy")
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	// Bound how many connections can be in flight at once: wait up to
+	// MaxQueueWait for a free slot, then drop rather than hijack a
+	// connection nothing will ever drain.
+	waitCtx, cancel := context.WithTimeout(r.Context(), p.maxQueueWait)
+	defer cancel()
+	select {
+	case p.sem <- struct{}{}:
+	case <-waitCtx.Done():
+		p.metrics.ConnectsDropped.WithLabelValues("queue_full").Inc()
+		slog.Warn("dropping CONNECT, proxy at max concurrency", "remote", r.RemoteAddr, "reason", "queue_full", "max_concurrent", cap(p.sem))
+		http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
 	hijacker, ok := w.(http.Hijacker)
 	if !ok {
+		<-p.sem
 		http.Error(w, "Hijacking not supported", http.StatusInternalServerError)
 		return
 	}
@@ -98,34 +232,145 @@ func (p *Server) handleConnect(w http.ResponseWriter, r *http.Request) {
 
 	conn, _, err := hijacker.Hijack()
 	if err != nil {
+		<-p.sem
+		p.metrics.HijackErrorsTotal.Inc()
 		slog.Error("failed to hijack connection", "error", err)
 		return
 	}
 
 	slog.Debug("http connect request", "remote", r.RemoteAddr, "target", p.target)
 
+	if p.proxyProtocol {
+		var header bytes.Buffer
+		if err := writeProxyProtocolV2(&header, conn.RemoteAddr(), conn.LocalAddr()); err != nil {
+			slog.Error("failed to write PROXY protocol v2 header", "error", err, "remote", r.RemoteAddr)
+			conn.Close()
+			<-p.sem
+			return
+		}
+		conn = &prefixedConn{Conn: conn, prefix: header.Bytes()}
+	}
+
+	p.metrics.ActiveConnections.Inc()
+	p.tracked.Add(1)
+	wrapped := &instrumentedConn{Conn: conn, metrics: p.metrics, sem: p.sem, tracked: &p.tracked}
+
 	select {
-	case p.connCh <- Conn{Net: conn}:
+	case p.connCh <- Conn{Net: wrapped, Requested: requested}:
 	default:
-		slog.Warn("connection channel full, dropping connection", "remote", r.RemoteAddr)
-		conn.Close()
+		// connCh is sized to MaxConcurrent and the semaphore already bounds
+		// how many connections can be in flight, so this should only ever
+		// trigger if Conns() isn't being drained.
+		p.metrics.ConnectsDropped.WithLabelValues("queue_full").Inc()
+		slog.Warn("connection channel full, dropping connection", "remote", r.RemoteAddr, "reason", "queue_full")
+		wrapped.Close()
 	}
 }
 
+// evaluateDestination parses a CONNECT request's "host:port" authority and
+// runs it through the server's Policy, returning the destination the
+// connection was allowed (or rewritten) to use. With no Policy configured,
+// it returns hostport unchanged.
+func (p *Server) evaluateDestination(hostport string) (string, error) {
+	host, portStr, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return "", fmt.Errorf("invalid CONNECT authority %q: %w", hostport, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return "", fmt.Errorf("invalid CONNECT port %q: %w", hostport, err)
+	}
+	return p.policy.Load().Evaluate(host, port)
+}
+
+// validProxyAuth reports whether r carries a Proxy-Authorization bearer
+// header matching token.
+func validProxyAuth(r *http.Request, token string) bool {
+	const prefix = "Bearer "
+	auth := r.Header.Get("Proxy-Authorization")
+	if !strings.HasPrefix(auth, prefix) {
+		return false
+	}
+	got := strings.TrimPrefix(auth, prefix)
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
 // Start starts the proxy server.
 func (p *Server) Start() error {
 	slog.Info("starting http connect proxy", "addr", p.addr, "target", p.target)
 	return p.httpServer.ListenAndServe()
 }
 
-// Shutdown gracefully shuts down the proxy server.
+// Shutdown stops accepting new CONNECT requests, then waits for tunnels
+// already handed off via Conns to finish (e.g. by bidi.Pipe returning) before
+// closing the Conns channel. http.Server.Shutdown alone isn't enough here
+// because it has no visibility into hijacked connections; Shutdown blocks
+// until every tracked connection closes or ctx's deadline passes, whichever
+// comes first, so an operator can bound how long a rolling restart waits on
+// slow tunnels.
 func (p *Server) Shutdown(ctx context.Context) error {
 	slog.Info("shutting down http connect proxy")
+
+	p.shutdownOnce.Do(func() { close(p.draining) })
+
+	shutdownErr := p.httpServer.Shutdown(ctx)
+
+	drained := make(chan struct{})
+	go func() {
+		p.tracked.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		slog.Warn("shutdown deadline reached with tunnels still draining")
+	}
+
 	close(p.connCh)
-	return p.httpServer.Shutdown(ctx)
+	return shutdownErr
 }
 
 // Addr returns the address the server is listening on.
 func (p *Server) Addr() string {
 	return p.addr
 }
+
+// instrumentedConn wraps a hijacked net.Conn to count bytes in/out and to
+// release its Server's concurrency slot (and ActiveConnections gauge) on
+// Close, however the consumer pairs this connection up (e.g. via
+// bidi.Pipe).
+type instrumentedConn struct {
+	net.Conn
+	metrics   *Metrics
+	sem       chan struct{}
+	tracked   *sync.WaitGroup
+	closeOnce sync.Once
+}
+
+func (c *instrumentedConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.metrics.BytesIn.Add(float64(n))
+	}
+	return n, err
+}
+
+func (c *instrumentedConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		c.metrics.BytesOut.Add(float64(n))
+	}
+	return n, err
+}
+
+func (c *instrumentedConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		err = c.Conn.Close()
+		c.metrics.ActiveConnections.Dec()
+		<-c.sem
+		c.tracked.Done()
+	})
+	return err
+}
@@ -0,0 +1,89 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+)
+
+// ProxyManagerConfig configures a ProxyManager. TCP and UDP share the same
+// upstream proxy and target: this mirrors what a single bridge session
+// actually needs, a local TCP listener and a local UDP listener both
+// tunneling to the same remote Server.
+type ProxyManagerConfig struct {
+	Host      string
+	TCPPort   int
+	UDPPort   int
+	ProxyURL  string
+	AuthToken string
+	// Target is the "host:port" UDP datagrams are tunneled to. TCPProxy's
+	// own target isn't configured here since proxy.Server (not TCPProxy)
+	// decides TCP destinations per-CONNECT via policy.
+	Target string
+}
+
+// ProxyManager bundles a TCPProxy and a UDPProxy that dial the same
+// ProxyURL/AuthToken, so callers that need both don't have to duplicate that
+// configuration or manage two independent lifecycles. Neither CONNECT nor
+// CONNECT-UDP connections are actually poolable once hijacked (each is a
+// dedicated byte stream for the life of one flow), so "sharing a connection
+// pool" here means sharing the dial configuration and lifecycle, not a
+// literal pool of reusable sockets.
+type ProxyManager struct {
+	TCP TCPProxy
+	UDP UDPProxy
+}
+
+// NewProxyManager builds a ProxyManager from cfg. Callers that only need TCP
+// or only need UDP should use NewTCPProxy/NewUDPProxy directly instead.
+func NewProxyManager(cfg ProxyManagerConfig) *ProxyManager {
+	return &ProxyManager{
+		TCP: NewTCPProxy(TCPProxyConfig{
+			Host:      cfg.Host,
+			Port:      cfg.TCPPort,
+			ProxyURL:  cfg.ProxyURL,
+			AuthToken: cfg.AuthToken,
+		}),
+		UDP: NewUDPProxy(UDPProxyConfig{
+			Host:      cfg.Host,
+			Port:      cfg.UDPPort,
+			ProxyURL:  cfg.ProxyURL,
+			AuthToken: cfg.AuthToken,
+			Target:    cfg.Target,
+		}),
+	}
+}
+
+// Listen binds both the TCP and UDP listeners.
+func (m *ProxyManager) Listen() error {
+	if err := m.TCP.Listen(); err != nil {
+		return fmt.Errorf("failed to listen on tcp proxy: %w", err)
+	}
+	if err := m.UDP.Listen(); err != nil {
+		return fmt.Errorf("failed to listen on udp proxy: %w", err)
+	}
+	return nil
+}
+
+// Serve runs both proxies until ctx is canceled or either returns an error.
+func (m *ProxyManager) Serve(ctx context.Context) error {
+	errCh := make(chan error, 2)
+	go func() { errCh <- m.TCP.Serve(ctx) }()
+	go func() { errCh <- m.UDP.Serve(ctx) }()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// Shutdown stops both listeners.
+func (m *ProxyManager) Shutdown() error {
+	tcpErr := m.TCP.Shutdown()
+	udpErr := m.UDP.Shutdown()
+	if tcpErr != nil {
+		return tcpErr
+	}
+	return udpErr
+}
@@ -0,0 +1,54 @@
+package proxy
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+)
+
+// proxyProtocolV2Signature is the fixed 12-byte magic that opens every PROXY
+// protocol v2 header (see https://www.haproxy.org/download/2.8/doc/proxy-protocol.txt).
+var proxyProtocolV2Signature = [12]byte{0x0D, 0x0A, 0x0D, 0x0A, 0x00, 0x0D, 0x0A, 0x51, 0x55, 0x49, 0x54, 0x0A}
+
+// writeProxyProtocolV2 writes a PROXY protocol v2 header to w identifying a
+// TCP connection from src to dst, so an upstream that speaks PROXY protocol
+// sees the original client's address instead of ours.
+func writeProxyProtocolV2(w io.Writer, src, dst net.Addr) error {
+	srcTCP, ok := src.(*net.TCPAddr)
+	if !ok {
+		return fmt.Errorf("proxy: PROXY protocol v2 requires a TCP source address, got %T", src)
+	}
+	dstTCP, ok := dst.(*net.TCPAddr)
+	if !ok {
+		return fmt.Errorf("proxy: PROXY protocol v2 requires a TCP destination address, got %T", dst)
+	}
+
+	var famTransport byte
+	var addr []byte
+	if srcIP4, dstIP4 := srcTCP.IP.To4(), dstTCP.IP.To4(); srcIP4 != nil && dstIP4 != nil {
+		famTransport = 0x11 // AF_INET, STREAM
+		addr = append(addr, srcIP4...)
+		addr = append(addr, dstIP4...)
+	} else {
+		famTransport = 0x21 // AF_INET6, STREAM
+		addr = append(addr, srcTCP.IP.To16()...)
+		addr = append(addr, dstTCP.IP.To16()...)
+	}
+
+	var ports [4]byte
+	binary.BigEndian.PutUint16(ports[0:2], uint16(srcTCP.Port))
+	binary.BigEndian.PutUint16(ports[2:4], uint16(dstTCP.Port))
+	addr = append(addr, ports[:]...)
+
+	header := make([]byte, 0, len(proxyProtocolV2Signature)+4+len(addr))
+	header = append(header, proxyProtocolV2Signature[:]...)
+	header = append(header, 0x21, famTransport) // version 2, command PROXY
+	var length [2]byte
+	binary.BigEndian.PutUint16(length[:], uint16(len(addr)))
+	header = append(header, length[:]...)
+	header = append(header, addr...)
+
+	_, err := w.Write(header)
+	return err
+}
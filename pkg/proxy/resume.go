@@ -0,0 +1,395 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// headerResumeSessionID carries the server-issued session ID a
+	// reconnecting client presents so the server reattaches to its
+	// existing session instead of starting a fresh one.
+	headerResumeSessionID = "Sec-Bridge-Resume-Id"
+
+	// headerResumeOffset carries, in both directions, the number of bytes
+	// of the peer's stream the sender has already received, so each side
+	// knows how much of its replay buffer the other already has.
+	headerResumeOffset = "Sec-Bridge-Resume-Offset"
+)
+
+// BackoffConfig configures exponential backoff with full jitter between
+// reconnect attempts.
+type BackoffConfig struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+// WithDefaults returns c with zero fields replaced by sensible defaults
+// (200ms initial, 30s max, 2x multiplier), for callers outside this package
+// (e.g. pkg/proxy/carrier) that want the same backoff shape without
+// duplicating it.
+func (c BackoffConfig) WithDefaults() BackoffConfig {
+	if c.Initial <= 0 {
+		c.Initial = 200 * time.Millisecond
+	}
+	if c.Max <= 0 {
+		c.Max = 30 * time.Second
+	}
+	if c.Multiplier <= 0 {
+		c.Multiplier = 2
+	}
+	return c
+}
+
+// Next returns the backoff duration before reconnect attempt n (0-indexed),
+// picked uniformly from [0, min(Max, Initial*Multiplier^n)] to avoid a
+// thundering herd of clients all reconnecting in lockstep.
+func (c BackoffConfig) Next(attempt int) time.Duration {
+	d := float64(c.Initial) * math.Pow(c.Multiplier, float64(attempt))
+	if d > float64(c.Max) {
+		d = float64(c.Max)
+	}
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// ResumableDialerConfig configures a ResumableDialer.
+type ResumableDialerConfig struct {
+	// Backoff controls the delay between reconnect attempts.
+	Backoff BackoffConfig
+
+	// MaxResumeWindow bounds how long Write/Read will keep retrying a
+	// broken connection before giving up and returning an error. Once this
+	// elapses since the connection first dropped, the session is declared
+	// dead. Defaults to 5 minutes.
+	MaxResumeWindow time.Duration
+
+	// SendBufferLimit caps how many bytes of written-but-possibly-unacked
+	// data are kept around for replay after a reconnect. Once exceeded, the
+	// oldest bytes are dropped; if the server reports it never received
+	// them, the session can't be resumed and the dialer gives up.
+	// Defaults to 4 MiB.
+	SendBufferLimit int
+
+	// Logger carries fixed contextual attributes onto every log line this
+	// dialer emits. Defaults to slog.Default() when nil.
+	Logger *slog.Logger
+}
+
+func (cfg ResumableDialerConfig) withDefaults() ResumableDialerConfig {
+	cfg.Backoff = cfg.Backoff.WithDefaults()
+	if cfg.MaxResumeWindow <= 0 {
+		cfg.MaxResumeWindow = 5 * time.Minute
+	}
+	if cfg.SendBufferLimit <= 0 {
+		cfg.SendBufferLimit = 4 * 1024 * 1024
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	return cfg
+}
+
+// ResumableDialer wraps a *WSDialer so that a dropped WebSocket (mobile
+// network switch, ingress restart, an idle-killing load balancer) is
+// transparently reconnected and resumed rather than surfaced to the caller
+// as io.EOF. It assigns each session a server-issued ID plus a monotonic
+// byte offset in each direction, buffers unacknowledged writes, and replays
+// whatever either side missed once the resume handshake completes.
+type ResumableDialer struct {
+	underlying *WSDialer
+	cfg        ResumableDialerConfig
+}
+
+// NewResumableDialer wraps underlying with automatic reconnect/resume.
+func NewResumableDialer(underlying *WSDialer, cfg ResumableDialerConfig) *ResumableDialer {
+	return &ResumableDialer{underlying: underlying, cfg: cfg.withDefaults()}
+}
+
+var _ Dialer = (*ResumableDialer)(nil)
+
+// Dial establishes the initial connection and returns a conn that survives
+// reconnects transparently: Write blocks (rather than erroring) while a
+// reconnect is in progress, up to cfg.MaxResumeWindow.
+func (d *ResumableDialer) Dial(ctx context.Context) (io.ReadWriteCloser, error) {
+	rc := &resumableConn{dialer: d, logger: d.cfg.Logger}
+	if err := rc.connect(ctx, ""); err != nil {
+		return nil, err
+	}
+	return rc, nil
+}
+
+// resumableConn is the io.ReadWriteCloser ResumableDialer.Dial returns. It
+// owns the reconnect/resume state machine; all fields are guarded by mu.
+type resumableConn struct {
+	dialer *ResumableDialer
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	current   *wsConn
+	sessionID string
+	closed    bool
+	dead      error // set once the session is declared unrecoverable
+
+	sendOffset   int64 // total bytes ever handed to the underlying conn's Write
+	sendBufStart int64 // sendOffset value of sendBuf[0]
+	sendBuf      []byte
+
+	recvOffset int64 // total bytes ever handed back by Read
+
+	disconnectedAt time.Time
+}
+
+// connect performs the initial dial (resumeID == "") or a resume dial
+// (resumeID != ""), replaying any buffered-but-unconfirmed writes once the
+// peer reports how much of them it already has.
+func (c *resumableConn) connect(ctx context.Context, resumeID string) error {
+	header := http.Header{}
+	var replayFrom int64
+	if resumeID != "" {
+		header.Set(headerResumeSessionID, resumeID)
+		header.Set(headerResumeOffset, strconv.FormatInt(c.recvOffset, 10))
+	}
+
+	conn, respHeader, err := c.dialer.underlying.DialWithHeader(ctx, header)
+	if err != nil {
+		return fmt.Errorf("dial: %w", err)
+	}
+	conn.onPingFailure = func(err error) { c.handleDisconnect(conn, err) }
+
+	newSessionID := respHeader.Get(headerResumeSessionID)
+	resumed := resumeID != "" && newSessionID == resumeID
+
+	c.mu.Lock()
+	c.current = conn
+	if newSessionID != "" {
+		c.sessionID = newSessionID
+	}
+	c.disconnectedAt = time.Time{}
+	if resumed {
+		if ackStr := respHeader.Get(headerResumeOffset); ackStr != "" {
+			if ack, err := strconv.ParseInt(ackStr, 10, 64); err == nil && ack > c.sendBufStart {
+				c.trimSendBufLocked(ack)
+			} else if err == nil && ack < c.sendBufStart {
+				// The peer is missing bytes SendBufferLimit already dropped.
+				c.current = nil
+				c.mu.Unlock()
+				conn.Close()
+				gapErr := fmt.Errorf("resume: peer has %d bytes but the send buffer starts at offset %d", ack, c.sendBufStart)
+				c.fail(gapErr)
+				return gapErr
+			}
+		}
+		replayFrom = c.sendBufStart
+		toReplay := append([]byte(nil), c.sendBuf...)
+		c.mu.Unlock()
+
+		if len(toReplay) > 0 {
+			if err := c.writeRaw(conn, toReplay); err != nil {
+				c.mu.Lock()
+				c.current = nil
+				c.mu.Unlock()
+				return fmt.Errorf("replay %d buffered bytes from offset %d: %w", len(toReplay), replayFrom, err)
+			}
+		}
+		c.logger.Debug("resumed session", "session_id", newSessionID, "replayed_bytes", len(toReplay))
+	} else {
+		if resumeID != "" {
+			c.logger.Warn("server could not resume session, starting fresh", "requested_session_id", resumeID)
+		}
+		// A fresh (or rejected-resume) session shares nothing with the
+		// old one; reset all offsets so replay bookkeeping starts clean.
+		c.sendOffset = 0
+		c.sendBufStart = 0
+		c.sendBuf = nil
+		c.recvOffset = 0
+		c.mu.Unlock()
+	}
+
+	return nil
+}
+
+// trimSendBufLocked drops bytes up to (but not including) absolute offset
+// ack from the send buffer, since the peer has confirmed receiving them.
+// Caller holds mu.
+func (c *resumableConn) trimSendBufLocked(ack int64) {
+	drop := ack - c.sendBufStart
+	if drop <= 0 {
+		return
+	}
+	if drop >= int64(len(c.sendBuf)) {
+		c.sendBuf = nil
+	} else {
+		c.sendBuf = append([]byte(nil), c.sendBuf[drop:]...)
+	}
+	c.sendBufStart = ack
+}
+
+func (c *resumableConn) writeRaw(conn *wsConn, p []byte) error {
+	_, err := conn.Write(p)
+	return err
+}
+
+// handleDisconnect marks conn as broken and kicks off a background
+// reconnect loop, unless conn has already been superseded (by a previous
+// handleDisconnect/reconnect) — a stale failure report or a late Write error
+// on an already-replaced connection must not tear down a healthy session.
+func (c *resumableConn) handleDisconnect(conn *wsConn, cause error) {
+	c.mu.Lock()
+	if c.current == nil || c.current != conn || c.closed || c.dead != nil {
+		c.mu.Unlock()
+		return
+	}
+	c.current = nil
+	if c.disconnectedAt.IsZero() {
+		c.disconnectedAt = time.Now()
+	}
+	c.mu.Unlock()
+
+	go c.reconnectLoop(cause)
+}
+
+// reconnectLoop retries the resume dial with backoff until it succeeds, the
+// conn is closed, or MaxResumeWindow elapses since the outage began.
+func (c *resumableConn) reconnectLoop(cause error) {
+	c.logger.Warn("connection lost, attempting to resume", "error", cause)
+
+	for attempt := 0; ; attempt++ {
+		c.mu.Lock()
+		closed := c.closed
+		sessionID := c.sessionID
+		deadline := c.disconnectedAt.Add(c.dialer.cfg.MaxResumeWindow)
+		c.mu.Unlock()
+		if closed {
+			return
+		}
+		if time.Now().After(deadline) {
+			c.fail(fmt.Errorf("resume window (%s) elapsed: %w", c.dialer.cfg.MaxResumeWindow, cause))
+			return
+		}
+
+		if attempt > 0 {
+			time.Sleep(c.dialer.cfg.Backoff.Next(attempt - 1))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := c.connect(ctx, sessionID)
+		cancel()
+		if err == nil {
+			c.logger.Info("connection resumed", "session_id", sessionID, "attempts", attempt+1)
+			return
+		}
+		c.logger.Debug("resume attempt failed, retrying", "attempt", attempt+1, "error", err)
+	}
+}
+
+// fail permanently marks the session dead; all further Read/Write calls
+// return err.
+func (c *resumableConn) fail(err error) {
+	c.mu.Lock()
+	c.dead = err
+	c.mu.Unlock()
+	c.logger.Error("giving up on resuming connection", "error", err)
+}
+
+// awaitReconnect blocks until either a new connection is established, the
+// session is declared dead, or the conn is closed, returning the live
+// connection (or an error).
+func (c *resumableConn) awaitReconnect() (*wsConn, error) {
+	for {
+		c.mu.Lock()
+		switch {
+		case c.closed:
+			c.mu.Unlock()
+			return nil, io.ErrClosedPipe
+		case c.dead != nil:
+			err := c.dead
+			c.mu.Unlock()
+			return nil, err
+		case c.current != nil:
+			conn := c.current
+			c.mu.Unlock()
+			return conn, nil
+		}
+		c.mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func (c *resumableConn) Read(p []byte) (int, error) {
+	for {
+		conn, err := c.awaitReconnect()
+		if err != nil {
+			return 0, err
+		}
+		n, err := conn.Read(p)
+		if n > 0 {
+			c.mu.Lock()
+			c.recvOffset += int64(n)
+			c.mu.Unlock()
+		}
+		if err == nil {
+			return n, nil
+		}
+		c.handleDisconnect(conn, err)
+		if n > 0 {
+			return n, nil
+		}
+		// Loop around: Write's callers see a brief stall instead of an
+		// error, so Read does the same for symmetry.
+	}
+}
+
+// Write blocks while a reconnect is in progress (up to MaxResumeWindow)
+// instead of returning an error, buffering p for replay if the connection
+// drops again before the peer has confirmed receiving it.
+func (c *resumableConn) Write(p []byte) (int, error) {
+	conn, err := c.awaitReconnect()
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	c.sendBuf = append(c.sendBuf, p...)
+	if over := len(c.sendBuf) - c.dialer.cfg.SendBufferLimit; over > 0 {
+		c.sendBuf = c.sendBuf[over:]
+		c.sendBufStart += int64(over)
+	}
+	c.sendOffset += int64(len(p))
+	c.mu.Unlock()
+
+	if _, err := conn.Write(p); err != nil {
+		c.handleDisconnect(conn, err)
+		// The bytes are already buffered above, so the reconnect's replay
+		// will deliver them; report success to the caller rather than the
+		// transient error, matching the "block, don't error" contract.
+		if _, err := c.awaitReconnect(); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+func (c *resumableConn) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	conn := c.current
+	c.current = nil
+	c.mu.Unlock()
+	if conn != nil {
+		return conn.Close()
+	}
+	return nil
+}
@@ -1,18 +1,17 @@
 package proxy
 
 import (
-	"bufio"
 	"context"
 	"crypto/tls"
 	"fmt"
 	"log/slog"
 	"net"
-	"net/http"
 	"net/url"
 	"sync/atomic"
 	"time"
 
 	"github.com/reach/pkg/bidi"
+	"github.com/vercel-eddie/bridge/pkg/netutil"
 )
 
 // TCPProxy listens for local TCP connections and forwards them through
@@ -34,13 +33,43 @@ type TCPProxyConfig struct {
 	Port int
 	// ProxyURL is the HTTP CONNECT proxy URL (e.g., "http://remote:3000")
 	ProxyURL string
+	// AuthToken, if set, is sent as "Proxy-Authorization: Bearer <token>" on
+	// the CONNECT request, matching proxy.Server's AuthToken.
+	AuthToken string
+	// Transparent, if set, ignores per-connection destinations entirely and
+	// instead recovers each accepted connection's true pre-redirect
+	// destination via SO_ORIGINAL_DST on Linux (pf's DIOCNATLOOK on
+	// darwin/freebsd, see netutil.OriginalDest), CONNECT-tunneling to
+	// that address. This lets a single iptables TPROXY/REDIRECT rule
+	// capture arbitrary egress instead of requiring a listener per
+	// destination. On non-Linux platforms this logs a warning and falls back
+	// to a normal listener, since IP_TRANSPARENT has no equivalent there.
+	Transparent bool
+	// TunnelProtocol selects the Transport used to reach ProxyURL. The only
+	// implementation today is HTTP/1.1 CONNECT (see TunnelProtocol).
+	TunnelProtocol TunnelProtocol
+}
+
+// destResolver returns the address a connection accepted by the local
+// listener should be CONNECT-tunneled to.
+type destResolver func(conn net.Conn) (string, error)
+
+// fixedDestResolver is the default resolver: proxy.Server forwards every
+// hijacked connection to its own single configured target regardless of
+// what the CONNECT request asked for, so the authority in the CONNECT
+// request line has never mattered and can stay a placeholder.
+func fixedDestResolver(net.Conn) (string, error) {
+	return "tunnel", nil
 }
 
 type tcpProxy struct {
-	listener    net.Listener
-	proxyURL    string
-	addr        string
-	connections atomic.Int64
+	listener     net.Listener
+	proxyURL     string
+	transport    Transport
+	addr         string
+	transparent  bool
+	destResolver destResolver
+	connections  atomic.Int64
 }
 
 // NewTCPProxy creates a new TCP proxy that forwards connections through HTTP CONNECT.
@@ -52,14 +81,27 @@ func NewTCPProxy(cfg TCPProxyConfig) TCPProxy {
 
 	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
 
+	resolver := fixedDestResolver
+	if cfg.Transparent {
+		resolver = netutil.OriginalDest
+	}
+
 	return &tcpProxy{
-		proxyURL: cfg.ProxyURL,
-		addr:     addr,
+		proxyURL:     cfg.ProxyURL,
+		transport:    newTransport(cfg.ProxyURL, cfg.AuthToken, cfg.TunnelProtocol),
+		addr:         addr,
+		transparent:  cfg.Transparent,
+		destResolver: resolver,
 	}
 }
 
 func (p *tcpProxy) Listen() error {
-	listener, err := net.Listen("tcp", p.addr)
+	lc := net.ListenConfig{}
+	if p.transparent {
+		lc.Control = setTransparentSocketOption
+	}
+
+	listener, err := lc.Listen(context.Background(), "tcp", p.addr)
 	if err != nil {
 		return fmt.Errorf("failed to listen: %w", err)
 	}
@@ -93,10 +135,13 @@ func (p *tcpProxy) Serve(ctx context.Context) error {
 
 func (p *tcpProxy) Shutdown() error {
 	slog.Info("shutting down tcp proxy")
+	transportErr := p.transport.Close()
 	if p.listener != nil {
-		return p.listener.Close()
+		if err := p.listener.Close(); err != nil {
+			return err
+		}
 	}
-	return nil
+	return transportErr
 }
 
 func (p *tcpProxy) Addr() string {
@@ -126,9 +171,15 @@ func (p *tcpProxy) handleConn(clientConn net.Conn) {
 		slog.Debug("client disconnected from tcp proxy", "remote", remoteAddr)
 	}()
 
-	tunnelConn, err := p.dialThroughProxy()
+	dest, err := p.destResolver(clientConn)
+	if err != nil {
+		slog.Error("failed to resolve destination", "error", err, "remote", remoteAddr)
+		return
+	}
+
+	tunnelConn, err := p.transport.DialTunnel(context.Background(), dest)
 	if err != nil {
-		slog.Error("failed to establish tunnel", "error", err, "remote", remoteAddr)
+		slog.Error("failed to establish tunnel", "error", err, "remote", remoteAddr, "destination", dest)
 		return
 	}
 	defer tunnelConn.Close()
@@ -138,65 +189,42 @@ func (p *tcpProxy) handleConn(clientConn net.Conn) {
 	bidi.New(clientConn, tunnelConn).Wait(context.Background())
 }
 
-func (p *tcpProxy) dialThroughProxy() (net.Conn, error) {
-	proxyURL, err := url.Parse(p.proxyURL)
+// dialProxyConn opens the underlying TCP (or TLS, for an "https://" proxy
+// URL) connection to the CONNECT proxy itself, before any CONNECT or
+// CONNECT-UDP request is written to it. Shared by h1Transport and
+// dialUDPThroughProxy so the two only differ in the request they send over
+// this connection.
+func dialProxyConn(proxyURL string) (net.Conn, error) {
+	parsed, err := url.Parse(proxyURL)
 	if err != nil {
 		return nil, fmt.Errorf("invalid proxy URL: %w", err)
 	}
 
-	proxyAddr := proxyURL.Host
-	if proxyURL.Port() == "" {
-		if proxyURL.Scheme == "https" {
+	proxyAddr := parsed.Host
+	if parsed.Port() == "" {
+		if parsed.Scheme == "https" {
 			proxyAddr += ":443"
 		} else {
 			proxyAddr += ":80"
 		}
 	}
 
-	var conn net.Conn
-	if proxyURL.Scheme == "https" {
-		// Use TLS for HTTPS URLs
+	if parsed.Scheme == "https" {
 		tlsConn, err := tls.DialWithDialer(
 			&net.Dialer{Timeout: 10 * time.Second},
 			"tcp",
 			proxyAddr,
-			&tls.Config{ServerName: proxyURL.Hostname()},
+			&tls.Config{ServerName: parsed.Hostname()},
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to connect to proxy via TLS: %w", err)
 		}
-		conn = tlsConn
-	} else {
-		var err error
-		conn, err = net.DialTimeout("tcp", proxyAddr, 10*time.Second)
-		if err != nil {
-			return nil, fmt.Errorf("failed to connect to proxy: %w", err)
-		}
-	}
-
-	req := &http.Request{
-		Method: http.MethodConnect,
-		URL:    &url.URL{Host: "tunnel"},
-		Header: make(http.Header),
+		return tlsConn, nil
 	}
-	req.Header.Set("Proxy-Connection", "keep-alive")
 
-	if err := req.Write(conn); err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to send CONNECT request: %w", err)
-	}
-
-	br := bufio.NewReader(conn)
-	resp, err := http.ReadResponse(br, req)
+	conn, err := net.DialTimeout("tcp", proxyAddr, 10*time.Second)
 	if err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to read CONNECT response: %w", err)
+		return nil, fmt.Errorf("failed to connect to proxy: %w", err)
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		conn.Close()
-		return nil, fmt.Errorf("proxy returned status %d", resp.StatusCode)
-	}
-
 	return conn, nil
 }
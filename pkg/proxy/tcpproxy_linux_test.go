@@ -0,0 +1,47 @@
+//go:build linux
+
+package proxy
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/vercel-eddie/bridge/pkg/netutil"
+)
+
+func TestNewTCPProxyTransparentUsesOriginalDestResolver(t *testing.T) {
+	p := NewTCPProxy(TCPProxyConfig{ProxyURL: "http://example.invalid", Transparent: true}).(*tcpProxy)
+
+	if !p.transparent {
+		t.Fatal("expected transparent to be true")
+	}
+
+	got := reflect.ValueOf(p.destResolver).Pointer()
+	want := reflect.ValueOf(netutil.OriginalDest).Pointer()
+	if got != want {
+		t.Fatal("Transparent proxy should resolve destinations via netutil.OriginalDest")
+	}
+}
+
+func TestNewTCPProxyDefaultResolver(t *testing.T) {
+	p := NewTCPProxy(TCPProxyConfig{ProxyURL: "http://example.invalid"}).(*tcpProxy)
+
+	dest, err := p.destResolver(nil)
+	if err != nil {
+		t.Fatalf("fixedDestResolver() error: %v", err)
+	}
+	if dest != "tunnel" {
+		t.Fatalf("fixedDestResolver() = %q, want %q", dest, "tunnel")
+	}
+}
+
+func TestSetTransparentSocketOptionListen(t *testing.T) {
+	lc := net.ListenConfig{Control: setTransparentSocketOption}
+	ln, err := lc.Listen(context.Background(), "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("IP_TRANSPARENT requires CAP_NET_ADMIN, skipping: %v", err)
+	}
+	ln.Close()
+}
@@ -0,0 +1,22 @@
+//go:build linux
+
+package proxy
+
+import "syscall"
+
+// ipTransparent is IP_TRANSPARENT from linux/in.h; the syscall package
+// doesn't define it.
+const ipTransparent = 19
+
+// setTransparentSocketOption sets IP_TRANSPARENT on the listening socket, so
+// the kernel accepts connections a TPROXY iptables rule redirected to an
+// address this process doesn't itself own.
+func setTransparentSocketOption(_, _ string, c syscall.RawConn) error {
+	var sockErr error
+	if err := c.Control(func(fd uintptr) {
+		sockErr = syscall.SetsockoptInt(int(fd), syscall.SOL_IP, ipTransparent, 1)
+	}); err != nil {
+		return err
+	}
+	return sockErr
+}
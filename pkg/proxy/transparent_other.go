@@ -0,0 +1,19 @@
+//go:build !linux
+
+package proxy
+
+import (
+	"log/slog"
+	"syscall"
+)
+
+// setTransparentSocketOption is a no-op outside Linux: IP_TRANSPARENT and
+// TPROXY are Linux-specific, so the listener behaves like a normal one
+// instead of failing to start. Destinations still go through
+// netutil.OriginalDest, which on darwin/freebsd looks up a pf-redirected
+// connection's original destination and falls back to RemoteAddr when pf
+// has no state for it.
+func setTransparentSocketOption(_, _ string, _ syscall.RawConn) error {
+	slog.Warn("transparent proxy mode (IP_TRANSPARENT) is only supported on Linux; listening normally")
+	return nil
+}
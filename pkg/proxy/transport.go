@@ -0,0 +1,41 @@
+package proxy
+
+import (
+	"context"
+	"net"
+)
+
+// Transport opens a tunneled connection to a destination ("host:port")
+// through the proxy TCPProxy is configured against. TCPProxy holds exactly
+// one Transport for its whole lifetime; switching TunnelProtocol only
+// changes which implementation newTransport returns.
+type Transport interface {
+	// DialTunnel returns a net.Conn whose Read/Write carry the tunneled
+	// byte stream to dest.
+	DialTunnel(ctx context.Context, dest string) (net.Conn, error)
+	// Close releases any underlying connection(s) this Transport holds.
+	Close() error
+}
+
+// TunnelProtocol selects a Transport implementation. The only implementation
+// today is HTTP/1.1 CONNECT, matching what pkg/proxy's own Server can
+// terminate: Server.handleConnect hijacks the connection (proxy.go), which
+// neither an HTTP/2 nor an HTTP/3 response supports, and Server never serves
+// anything but plaintext HTTP/1.1 (Start calls p.httpServer.ListenAndServe()).
+// This type stays exported so a future multiplexed transport can be added
+// without changing TCPProxyConfig's field.
+type TunnelProtocol string
+
+const (
+	// TunnelProtocolAuto is currently equivalent to TunnelProtocolH1.
+	TunnelProtocolAuto TunnelProtocol = ""
+	// TunnelProtocolH1 dials one fresh TCP (or TLS) connection and sends
+	// one HTTP/1.1 CONNECT request per tunneled flow.
+	TunnelProtocolH1 TunnelProtocol = "http1"
+)
+
+// newTransport builds the Transport named by protocol for the given proxy
+// URL/auth token.
+func newTransport(proxyURL, authToken string, protocol TunnelProtocol) Transport {
+	return newH1Transport(proxyURL, authToken)
+}
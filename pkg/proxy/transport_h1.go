@@ -0,0 +1,63 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// h1Transport dials a fresh TCP (or TLS) connection and sends one HTTP/1.1
+// CONNECT request per DialTunnel call, exactly as TCPProxy always did before
+// Transport existed. It has no connection to reuse or health-check, so Close
+// is a no-op.
+type h1Transport struct {
+	proxyURL  string
+	authToken string
+}
+
+func newH1Transport(proxyURL, authToken string) *h1Transport {
+	return &h1Transport{proxyURL: proxyURL, authToken: authToken}
+}
+
+func (t *h1Transport) DialTunnel(ctx context.Context, dest string) (net.Conn, error) {
+	conn, err := dialProxyConn(t.proxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req := (&http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Host: dest},
+		Header: make(http.Header),
+	}).WithContext(ctx)
+	req.Header.Set("Proxy-Connection", "keep-alive")
+	if t.authToken != "" {
+		req.Header.Set("Proxy-Authorization", "Bearer "+t.authToken)
+	}
+
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send CONNECT request: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read CONNECT response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy returned status %d", resp.StatusCode)
+	}
+
+	return conn, nil
+}
+
+func (t *h1Transport) Close() error {
+	return nil
+}
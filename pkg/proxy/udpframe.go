@@ -0,0 +1,44 @@
+package proxy
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// maxUDPFrameSize is the largest payload writeUDPFrame/readUDPFrame can
+// carry, matching the 16-bit length prefix.
+const maxUDPFrameSize = 0xFFFF
+
+// writeUDPFrame writes payload to w as a single length-prefixed frame: a
+// big-endian uint16 length followed by the payload. Both UDPProxy's tunnel
+// client and Server's CONNECT-UDP handler use this framing to shuttle
+// discrete UDP datagrams over the single underlying TCP/TLS stream a CONNECT
+// request hijacks.
+func writeUDPFrame(w io.Writer, payload []byte) error {
+	if len(payload) > maxUDPFrameSize {
+		return fmt.Errorf("udp datagram too large to frame: %d bytes", len(payload))
+	}
+
+	frame := make([]byte, 2+len(payload))
+	binary.BigEndian.PutUint16(frame, uint16(len(payload)))
+	copy(frame[2:], payload)
+
+	_, err := w.Write(frame)
+	return err
+}
+
+// readUDPFrame reads one length-prefixed frame from r into buf, which must
+// be at least maxUDPFrameSize bytes, and returns the payload length.
+func readUDPFrame(r io.Reader, buf []byte) (int, error) {
+	var lenBuf [2]byte
+	if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+		return 0, err
+	}
+
+	n := int(binary.BigEndian.Uint16(lenBuf[:]))
+	if _, err := io.ReadFull(r, buf[:n]); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
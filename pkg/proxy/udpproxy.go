@@ -0,0 +1,312 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// UDPProxy listens for local UDP datagrams and forwards each distinct source
+// address's flow through its own CONNECT-UDP tunnel (RFC 9298-style, over
+// the same HTTP CONNECT machinery TCPProxy uses) to a remote proxy.Server,
+// so DNS, gRPC-over-QUIC and other UDP workloads can reach an in-cluster
+// target the same way TCP traffic already does.
+type UDPProxy interface {
+	Listen() error
+	Serve(ctx context.Context) error
+	Shutdown() error
+	Addr() string
+	Port() int
+	ActiveFlows() int64
+}
+
+// UDPProxyConfig configures the UDP proxy.
+type UDPProxyConfig struct {
+	// Host to bind locally.
+	Host string
+	// Port to listen on locally.
+	Port int
+	// ProxyURL is the HTTP CONNECT proxy URL (e.g., "http://remote:3000").
+	ProxyURL string
+	// AuthToken, if set, is sent as "Proxy-Authorization: Bearer <token>" on
+	// the CONNECT-UDP request, matching TCPProxyConfig.AuthToken.
+	AuthToken string
+	// Target is the "host:port" every flow's datagrams are tunneled to.
+	Target string
+	// IdleTimeout closes a flow's tunnel after this long without a datagram
+	// in either direction (default 60s).
+	IdleTimeout time.Duration
+}
+
+type udpProxy struct {
+	conn        net.PacketConn
+	proxyURL    string
+	authToken   string
+	target      string
+	idleTimeout time.Duration
+	addr        string
+
+	mu          sync.Mutex
+	flows       map[string]*udpFlow
+	activeFlows atomic.Int64
+}
+
+// NewUDPProxy creates a new UDP proxy that forwards datagrams through
+// CONNECT-UDP tunnels. If Port is 0, a random available port will be
+// assigned.
+func NewUDPProxy(cfg UDPProxyConfig) UDPProxy {
+	if cfg.Host == "" {
+		cfg.Host = "127.0.0.1"
+	}
+	idleTimeout := cfg.IdleTimeout
+	if idleTimeout == 0 {
+		idleTimeout = 60 * time.Second
+	}
+
+	return &udpProxy{
+		proxyURL:    cfg.ProxyURL,
+		authToken:   cfg.AuthToken,
+		target:      cfg.Target,
+		idleTimeout: idleTimeout,
+		addr:        fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		flows:       make(map[string]*udpFlow),
+	}
+}
+
+func (p *udpProxy) Listen() error {
+	conn, err := net.ListenPacket("udp", p.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	p.conn = conn
+	p.addr = conn.LocalAddr().String()
+	return nil
+}
+
+func (p *udpProxy) Serve(ctx context.Context) error {
+	if p.conn == nil {
+		return fmt.Errorf("must call Listen before Serve")
+	}
+
+	slog.Info("starting udp proxy", "addr", p.addr, "proxy", p.proxyURL, "target", p.target)
+
+	go p.reapIdleFlows(ctx)
+
+	buf := make([]byte, maxUDPFrameSize)
+	for {
+		n, srcAddr, err := p.conn.ReadFrom(buf)
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return nil
+			default:
+				slog.Error("failed to read udp datagram", "error", err)
+				continue
+			}
+		}
+
+		flow, err := p.flowFor(srcAddr)
+		if err != nil {
+			slog.Error("failed to establish udp tunnel", "error", err, "remote", srcAddr)
+			continue
+		}
+
+		flow.touch()
+		if err := writeUDPFrame(flow.tunnel, buf[:n]); err != nil {
+			slog.Error("failed to write udp frame", "error", err, "remote", srcAddr)
+			p.closeFlow(srcAddr.String(), flow)
+		}
+	}
+}
+
+// flowFor returns the existing tunnel for srcAddr, dialing a new one (and
+// starting its response reader) if this is the first datagram seen from it.
+func (p *udpProxy) flowFor(srcAddr net.Addr) (*udpFlow, error) {
+	key := srcAddr.String()
+
+	p.mu.Lock()
+	if flow, ok := p.flows[key]; ok {
+		p.mu.Unlock()
+		return flow, nil
+	}
+	p.mu.Unlock()
+
+	tunnel, err := p.dialUDPThroughProxy(p.target)
+	if err != nil {
+		return nil, err
+	}
+
+	flow := &udpFlow{tunnel: tunnel}
+	flow.touch()
+
+	p.mu.Lock()
+	p.flows[key] = flow
+	p.mu.Unlock()
+	p.activeFlows.Add(1)
+
+	go p.readFlowResponses(srcAddr, key, flow)
+
+	return flow, nil
+}
+
+// readFlowResponses copies datagrams the tunnel sends back for srcAddr's
+// flow out to the local UDP socket until the tunnel closes, then tears the
+// flow down.
+func (p *udpProxy) readFlowResponses(srcAddr net.Addr, key string, flow *udpFlow) {
+	defer p.closeFlow(key, flow)
+
+	buf := make([]byte, maxUDPFrameSize)
+	for {
+		n, err := readUDPFrame(flow.tunnel, buf)
+		if err != nil {
+			return
+		}
+		flow.touch()
+		if _, err := p.conn.WriteTo(buf[:n], srcAddr); err != nil {
+			slog.Error("failed to write udp response", "error", err, "remote", srcAddr)
+			return
+		}
+	}
+}
+
+func (p *udpProxy) closeFlow(key string, flow *udpFlow) {
+	p.mu.Lock()
+	if p.flows[key] == flow {
+		delete(p.flows, key)
+		p.activeFlows.Add(-1)
+	}
+	p.mu.Unlock()
+	flow.Close()
+}
+
+// reapIdleFlows periodically closes tunnels that haven't carried a datagram
+// in either direction for idleTimeout, so a client that stops sending
+// doesn't hold a tunnel (and the remote's concurrency slot behind it) open
+// forever.
+func (p *udpProxy) reapIdleFlows(ctx context.Context) {
+	ticker := time.NewTicker(p.idleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			stale := make(map[string]*udpFlow, len(p.flows))
+			for key, flow := range p.flows {
+				if flow.idleSince() > p.idleTimeout {
+					stale[key] = flow
+				}
+			}
+			p.mu.Unlock()
+
+			for key, flow := range stale {
+				slog.Debug("closing idle udp flow", "flow", key, "idle", flow.idleSince())
+				p.closeFlow(key, flow)
+			}
+		}
+	}
+}
+
+func (p *udpProxy) Shutdown() error {
+	slog.Info("shutting down udp proxy")
+
+	p.mu.Lock()
+	flows := p.flows
+	p.flows = make(map[string]*udpFlow)
+	p.mu.Unlock()
+
+	for _, flow := range flows {
+		flow.Close()
+	}
+	p.activeFlows.Store(0)
+
+	if p.conn != nil {
+		return p.conn.Close()
+	}
+	return nil
+}
+
+func (p *udpProxy) Addr() string {
+	return p.addr
+}
+
+func (p *udpProxy) Port() int {
+	if p.conn == nil {
+		return 0
+	}
+	return p.conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func (p *udpProxy) ActiveFlows() int64 {
+	return p.activeFlows.Load()
+}
+
+// dialUDPThroughProxy opens a CONNECT-UDP tunnel to dest: it reuses the same
+// proxy connection setup (dialProxyConn) as h1Transport.DialTunnel, but sends an
+// "Upgrade: connect-udp" CONNECT request instead of a plain one, and the
+// returned net.Conn carries length-prefixed datagram frames (see
+// writeUDPFrame/readUDPFrame) rather than a raw byte stream.
+func (p *udpProxy) dialUDPThroughProxy(dest string) (net.Conn, error) {
+	conn, err := dialProxyConn(p.proxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Host: dest},
+		Header: make(http.Header),
+	}
+	req.Header.Set("Upgrade", "connect-udp")
+	req.Header.Set("Proxy-Connection", "keep-alive")
+	if p.authToken != "" {
+		req.Header.Set("Proxy-Authorization", "Bearer "+p.authToken)
+	}
+
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send CONNECT-UDP request: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read CONNECT-UDP response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy returned status %d", resp.StatusCode)
+	}
+
+	return conn, nil
+}
+
+// udpFlow is one client source address's tunnel to Target.
+type udpFlow struct {
+	tunnel     net.Conn
+	lastActive atomic.Int64 // unix nano, updated on each datagram
+	closeOnce  sync.Once
+}
+
+func (f *udpFlow) touch() {
+	f.lastActive.Store(time.Now().UnixNano())
+}
+
+func (f *udpFlow) idleSince() time.Duration {
+	return time.Since(time.Unix(0, f.lastActive.Load()))
+}
+
+func (f *udpFlow) Close() {
+	f.closeOnce.Do(func() { f.tunnel.Close() })
+}
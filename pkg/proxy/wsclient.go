@@ -1,9 +1,14 @@
 package proxy
 
 import (
+	"bufio"
 	"context"
+	"crypto/tls"
+	"encoding/base64"
 	"fmt"
 	"io"
+	"log/slog"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
@@ -15,14 +20,64 @@ import (
 
 // WSDialer dials WebSocket connections to a tunnel server.
 type WSDialer struct {
-	tunnelURL string
-	dialer    *websocket.Dialer
+	tunnelURL        string
+	proxyURL         *url.URL
+	proxyAuth        string
+	dialer           *websocket.Dialer
+	compressionLevel int
+	logger           *slog.Logger
 }
 
 var _ Dialer = (*WSDialer)(nil)
 
-// NewWSDialer creates a new WebSocket dialer for the given tunnel URL.
+// WSDialerConfig configures a WSDialer.
+type WSDialerConfig struct {
+	// TunnelURL is the ws(s):// or http(s):// URL of the tunnel server's
+	// Path endpoint.
+	TunnelURL string
+
+	// ProxyURL explicitly selects the HTTP CONNECT proxy to tunnel the
+	// WebSocket upgrade through. When nil, http.ProxyFromEnvironment is
+	// consulted (HTTPS_PROXY/HTTP_PROXY/NO_PROXY), matching how the rest of
+	// the Go standard library picks up a corporate proxy.
+	ProxyURL *url.URL
+
+	// ProxyAuthToken, when set, is sent as "Bearer <token>" in the
+	// Proxy-Authorization header of the CONNECT request. Takes precedence
+	// over any userinfo on ProxyURL, which is used for Basic auth instead.
+	ProxyAuthToken string
+
+	// Path is the tunnel endpoint to dial, e.g. "/ssh" or "/tunnel".
+	// Defaults to "/ssh".
+	Path string
+
+	// EnableCompression negotiates permessage-deflate on the dial, matching
+	// WSServerConfig.EnableCompression on the server side. Both ends must
+	// agree for the extension to take effect; gorilla/websocket falls back
+	// to uncompressed silently if the peer doesn't support it.
+	EnableCompression bool
+
+	// CompressionLevel is passed to SetCompressionLevel on the dialed
+	// connection when EnableCompression is set. Defaults to
+	// websocket.DefaultCompression.
+	CompressionLevel int
+
+	// Logger carries fixed contextual attributes onto every log line this
+	// dialer emits. Defaults to slog.Default() when nil.
+	Logger *slog.Logger
+}
+
+// NewWSDialer creates a new WebSocket dialer for the given tunnel URL,
+// honoring HTTPS_PROXY/HTTP_PROXY from the environment. Use
+// NewWSDialerFromConfig to set an explicit proxy or proxy credentials.
 func NewWSDialer(tunnelURL string) *WSDialer {
+	return NewWSDialerFromConfig(WSDialerConfig{TunnelURL: tunnelURL})
+}
+
+// NewWSDialerFromConfig creates a WSDialer from cfg.
+func NewWSDialerFromConfig(cfg WSDialerConfig) *WSDialer {
+	tunnelURL := cfg.TunnelURL
+
 	// Convert HTTP URL to WebSocket URL
 	if strings.HasPrefix(tunnelURL, "https://") {
 		tunnelURL = "wss://" + strings.TrimPrefix(tunnelURL, "https://")
@@ -30,45 +85,90 @@ func NewWSDialer(tunnelURL string) *WSDialer {
 		tunnelURL = "ws://" + strings.TrimPrefix(tunnelURL, "http://")
 	}
 
-	// Ensure /ssh path
-	if !strings.HasSuffix(tunnelURL, "/ssh") {
-		tunnelURL = strings.TrimSuffix(tunnelURL, "/") + "/ssh"
+	path := cfg.Path
+	if path == "" {
+		path = "/ssh"
+	}
+	if !strings.HasSuffix(tunnelURL, path) {
+		tunnelURL = strings.TrimSuffix(tunnelURL, "/") + path
+	}
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	compressionLevel := cfg.CompressionLevel
+	if compressionLevel == 0 {
+		compressionLevel = websocket.DefaultCompression
 	}
 
-	return &WSDialer{
+	d := &WSDialer{
 		tunnelURL: tunnelURL,
+		proxyURL:  cfg.ProxyURL,
+		proxyAuth: cfg.ProxyAuthToken,
 		dialer: &websocket.Dialer{
-			HandshakeTimeout: 30 * time.Second,
-			ReadBufferSize:   32 * 1024,
-			WriteBufferSize:  32 * 1024,
+			HandshakeTimeout:  30 * time.Second,
+			ReadBufferSize:    32 * 1024,
+			WriteBufferSize:   32 * 1024,
+			EnableCompression: cfg.EnableCompression,
 		},
+		compressionLevel: compressionLevel,
+		logger:           logger,
 	}
+	d.dialer.NetDialContext = d.netDialContext
+	return d
+}
+
+// WithLogger sets the logger d uses for connection-scoped log lines (e.g.
+// the ping loop's keepalive failures), carrying fixed attributes like
+// remote address through every call it makes.
+func (d *WSDialer) WithLogger(logger *slog.Logger) *WSDialer {
+	d.logger = logger
+	return d
 }
 
 // Dial connects to the WebSocket tunnel server and returns an io.ReadWriteCloser.
 func (d *WSDialer) Dial(ctx context.Context) (io.ReadWriteCloser, error) {
+	conn, _, err := d.DialWithHeader(ctx, nil)
+	return conn, err
+}
+
+// DialWithHeader is Dial plus the ability to send extra request headers and
+// inspect the server's response headers — e.g. ResumableDialer uses it to
+// carry the Sec-Bridge-Resume-Id/Offset handshake.
+func (d *WSDialer) DialWithHeader(ctx context.Context, extraHeader http.Header) (*wsConn, http.Header, error) {
 	u, err := url.Parse(d.tunnelURL)
 	if err != nil {
-		return nil, fmt.Errorf("invalid tunnel URL: %w", err)
+		return nil, nil, fmt.Errorf("invalid tunnel URL: %w", err)
 	}
 
 	header := http.Header{}
 	header.Set("Origin", fmt.Sprintf("https://%s", u.Host))
+	for k, vs := range extraHeader {
+		for _, v := range vs {
+			header.Add(k, v)
+		}
+	}
 
 	conn, resp, err := d.dialer.DialContext(ctx, d.tunnelURL, header)
 	if err != nil {
 		if resp != nil {
-			return nil, fmt.Errorf("websocket dial failed with status %d: %w", resp.StatusCode, err)
+			return nil, nil, fmt.Errorf("websocket dial failed with status %d: %w", resp.StatusCode, err)
 		}
-		return nil, fmt.Errorf("websocket dial failed: %w", err)
+		return nil, nil, fmt.Errorf("websocket dial failed: %w", err)
 	}
 
-	wsConn := &wsConn{conn: conn}
+	if d.dialer.EnableCompression {
+		conn.SetCompressionLevel(d.compressionLevel)
+	}
+
+	wsConn := &wsConn{conn: conn, logger: d.logger.With("remote_addr", u.Host)}
 
 	// Start ping loop to keep connection alive
 	go wsConn.pingLoop()
 
-	return wsConn, nil
+	return wsConn, resp.Header, nil
 }
 
 // URL returns the tunnel URL.
@@ -76,6 +176,141 @@ func (d *WSDialer) URL() string {
 	return d.tunnelURL
 }
 
+// netDialContext is plugged into the underlying websocket.Dialer as
+// NetDialContext so that, when a CONNECT proxy applies, the TCP connection it
+// hands back to gorilla/websocket is already tunneled through the proxy;
+// gorilla then layers the TLS handshake (for wss://) and WS upgrade on top of
+// it exactly as it would for a direct connection.
+func (d *WSDialer) netDialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	proxyURL, err := d.resolveProxyURL(addr)
+	if err != nil {
+		return nil, fmt.Errorf("resolve proxy for %s: %w", addr, err)
+	}
+	if proxyURL == nil {
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	}
+	return d.connectThroughProxy(ctx, proxyURL, addr)
+}
+
+// resolveProxyURL returns the CONNECT proxy to use for addr, preferring an
+// explicit ProxyURL and otherwise consulting http.ProxyFromEnvironment. A nil
+// result with a nil error means dial addr directly.
+func (d *WSDialer) resolveProxyURL(addr string) (*url.URL, error) {
+	if d.proxyURL != nil {
+		return d.proxyURL, nil
+	}
+
+	scheme := "http"
+	if strings.HasPrefix(d.tunnelURL, "wss://") {
+		scheme = "https"
+	}
+	req := &http.Request{URL: &url.URL{Scheme: scheme, Host: addr}}
+	return http.ProxyFromEnvironment(req)
+}
+
+// connectThroughProxy dials proxyURL and issues an HTTP CONNECT request for
+// addr, including Proxy-Authorization (Basic from proxyURL's userinfo, or
+// Bearer from ProxyAuthToken) when credentials are configured. The returned
+// conn is ready to speak whatever protocol addr expects (here: TLS then the
+// WebSocket upgrade).
+func (d *WSDialer) connectThroughProxy(ctx context.Context, proxyURL *url.URL, addr string) (net.Conn, error) {
+	proxyAddr := proxyURL.Host
+	if proxyURL.Port() == "" {
+		if proxyURL.Scheme == "https" {
+			proxyAddr = net.JoinHostPort(proxyURL.Hostname(), "443")
+		} else {
+			proxyAddr = net.JoinHostPort(proxyURL.Hostname(), "80")
+		}
+	}
+
+	var conn net.Conn
+	var err error
+	if proxyURL.Scheme == "https" {
+		conn, err = (&tls.Dialer{}).DialContext(ctx, "tcp", proxyAddr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", proxyAddr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dial proxy %s: %w", proxyAddr, err)
+	}
+
+	connectReq := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if auth := d.proxyAuthHeader(proxyURL); auth != "" {
+		connectReq.Header.Set("Proxy-Authorization", auth)
+	}
+
+	if err := connectReq.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("write CONNECT request: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, connectReq)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("read CONNECT response: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy CONNECT to %s failed: %s", addr, resp.Status)
+	}
+
+	// br may have buffered bytes the proxy sent right after the CONNECT
+	// response (pipelined by an over-eager proxy); carry them forward
+	// instead of dropping them.
+	if br.Buffered() > 0 {
+		buffered := make([]byte, br.Buffered())
+		_, _ = io.ReadFull(br, buffered)
+		return &prefixedConn{Conn: conn, prefix: buffered}, nil
+	}
+
+	return conn, nil
+}
+
+// proxyAuthHeader returns the Proxy-Authorization header value to send,
+// preferring a Bearer token over Basic auth from proxyURL's userinfo, or ""
+// if neither is configured.
+func (d *WSDialer) proxyAuthHeader(proxyURL *url.URL) string {
+	if d.proxyAuth != "" {
+		return "Bearer " + d.proxyAuth
+	}
+	if proxyURL.User != nil {
+		if password, ok := proxyURL.User.Password(); ok {
+			return "Basic " + basicAuth(proxyURL.User.Username(), password)
+		}
+	}
+	return ""
+}
+
+// prefixedConn prepends prefix to the first Read calls on an otherwise
+// unmodified net.Conn, for bytes already pulled off the wire by a buffered
+// reader before the caller took ownership of the connection.
+type prefixedConn struct {
+	net.Conn
+	prefix []byte
+}
+
+func (c *prefixedConn) Read(p []byte) (int, error) {
+	if len(c.prefix) > 0 {
+		n := copy(p, c.prefix)
+		c.prefix = c.prefix[n:]
+		return n, nil
+	}
+	return c.Conn.Read(p)
+}
+
+// basicAuth returns the base64 "user:password" value for an HTTP Basic
+// Authorization/Proxy-Authorization header.
+func basicAuth(username, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+}
+
 // wsConn wraps a websocket.Conn to implement io.ReadWriteCloser.
 type wsConn struct {
 	conn    *websocket.Conn
@@ -84,6 +319,13 @@ type wsConn struct {
 	buf     []byte
 	offset  int
 	closed  bool
+	logger  *slog.Logger
+
+	// onPingFailure, when set, is called instead of just logging and
+	// returning when a keepalive ping fails, so a wrapper like
+	// ResumableDialer can trigger a reconnect rather than let the
+	// connection sit dead until the next Read/Write notices.
+	onPingFailure func(error)
 }
 
 func (c *wsConn) Read(p []byte) (int, error) {
@@ -153,6 +395,10 @@ func (c *wsConn) pingLoop() {
 		err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second))
 		c.writeMu.Unlock()
 		if err != nil {
+			c.logger.Debug("ping loop: keepalive failed, stopping", "error", err)
+			if c.onPingFailure != nil {
+				c.onPingFailure(err)
+			}
 			return
 		}
 	}
@@ -4,17 +4,24 @@ import (
 	"bytes"
 	"context"
 	"crypto/rand"
+	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/hashicorp/yamux"
 	"github.com/puzpuzpuz/xsync/v3"
 	bridgev1 "github.com/vercel-eddie/bridge/api/go/bridge/v1"
 	"github.com/vercel-eddie/bridge/pkg/bidi"
+	"github.com/vercel-eddie/bridge/pkg/netutil"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
@@ -26,10 +33,11 @@ const (
 
 // pendingTunnel represents a client connection waiting for its server pair
 type pendingTunnel struct {
-	clientConn *websocket.Conn
-	ready      chan *websocket.Conn // receives the server connection when matched
-	done       chan struct{}        // closed when the tunnel is finished
-	cancel     context.CancelFunc
+	clientConn  *websocket.Conn
+	ready       chan *websocket.Conn // receives the server connection when matched (legacy 1:1 path)
+	readyStream chan net.Conn        // receives the paired yamux stream when the server side registered with multiplex=true
+	done        chan struct{}        // closed when the tunnel is finished
+	cancel      context.CancelFunc
 }
 
 // WSServer is a WebSocket server that tunnels connections to a target.
@@ -46,6 +54,25 @@ type WSServer struct {
 	pendingTunnels *xsync.MapOf[string, *pendingTunnel]
 
 	pairingTimeout time.Duration
+	logger         *slog.Logger
+
+	streamHandler func(net.Conn)
+	muxConfig     MuxConfig
+
+	// resumeSessions tracks in-flight /ssh and /tunnel sessions by the ID
+	// handleSSH or a paired /tunnel registration hands out, so a
+	// reconnecting client can resume one instead of dialing (or pairing)
+	// fresh. Keyed by session ID.
+	resumeSessions  *xsync.MapOf[string, *resumeSession]
+	sshResumeWindow time.Duration
+
+	// tokenVerifier, when set, requires /tunnel registrations to present a
+	// bearer token it accepts before the registration is paired. Nil skips
+	// authentication entirely, preserving today's behavior.
+	tokenVerifier TokenVerifier
+
+	enableCompression bool
+	compressionLevel  int
 }
 
 // WSServerConfig configures the WebSocket server.
@@ -54,6 +81,51 @@ type WSServerConfig struct {
 	Dialer         Dialer        // Dialer for establishing connections to the target
 	Name           string        // Name of the sandbox
 	PairingTimeout time.Duration // How long to wait for server to pair with client (default 60s)
+
+	// StreamHandler, when set, registers a /mux endpoint: each WebSocket
+	// connected there carries a yamux session, and StreamHandler is called
+	// once per logical stream the peer opens on it. This lets many logical
+	// connections (SSH sessions, port-forwards, ...) share a single WS
+	// handshake and CONNECT proxy round trip, unlike the single-connection
+	// /ssh and /tunnel endpoints, which keep using Dialer directly.
+	StreamHandler func(net.Conn)
+
+	// Mux configures the yamux session backing the /mux endpoint. Only
+	// meaningful when StreamHandler is set.
+	Mux MuxConfig
+
+	// SSHResumeWindow bounds how long a /ssh or /tunnel resumeSession is kept
+	// alive waiting for a disconnected client to resume it before it's torn
+	// down. Defaults to 5 minutes.
+	SSHResumeWindow time.Duration
+
+	// Logger carries fixed contextual attributes onto every log line this
+	// server emits. Defaults to slog.Default() when nil.
+	Logger *slog.Logger
+
+	// TokenVerifier, when set, requires /tunnel registrations to present a
+	// bearer token (either an Authorization: Bearer header on the upgrade
+	// request or the registration's auth_token field) that it accepts.
+	// Nil disables registration authentication entirely.
+	TokenVerifier TokenVerifier
+
+	// ReadBufferSize and WriteBufferSize size the upgrader's per-connection
+	// I/O buffers. Default to 32 KiB each.
+	ReadBufferSize  int
+	WriteBufferSize int
+
+	// EnableCompression negotiates permessage-deflate on every upgrade,
+	// worthwhile for the repetitive payloads (HTTP, SSH banners, protobuf
+	// control messages) tunneled connections usually carry. A /tunnel
+	// registration can still opt a single connection out via
+	// Registration.disable_compression, for payloads that are already
+	// compressed and would only pay the deflate overhead for nothing.
+	EnableCompression bool
+
+	// CompressionLevel is passed to SetCompressionLevel on every upgraded
+	// connection when EnableCompression is set. Defaults to
+	// websocket.DefaultCompression.
+	CompressionLevel int
 }
 
 // NewWSServer creates a new WebSocket tunnel server.
@@ -68,16 +140,48 @@ func NewWSServer(cfg WSServerConfig) *WSServer {
 		pairingTimeout = 60 * time.Second
 	}
 
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	sshResumeWindow := cfg.SSHResumeWindow
+	if sshResumeWindow == 0 {
+		sshResumeWindow = defaultSSHResumeWindow
+	}
+
+	readBufferSize := cfg.ReadBufferSize
+	if readBufferSize == 0 {
+		readBufferSize = 32 * 1024
+	}
+	writeBufferSize := cfg.WriteBufferSize
+	if writeBufferSize == 0 {
+		writeBufferSize = 32 * 1024
+	}
+	compressionLevel := cfg.CompressionLevel
+	if compressionLevel == 0 {
+		compressionLevel = websocket.DefaultCompression
+	}
+
 	s := &WSServer{
-		addr:           addr,
-		dialer:         cfg.Dialer,
-		name:           cfg.Name,
-		conns:          xsync.NewMapOf[*websocket.Conn, struct{}](),
-		pendingTunnels: xsync.NewMapOf[string, *pendingTunnel](),
-		pairingTimeout: pairingTimeout,
+		addr:              addr,
+		dialer:            cfg.Dialer,
+		name:              cfg.Name,
+		conns:             xsync.NewMapOf[*websocket.Conn, struct{}](),
+		pendingTunnels:    xsync.NewMapOf[string, *pendingTunnel](),
+		pairingTimeout:    pairingTimeout,
+		logger:            logger,
+		streamHandler:     cfg.StreamHandler,
+		muxConfig:         cfg.Mux,
+		resumeSessions:    xsync.NewMapOf[string, *resumeSession](),
+		sshResumeWindow:   sshResumeWindow,
+		tokenVerifier:     cfg.TokenVerifier,
+		enableCompression: cfg.EnableCompression,
+		compressionLevel:  compressionLevel,
 		upgrader: websocket.Upgrader{
-			ReadBufferSize:  32 * 1024,
-			WriteBufferSize: 32 * 1024,
+			ReadBufferSize:    readBufferSize,
+			WriteBufferSize:   writeBufferSize,
+			EnableCompression: cfg.EnableCompression,
 			CheckOrigin: func(r *http.Request) bool {
 				return true // Allow all origins for tunnel
 			},
@@ -88,10 +192,22 @@ func NewWSServer(cfg WSServerConfig) *WSServer {
 	mux.HandleFunc("/health", s.handleHealth)
 	mux.HandleFunc("/ssh", s.handleSSH)
 	mux.HandleFunc("/tunnel", s.handleTunnel)
+	if s.streamHandler != nil {
+		mux.HandleFunc("/mux", s.handleMux)
+	}
 
 	s.httpServer = &http.Server{
-		Addr:         addr,
-		Handler:      mux,
+		Addr: addr,
+		// CONNECT requests carry an authority-form URL (host:port, no
+		// path) that the mux can't route, so intercept them before
+		// falling through to the WS-tunneled endpoints above.
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodConnect {
+				s.handleConnect(w, r)
+				return
+			}
+			mux.ServeHTTP(w, r)
+		}),
 		ReadTimeout:  0, // No timeout for WebSocket
 		WriteTimeout: 0,
 	}
@@ -99,20 +215,67 @@ func NewWSServer(cfg WSServerConfig) *WSServer {
 	return s
 }
 
+// handleConnect serves plain HTTP CONNECT requests (e.g. from curl or
+// kubectl configured with --proxy/HTTPS_PROXY) by hijacking the client
+// connection and bridging it to the same target s.dialer reaches for WS
+// traffic, so one proxy port serves both protocols.
+func (s *WSServer) handleConnect(w http.ResponseWriter, r *http.Request) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection does not support hijacking", http.StatusInternalServerError)
+		return
+	}
+
+	targetConn, err := s.dialer.Dial(r.Context())
+	if err != nil {
+		s.logger.Error("CONNECT: failed to dial target", "error", err, "remote", r.RemoteAddr)
+		http.Error(w, "failed to dial target", http.StatusBadGateway)
+		return
+	}
+
+	clientConn, _, err := hijacker.Hijack()
+	if err != nil {
+		targetConn.Close()
+		s.logger.Error("CONNECT: failed to hijack connection", "error", err, "remote", r.RemoteAddr)
+		return
+	}
+
+	if _, err := clientConn.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n")); err != nil {
+		s.logger.Error("CONNECT: failed to write 200 response", "error", err, "remote", r.RemoteAddr)
+		clientConn.Close()
+		targetConn.Close()
+		return
+	}
+
+	s.logger.Debug("CONNECT tunnel established", "remote", r.RemoteAddr, "target", r.Host)
+	bidi.New(clientConn, targetConn).Wait(context.Background())
+	s.logger.Debug("CONNECT tunnel closed", "remote", r.RemoteAddr, "target", r.Host)
+}
+
 func (s *WSServer) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("X-Bridge-Name", s.name)
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("ok"))
 }
 
-func (s *WSServer) handleSSH(w http.ResponseWriter, r *http.Request) {
+// handleSSH is defined in wsserver_resume.go: it either attaches the
+// connection to an existing resumeSession (Sec-Bridge-Resume-Id request
+// header) or dials a fresh target and starts one, so a dropped WebSocket
+// resumes rather than killing the SSH session.
+
+// handleMux upgrades the connection to a WebSocket, wraps it in a yamux
+// server session, and dispatches every stream the peer opens on it to
+// s.streamHandler, so callers that multiplex through a MuxDialer (SSH
+// sessions, port-forwards, ...) only pay one WS handshake total.
+func (s *WSServer) handleMux(w http.ResponseWriter, r *http.Request) {
 	wsConn, err := s.upgrader.Upgrade(w, r, http.Header{
 		"X-Bridge-Name": []string{s.name},
 	})
 	if err != nil {
-		slog.Error("failed to upgrade websocket", "error", err, "remote", r.RemoteAddr)
+		s.logger.Error("failed to upgrade websocket for mux", "error", err, "remote", r.RemoteAddr)
 		return
 	}
+	s.applyCompression(wsConn)
 
 	s.conns.Store(wsConn, struct{}{})
 	defer func() {
@@ -121,32 +284,42 @@ func (s *WSServer) handleSSH(w http.ResponseWriter, r *http.Request) {
 	}()
 
 	remoteAddr := r.RemoteAddr
-	slog.Info("SSH websocket tunnel connected", "remote", remoteAddr)
+	s.logger.Info("mux websocket tunnel connected", "remote", remoteAddr)
 
-	// Dial the target
-	targetConn, err := s.dialer.Dial(r.Context())
+	session, err := yamux.Server(&wsConnAdapter{conn: wsConn}, s.muxConfig.yamuxConfig())
 	if err != nil {
-		slog.Error("failed to dial target", "error", err, "remote", remoteAddr)
+		s.logger.Error("failed to establish yamux session", "error", err, "remote", remoteAddr)
 		return
 	}
-	defer targetConn.Close()
-
-	slog.Info("connected to SSH target", "remote", remoteAddr)
+	defer session.Close()
 
-	// Create adapters for bidirectional copy
-	wsAdapter := &wsConnAdapter{conn: wsConn}
+	for {
+		stream, err := session.Accept()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, yamux.ErrSessionShutdown) {
+				s.logger.Debug("mux session accept error, closing", "error", err, "remote", remoteAddr)
+			}
+			break
+		}
 
-	bidi.New(wsAdapter, targetConn).Wait(context.Background())
+		go func(stream net.Conn) {
+			defer netutil.HandleCrash(s.logger, stream)
+			s.streamHandler(stream)
+		}(stream)
+	}
 
-	slog.Info("SSH websocket tunnel disconnected", "remote", remoteAddr)
+	s.logger.Info("mux websocket tunnel disconnected", "remote", remoteAddr)
 }
 
-func (s *WSServer) handleTunnel(w http.ResponseWriter, r *http.Request) {
+// registerTunnel runs the normal dispatcher-pairing flow for a fresh
+// /tunnel registration. handleTunnel (wsserver_tunnel_resume.go) calls this
+// once it's ruled out a resume attach.
+func (s *WSServer) registerTunnel(w http.ResponseWriter, r *http.Request) {
 	wsConn, err := s.upgrader.Upgrade(w, r, http.Header{
 		"X-Bridge-Name": []string{s.name},
 	})
 	if err != nil {
-		slog.Error("failed to upgrade websocket for tunnel", "error", err, "remote", r.RemoteAddr)
+		s.logger.Error("failed to upgrade websocket for tunnel", "error", err, "remote", r.RemoteAddr)
 		return
 	}
 
@@ -157,7 +330,7 @@ func (s *WSServer) handleTunnel(w http.ResponseWriter, r *http.Request) {
 	}()
 
 	remoteAddr := r.RemoteAddr
-	slog.Debug("tunnel connection established", "remote", remoteAddr)
+	s.logger.Debug("tunnel connection established", "remote", remoteAddr)
 
 	// Set read deadline for registration message
 	_ = wsConn.SetReadDeadline(time.Now().Add(registrationTimeout))
@@ -165,7 +338,7 @@ func (s *WSServer) handleTunnel(w http.ResponseWriter, r *http.Request) {
 	// Wait for registration message
 	messageType, data, err := wsConn.ReadMessage()
 	if err != nil {
-		slog.Error("failed to read registration message", "error", err, "remote", remoteAddr)
+		s.logger.Error("failed to read registration message", "error", err, "remote", remoteAddr)
 		return
 	}
 
@@ -173,46 +346,101 @@ func (s *WSServer) handleTunnel(w http.ResponseWriter, r *http.Request) {
 	_ = wsConn.SetReadDeadline(time.Time{})
 
 	if messageType != websocket.BinaryMessage && messageType != websocket.TextMessage {
-		slog.Error("unexpected message type for registration", "type", messageType, "remote", remoteAddr)
+		s.logger.Error("unexpected message type for registration", "type", messageType, "remote", remoteAddr)
 		return
 	}
 
 	// Parse the registration message
 	var msg bridgev1.Message
 	if err := proto.Unmarshal(data, &msg); err != nil {
-		slog.Error("failed to parse registration message", "error", err, "remote", remoteAddr)
+		s.logger.Error("failed to parse registration message", "error", err, "remote", remoteAddr)
 		return
 	}
 
 	reg := msg.GetRegistration()
 	if reg == nil {
-		slog.Error("registration message missing registration field", "remote", remoteAddr)
+		s.logger.Error("registration message missing registration field", "remote", remoteAddr)
 		return
 	}
 
-	slog.Debug("received tunnel registration",
+	s.logger.Debug("received tunnel registration",
 		"remote", remoteAddr,
 		"is_server", reg.GetIsServer(),
 		"connection_key", reg.GetConnectionKey(),
 		"has_bypass_secret", reg.GetProtectionBypassSecret() != "",
 	)
 
+	s.applyCompression(wsConn)
+	if reg.GetDisableCompression() {
+		wsConn.EnableWriteCompression(false)
+	}
+
+	logger := s.logger
+	if s.tokenVerifier != nil {
+		claims, err := s.verifyRegistration(r, reg)
+		if err != nil {
+			s.logger.Warn("tunnel registration rejected", "error", err, "remote", remoteAddr)
+			s.sendError(wsConn, fmt.Sprintf("registration rejected: %v", err))
+			return
+		}
+		logger = logger.With("auth_subject", claims.Subject, "auth_role", claims.Role)
+	}
+
 	// Derive the public sandbox URL from the Host header so the dispatcher
 	// receives a routable URL rather than the server's bind address.
 	sandboxURL := "https://" + r.Host
 
 	if reg.GetIsServer() {
-		s.handleServerRegistration(wsConn, reg, remoteAddr)
+		s.handleServerRegistration(logger, wsConn, reg, remoteAddr)
 	} else {
-		s.handleClientRegistration(r.Context(), wsConn, reg, remoteAddr, sandboxURL)
+		s.handleClientRegistration(r.Context(), logger, wsConn, reg, remoteAddr, sandboxURL)
+	}
+}
+
+// verifyRegistration checks reg's bearer token (from the upgrade request's
+// Authorization header or the registration's own auth_token field) against
+// s.tokenVerifier and enforces the sandbox/role/connection_key claims the
+// registration itself must satisfy. Only called when s.tokenVerifier is set.
+func (s *WSServer) verifyRegistration(r *http.Request, reg *bridgev1.Message_Registration) (TokenClaims, error) {
+	token := reg.GetAuthToken()
+	if token == "" {
+		if authz := r.Header.Get("Authorization"); authz != "" {
+			token, _ = strings.CutPrefix(authz, "Bearer ")
+		}
 	}
+	if token == "" {
+		return TokenClaims{}, fmt.Errorf("no bearer token presented")
+	}
+
+	claims, err := s.tokenVerifier.Verify(token)
+	if err != nil {
+		return TokenClaims{}, fmt.Errorf("token verification failed: %w", err)
+	}
+
+	if claims.Sandbox != "" && claims.Sandbox != s.name {
+		return TokenClaims{}, fmt.Errorf("token sandbox claim %q does not match %q", claims.Sandbox, s.name)
+	}
+
+	wantRole := "client"
+	if reg.GetIsServer() {
+		wantRole = "server"
+	}
+	if claims.Role != "" && claims.Role != wantRole {
+		return TokenClaims{}, fmt.Errorf("token role claim %q does not match registration (want %q)", claims.Role, wantRole)
+	}
+
+	if reg.GetIsServer() && claims.ConnectionKey != "" && claims.ConnectionKey != reg.GetConnectionKey() {
+		return TokenClaims{}, fmt.Errorf("token connection_key claim does not match registration")
+	}
+
+	return claims, nil
 }
 
-func (s *WSServer) handleClientRegistration(ctx context.Context, wsConn *websocket.Conn, reg *bridgev1.Message_Registration, remoteAddr string, sandboxURL string) {
+func (s *WSServer) handleClientRegistration(ctx context.Context, logger *slog.Logger, wsConn *websocket.Conn, reg *bridgev1.Message_Registration, remoteAddr string, sandboxURL string) {
 	functionURL := reg.GetFunctionUrl()
 
 	if functionURL == "" {
-		slog.Error("client registration missing function_url", "remote", remoteAddr)
+		logger.Error("client registration missing function_url", "remote", remoteAddr)
 		s.sendError(wsConn, "registration missing function_url")
 		return
 	}
@@ -220,7 +448,7 @@ func (s *WSServer) handleClientRegistration(ctx context.Context, wsConn *websock
 	// Generate a random connection key for pairing
 	keyBytes := make([]byte, 16)
 	if _, err := rand.Read(keyBytes); err != nil {
-		slog.Error("failed to generate connection key", "error", err, "remote", remoteAddr)
+		logger.Error("failed to generate connection key", "error", err, "remote", remoteAddr)
 		s.sendError(wsConn, "internal error generating connection key")
 		return
 	}
@@ -232,10 +460,11 @@ func (s *WSServer) handleClientRegistration(ctx context.Context, wsConn *websock
 
 	// Create pending tunnel entry
 	pending := &pendingTunnel{
-		clientConn: wsConn,
-		ready:      make(chan *websocket.Conn, 1),
-		done:       make(chan struct{}),
-		cancel:     cancel,
+		clientConn:  wsConn,
+		ready:       make(chan *websocket.Conn, 1),
+		readyStream: make(chan net.Conn, 1),
+		done:        make(chan struct{}),
+		cancel:      cancel,
 	}
 
 	s.pendingTunnels.Store(connectionKey, pending)
@@ -252,35 +481,71 @@ func (s *WSServer) handleClientRegistration(ctx context.Context, wsConn *websock
 
 	// POST to the dispatcher to trigger server connection, including connection_key
 	if err := s.notifyDispatcher(pairCtx, functionURL, sandboxURL, connectionKey, reg.GetProtectionBypassSecret()); err != nil {
-		slog.Error("failed to notify dispatcher", "error", err, "function_url", functionURL, "remote", remoteAddr)
+		logger.Error("failed to notify dispatcher", "error", err, "function_url", functionURL, "remote", remoteAddr)
 		s.sendError(wsConn, fmt.Sprintf("failed to connect to dispatcher: %v", err))
 		return
 	}
 
-	slog.Debug("notified dispatcher, waiting for server connection",
+	logger.Debug("notified dispatcher, waiting for server connection",
 		"connection_key", connectionKey,
 		"function_url", functionURL,
 		"remote", remoteAddr,
 	)
 
-	// Wait for server connection
+	// Wait for the server side to pair with us, either as a whole new
+	// WebSocket (legacy) or as a stream opened on an already-multiplexed one.
+	if reg.GetMultiplex() {
+		select {
+		case stream := <-pending.readyStream:
+			logger.Info("tunnel paired (multiplexed)",
+				"connection_key", connectionKey,
+				"client", remoteAddr,
+			)
+
+			bidi.New(&wsConnAdapter{conn: wsConn}, stream).Wait(context.Background())
+			close(pending.done)
+
+			logger.Debug("multiplexed tunnel closed", "connection_key", connectionKey)
+
+		case <-pairCtx.Done():
+			logger.Error("timeout waiting for multiplexed server stream",
+				"connection_key", connectionKey,
+				"remote", remoteAddr,
+			)
+			s.sendError(wsConn, fmt.Sprintf("timeout waiting for server connection for connection_key %s", connectionKey))
+			close(pending.done)
+		}
+		return
+	}
+
 	select {
 	case serverConn := <-pending.ready:
-		slog.Info("tunnel paired",
+		logger.Info("tunnel paired",
 			"connection_key", connectionKey,
 			"client", remoteAddr,
 		)
 
-		// Relay messages between client and server, preserving message boundaries
-		relayMessages(wsConn, serverConn)
-
-		// Signal that we're done so the server handler can exit
+		// Hand serverConn off to a resumeSession so a dropped client
+		// WebSocket can reattach instead of redoing the dispatcher pairing
+		// above. The server side's job here is done once the handoff
+		// succeeds, so signal pending.done right away rather than holding
+		// it open for the resumable session's full (possibly
+		// multi-reconnect) lifetime.
+		sess, err := s.newTunnelResumeSession(wsConn, serverConn)
+		if err != nil {
+			logger.Error("failed to start resumable tunnel session", "error", err, "connection_key", connectionKey)
+			close(pending.done)
+			return
+		}
 		close(pending.done)
+		logger.Debug("tunnel handed off to resumable session", "connection_key", connectionKey, "session_id", sess.id)
 
-		slog.Debug("tunnel closed", "connection_key", connectionKey)
+		s.pumpClientToTarget(sess, wsConn, remoteAddr)
+
+		logger.Debug("tunnel closed", "connection_key", connectionKey)
 
 	case <-pairCtx.Done():
-		slog.Error("timeout waiting for server connection",
+		logger.Error("timeout waiting for server connection",
 			"connection_key", connectionKey,
 			"remote", remoteAddr,
 		)
@@ -289,18 +554,23 @@ func (s *WSServer) handleClientRegistration(ctx context.Context, wsConn *websock
 	}
 }
 
-func (s *WSServer) handleServerRegistration(wsConn *websocket.Conn, reg *bridgev1.Message_Registration, remoteAddr string) {
+func (s *WSServer) handleServerRegistration(logger *slog.Logger, wsConn *websocket.Conn, reg *bridgev1.Message_Registration, remoteAddr string) {
+	if reg.GetMultiplex() {
+		s.handleMultiplexedServer(logger, wsConn, remoteAddr)
+		return
+	}
+
 	connectionKey := reg.GetConnectionKey()
 
 	if connectionKey == "" {
-		slog.Error("server registration missing connection_key", "remote", remoteAddr)
+		logger.Error("server registration missing connection_key", "remote", remoteAddr)
 		s.sendError(wsConn, "registration missing connection_key")
 		return
 	}
 
 	pending, ok := s.pendingTunnels.LoadAndDelete(connectionKey)
 	if !ok {
-		slog.Error("no pending client for server registration",
+		logger.Error("no pending client for server registration",
 			"connection_key", connectionKey,
 			"remote", remoteAddr,
 		)
@@ -308,7 +578,7 @@ func (s *WSServer) handleServerRegistration(wsConn *websocket.Conn, reg *bridgev
 		return
 	}
 
-	slog.Debug("server registered, pairing with client",
+	logger.Debug("server registered, pairing with client",
 		"connection_key", connectionKey,
 		"remote", remoteAddr,
 	)
@@ -319,9 +589,9 @@ func (s *WSServer) handleServerRegistration(wsConn *websocket.Conn, reg *bridgev
 		// Successfully paired - wait for the tunnel to complete
 		// The client handler will close the done channel when bidi copy finishes
 		<-pending.done
-		slog.Debug("server handler exiting after tunnel closed", "connection_key", connectionKey)
+		logger.Debug("server handler exiting after tunnel closed", "connection_key", connectionKey)
 	default:
-		slog.Error("failed to pair server with client",
+		logger.Error("failed to pair server with client",
 			"connection_key", connectionKey,
 			"remote", remoteAddr,
 		)
@@ -329,6 +599,112 @@ func (s *WSServer) handleServerRegistration(wsConn *websocket.Conn, reg *bridgev
 	}
 }
 
+// handleMultiplexedServer wraps an already-upgraded /tunnel WebSocket
+// registered with multiplex=true into a long-lived yamux session: rather
+// than pairing this one WebSocket with a single client and exiting once
+// that tunnel closes, the server keeps accepting streams for as long as the
+// WebSocket stays up, one per client connection_key, so repeat client
+// registrations reach this sandbox without a fresh dispatcher POST plus WS
+// handshake each time.
+func (s *WSServer) handleMultiplexedServer(logger *slog.Logger, wsConn *websocket.Conn, remoteAddr string) {
+	s.conns.Store(wsConn, struct{}{})
+	defer func() {
+		s.conns.Delete(wsConn)
+		wsConn.Close()
+	}()
+
+	session, err := yamux.Server(&wsConnAdapter{conn: wsConn}, s.muxConfig.yamuxConfig())
+	if err != nil {
+		logger.Error("failed to establish multiplexed tunnel session", "error", err, "remote", remoteAddr)
+		return
+	}
+	defer session.Close()
+
+	logger.Info("multiplexed tunnel server registered", "remote", remoteAddr)
+
+	for {
+		stream, err := session.Accept()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, yamux.ErrSessionShutdown) {
+				logger.Debug("multiplexed tunnel session accept error, closing", "error", err, "remote", remoteAddr)
+			}
+			break
+		}
+		go s.handleMultiplexedStream(stream, remoteAddr)
+	}
+
+	logger.Info("multiplexed tunnel server disconnected", "remote", remoteAddr)
+}
+
+// handleMultiplexedStream reads the connection_key a client stamps onto a
+// freshly opened stream (see writeMuxStreamHeader) and pairs it with the
+// matching pending client registration, mirroring handleServerRegistration's
+// legacy pairing but over a yamux stream instead of a whole new WebSocket.
+func (s *WSServer) handleMultiplexedStream(stream net.Conn, remoteAddr string) {
+	defer netutil.HandleCrash(s.logger, stream)
+
+	connectionKey, err := readMuxStreamHeader(stream)
+	if err != nil {
+		s.logger.Error("failed to read multiplexed stream header", "error", err, "remote", remoteAddr)
+		stream.Close()
+		return
+	}
+
+	pending, ok := s.pendingTunnels.LoadAndDelete(connectionKey)
+	if !ok {
+		s.logger.Error("no pending client for multiplexed stream", "connection_key", connectionKey, "remote", remoteAddr)
+		stream.Close()
+		return
+	}
+
+	select {
+	case pending.readyStream <- stream:
+		<-pending.done
+	default:
+		s.logger.Error("failed to pair multiplexed stream with client", "connection_key", connectionKey, "remote", remoteAddr)
+		stream.Close()
+	}
+}
+
+// writeMuxStreamHeader stamps connectionKey onto a freshly opened yamux
+// stream as a 2-byte length prefix followed by the key, so the server side
+// of a multiplexed /tunnel session knows which pending client to pair the
+// stream with.
+func writeMuxStreamHeader(conn net.Conn, connectionKey string) error {
+	key := []byte(connectionKey)
+	if len(key) > 0xFFFF {
+		return fmt.Errorf("connection key too long to frame: %d bytes", len(key))
+	}
+	header := make([]byte, 2+len(key))
+	binary.BigEndian.PutUint16(header, uint16(len(key)))
+	copy(header[2:], key)
+	_, err := conn.Write(header)
+	return err
+}
+
+// readMuxStreamHeader reads the connection_key writeMuxStreamHeader wrote.
+func readMuxStreamHeader(conn net.Conn) (string, error) {
+	var lenBuf [2]byte
+	if _, err := io.ReadFull(conn, lenBuf[:]); err != nil {
+		return "", fmt.Errorf("read header length: %w", err)
+	}
+	n := binary.BigEndian.Uint16(lenBuf[:])
+	key := make([]byte, n)
+	if _, err := io.ReadFull(conn, key); err != nil {
+		return "", fmt.Errorf("read connection key: %w", err)
+	}
+	return string(key), nil
+}
+
+// applyCompression sets conn's write compression level when compression is
+// enabled server-wide. Callers that also want to honor a per-connection
+// Registration.disable_compression opt-out do so with conn.EnableWriteCompression(false) instead, after calling this.
+func (s *WSServer) applyCompression(conn *websocket.Conn) {
+	if s.enableCompression {
+		conn.SetCompressionLevel(s.compressionLevel)
+	}
+}
+
 func (s *WSServer) sendError(wsConn *websocket.Conn, errMsg string) {
 	msg := &bridgev1.Message{
 		Error: errMsg,
@@ -336,64 +712,19 @@ func (s *WSServer) sendError(wsConn *websocket.Conn, errMsg string) {
 	}
 	data, err := proto.Marshal(msg)
 	if err != nil {
-		slog.Error("failed to marshal error message", "error", err)
+		s.logger.Error("failed to marshal error message", "error", err)
 		return
 	}
 	if err := wsConn.WriteMessage(websocket.BinaryMessage, data); err != nil {
-		slog.Error("failed to send error message", "error", err)
+		s.logger.Error("failed to send error message", "error", err)
 	}
 }
 
-// relayMessages relays WebSocket messages between two connections,
-// preserving message boundaries for proper protobuf parsing.
-func relayMessages(conn1, conn2 *websocket.Conn) {
-	done := make(chan struct{}, 2)
-
-	// conn1 -> conn2
-	go func() {
-		defer func() { done <- struct{}{} }()
-		for {
-			messageType, data, err := conn1.ReadMessage()
-			if err != nil {
-				if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
-					slog.Debug("relay read error from conn1", "error", err)
-				}
-				return
-			}
-			if err := conn2.WriteMessage(messageType, data); err != nil {
-				slog.Debug("relay write error to conn2", "error", err)
-				return
-			}
-		}
-	}()
-
-	// conn2 -> conn1
-	go func() {
-		defer func() { done <- struct{}{} }()
-		for {
-			messageType, data, err := conn2.ReadMessage()
-			if err != nil {
-				if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
-					slog.Debug("relay read error from conn2", "error", err)
-				}
-				return
-			}
-			if err := conn1.WriteMessage(messageType, data); err != nil {
-				slog.Debug("relay write error to conn1", "error", err)
-				return
-			}
-		}
-	}()
-
-	// Wait for one direction to finish
-	<-done
-}
-
 func (s *WSServer) notifyDispatcher(ctx context.Context, functionURL string, sandboxURL string, connectionKey string, protectionBypassSecret string) error {
 	// Build the connect URL
 	connectURL := functionURL + "/__tunnel/connect"
 
-	slog.Debug("notifying dispatcher",
+	s.logger.Debug("notifying dispatcher",
 		"connect_url", connectURL,
 		"connection_key", connectionKey,
 		"has_bypass_secret", protectionBypassSecret != "",
@@ -439,13 +770,13 @@ func (s *WSServer) notifyDispatcher(ctx context.Context, functionURL string, san
 
 // Start starts the WebSocket server.
 func (s *WSServer) Start() error {
-	slog.Info("starting websocket tunnel server", "addr", s.addr)
+	s.logger.Info("starting websocket tunnel server", "addr", s.addr)
 	return s.httpServer.ListenAndServe()
 }
 
 // Shutdown gracefully shuts down the server.
 func (s *WSServer) Shutdown(ctx context.Context) error {
-	slog.Info("shutting down websocket tunnel server")
+	s.logger.Info("shutting down websocket tunnel server")
 
 	// Close all active WebSocket connections
 	s.conns.Range(func(conn *websocket.Conn, _ struct{}) bool {
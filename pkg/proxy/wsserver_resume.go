@@ -0,0 +1,290 @@
+package proxy
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/vercel-eddie/bridge/pkg/bidi"
+)
+
+// defaultSSHResumeWindow bounds how long a /ssh session's target connection
+// is kept alive waiting for a disconnected client to resume it before it's
+// torn down.
+const defaultSSHResumeWindow = 5 * time.Minute
+
+// maxResumeSendBuffer caps how many bytes of target output a resumeSession
+// buffers for replay to a reconnecting client.
+const maxResumeSendBuffer = 4 * 1024 * 1024
+
+// resumeSession tracks one logical connection to the target (a dialed /ssh
+// target, or a paired /tunnel server WebSocket wrapped in a wsConnAdapter)
+// across however many client WebSocket (re)connections it takes to deliver
+// it, mirroring ResumableDialer's bookkeeping on the client side.
+type resumeSession struct {
+	id     string
+	target io.ReadWriteCloser
+
+	mu           sync.Mutex
+	clientConn   *websocket.Conn
+	sendBuf      []byte
+	sendBufStart int64 // offset of sendBuf[0] in the target->client stream
+	recvOffset   int64 // total bytes ever received from any client conn
+	closed       bool
+	expireTimer  *time.Timer
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// SSHStreamHandler returns a StreamHandler suitable for WSServerConfig.
+// StreamHandler: it dials a fresh target and bidi-pipes it for every yamux
+// stream the /mux endpoint accepts, the multiplexed alternative to /ssh —
+// many SSH sessions share one WebSocket handshake instead of one each, at
+// the cost of the per-session resume support handleSSH provides.
+func (s *WSServer) SSHStreamHandler() func(net.Conn) {
+	return func(stream net.Conn) {
+		targetConn, err := s.dialer.Dial(context.Background())
+		if err != nil {
+			s.logger.Error("failed to dial target for multiplexed SSH stream", "error", err)
+			stream.Close()
+			return
+		}
+		bidi.New(stream, targetConn).Wait(context.Background())
+	}
+}
+
+// handleSSH upgrades the connection, either attaching it to an existing
+// resumeSession named by the Sec-Bridge-Resume-Id request header, or dialing
+// a fresh target and starting a new one.
+func (s *WSServer) handleSSH(w http.ResponseWriter, r *http.Request) {
+	if resumeID := r.Header.Get(headerResumeSessionID); resumeID != "" {
+		if sess, ok := s.resumeSessions.Load(resumeID); ok {
+			s.attachSSHSession(w, r, sess)
+			return
+		}
+		s.logger.Warn("resume requested for unknown or expired session, starting fresh", "session_id", resumeID)
+	}
+	s.newSSHSession(w, r)
+}
+
+func (s *WSServer) newSSHSession(w http.ResponseWriter, r *http.Request) {
+	remoteAddr := r.RemoteAddr
+
+	targetConn, err := s.dialer.Dial(r.Context())
+	if err != nil {
+		s.logger.Error("failed to dial target", "error", err, "remote", remoteAddr)
+		http.Error(w, "failed to dial target", http.StatusBadGateway)
+		return
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		s.logger.Error("failed to generate session id", "error", err, "remote", remoteAddr)
+		targetConn.Close()
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	wsConn, err := s.upgrader.Upgrade(w, r, http.Header{
+		"X-Bridge-Name":       []string{s.name},
+		headerResumeSessionID: []string{id},
+	})
+	if err != nil {
+		s.logger.Error("failed to upgrade websocket", "error", err, "remote", remoteAddr)
+		targetConn.Close()
+		return
+	}
+	s.applyCompression(wsConn)
+
+	sess := &resumeSession{id: id, target: targetConn, clientConn: wsConn}
+	s.resumeSessions.Store(id, sess)
+
+	s.conns.Store(wsConn, struct{}{})
+	s.logger.Info("SSH websocket tunnel connected", "remote", remoteAddr, "session_id", id)
+
+	go s.pumpTargetToClient(sess)
+	s.pumpClientToTarget(sess, wsConn, remoteAddr)
+}
+
+// attachSSHSession re-attaches a reconnecting client to sess: it replays
+// whatever target output the client missed (per the Sec-Bridge-Resume-Offset
+// request header) and reports back how much of the client's own stream the
+// server already has, so the client can trim its own replay buffer.
+func (s *WSServer) attachSSHSession(w http.ResponseWriter, r *http.Request, sess *resumeSession) {
+	remoteAddr := r.RemoteAddr
+
+	sess.mu.Lock()
+	if sess.closed {
+		sess.mu.Unlock()
+		s.logger.Warn("resume requested for already-closed session, starting fresh", "session_id", sess.id)
+		s.newSSHSession(w, r)
+		return
+	}
+	if sess.expireTimer != nil {
+		sess.expireTimer.Stop()
+		sess.expireTimer = nil
+	}
+	recvOffset := sess.recvOffset
+	sess.mu.Unlock()
+
+	wsConn, err := s.upgrader.Upgrade(w, r, http.Header{
+		"X-Bridge-Name":       []string{s.name},
+		headerResumeSessionID: []string{sess.id},
+		headerResumeOffset:    []string{strconv.FormatInt(recvOffset, 10)},
+	})
+	if err != nil {
+		s.logger.Error("failed to upgrade resumed websocket", "error", err, "remote", remoteAddr, "session_id", sess.id)
+		return
+	}
+	s.applyCompression(wsConn)
+
+	sess.mu.Lock()
+	if ackStr := r.Header.Get(headerResumeOffset); ackStr != "" {
+		if ack, err := strconv.ParseInt(ackStr, 10, 64); err == nil && ack > sess.sendBufStart {
+			drop := ack - sess.sendBufStart
+			if drop >= int64(len(sess.sendBuf)) {
+				sess.sendBuf = nil
+			} else {
+				sess.sendBuf = append([]byte(nil), sess.sendBuf[drop:]...)
+			}
+			sess.sendBufStart = ack
+		}
+	}
+	replay := append([]byte(nil), sess.sendBuf...)
+	sess.clientConn = wsConn
+	sess.mu.Unlock()
+
+	if len(replay) > 0 {
+		if err := wsConn.WriteMessage(websocket.BinaryMessage, replay); err != nil {
+			s.logger.Error("failed to replay buffered output to resumed client", "error", err, "session_id", sess.id)
+			wsConn.Close()
+			return
+		}
+	}
+
+	s.conns.Store(wsConn, struct{}{})
+	s.logger.Info("SSH websocket tunnel resumed", "remote", remoteAddr, "session_id", sess.id, "replayed_bytes", len(replay))
+
+	s.pumpClientToTarget(sess, wsConn, remoteAddr)
+}
+
+// pumpTargetToClient reads target output for the life of the session,
+// buffering it for replay and forwarding it live to whichever client is
+// currently attached (there may be none, mid-reconnect).
+func (s *WSServer) pumpTargetToClient(sess *resumeSession) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := sess.target.Read(buf)
+		if n > 0 {
+			data := append([]byte(nil), buf[:n]...)
+
+			sess.mu.Lock()
+			sess.sendBuf = append(sess.sendBuf, data...)
+			if over := len(sess.sendBuf) - maxResumeSendBuffer; over > 0 {
+				sess.sendBuf = sess.sendBuf[over:]
+				sess.sendBufStart += int64(over)
+			}
+			conn := sess.clientConn
+			sess.mu.Unlock()
+
+			if conn != nil {
+				if werr := conn.WriteMessage(websocket.BinaryMessage, data); werr != nil {
+					s.logger.Debug("failed to forward target output to client, buffered for replay on resume", "error", werr, "session_id", sess.id)
+				}
+			}
+		}
+		if err != nil {
+			s.closeSession(sess, err)
+			return
+		}
+	}
+}
+
+// pumpClientToTarget reads from conn until it errors (client disconnect or
+// close), forwarding everything to sess.target and tracking how much of the
+// client's stream has been durably received. It blocks for the lifetime of
+// this particular client attachment, not the whole session.
+func (s *WSServer) pumpClientToTarget(sess *resumeSession, conn *websocket.Conn, remoteAddr string) {
+	defer func() {
+		s.conns.Delete(conn)
+		conn.Close()
+		s.detachClient(sess, conn)
+	}()
+
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				s.logger.Debug("SSH client connection lost, awaiting resume", "error", err, "session_id", sess.id, "remote", remoteAddr)
+			}
+			return
+		}
+		if messageType != websocket.BinaryMessage {
+			continue
+		}
+		if _, err := sess.target.Write(data); err != nil {
+			s.logger.Error("failed to write to SSH target, closing session", "error", err, "session_id", sess.id)
+			s.closeSession(sess, err)
+			return
+		}
+		sess.mu.Lock()
+		sess.recvOffset += int64(len(data))
+		sess.mu.Unlock()
+	}
+}
+
+// detachClient clears sess.clientConn if conn is still the attached one, and
+// schedules the session's teardown if no client resumes it within the
+// server's configured resume window.
+func (s *WSServer) detachClient(sess *resumeSession, conn *websocket.Conn) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if sess.clientConn != conn || sess.closed {
+		return
+	}
+	sess.clientConn = nil
+	sess.expireTimer = time.AfterFunc(s.sshResumeWindow, func() {
+		s.closeSession(sess, errors.New("resume window elapsed"))
+	})
+}
+
+// closeSession tears down the target connection and removes the session
+// from the registry. Safe to call more than once.
+func (s *WSServer) closeSession(sess *resumeSession, cause error) {
+	sess.mu.Lock()
+	if sess.closed {
+		sess.mu.Unlock()
+		return
+	}
+	sess.closed = true
+	if sess.expireTimer != nil {
+		sess.expireTimer.Stop()
+		sess.expireTimer = nil
+	}
+	conn := sess.clientConn
+	sess.clientConn = nil
+	sess.mu.Unlock()
+
+	s.logger.Info("SSH session closed", "session_id", sess.id, "cause", cause)
+	s.resumeSessions.Delete(sess.id)
+	sess.target.Close()
+	if conn != nil {
+		s.conns.Delete(conn)
+		conn.Close()
+	}
+}
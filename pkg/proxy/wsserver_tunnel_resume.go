@@ -0,0 +1,123 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/websocket"
+	bridgev1 "github.com/vercel-eddie/bridge/api/go/bridge/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// handleTunnel upgrades the connection, either attaching it to an existing
+// resumeSession named by the Sec-Bridge-Resume-Id request header (a
+// reconnecting client resuming a previously paired tunnel), or running the
+// normal dispatcher-pairing flow for a fresh registration. This is the same
+// resume handshake handleSSH uses, reused here rather than the in-band
+// control-frame scheme a from-scratch design might reach for, so clients
+// that can already resume /ssh gain /tunnel resume for free.
+func (s *WSServer) handleTunnel(w http.ResponseWriter, r *http.Request) {
+	if resumeID := r.Header.Get(headerResumeSessionID); resumeID != "" {
+		if sess, ok := s.resumeSessions.Load(resumeID); ok {
+			s.attachTunnelSession(w, r, sess)
+			return
+		}
+		s.logger.Warn("resume requested for unknown or expired tunnel session, registering fresh", "session_id", resumeID)
+	}
+	s.registerTunnel(w, r)
+}
+
+// attachTunnelSession re-attaches a reconnecting /tunnel client to sess,
+// replaying whatever server output it missed and resuming the pump of its
+// own writes to the paired server connection. Mirrors attachSSHSession, but
+// a tunnel session has no dialer to fall back to when sess is already
+// closed: the client has to register fresh instead, which needs a new
+// Registration message this handshake never reads, so that case is a
+// rejection rather than a retry.
+func (s *WSServer) attachTunnelSession(w http.ResponseWriter, r *http.Request, sess *resumeSession) {
+	remoteAddr := r.RemoteAddr
+
+	sess.mu.Lock()
+	if sess.closed {
+		sess.mu.Unlock()
+		s.logger.Warn("resume requested for already-closed tunnel session, rejecting", "session_id", sess.id, "remote", remoteAddr)
+		http.Error(w, "tunnel session no longer resumable, register fresh", http.StatusGone)
+		return
+	}
+	if sess.expireTimer != nil {
+		sess.expireTimer.Stop()
+		sess.expireTimer = nil
+	}
+	recvOffset := sess.recvOffset
+	sess.mu.Unlock()
+
+	wsConn, err := s.upgrader.Upgrade(w, r, http.Header{
+		"X-Bridge-Name":       []string{s.name},
+		headerResumeSessionID: []string{sess.id},
+		headerResumeOffset:    []string{strconv.FormatInt(recvOffset, 10)},
+	})
+	if err != nil {
+		s.logger.Error("failed to upgrade resumed tunnel websocket", "error", err, "remote", remoteAddr, "session_id", sess.id)
+		return
+	}
+	s.applyCompression(wsConn)
+
+	sess.mu.Lock()
+	if ackStr := r.Header.Get(headerResumeOffset); ackStr != "" {
+		if ack, err := strconv.ParseInt(ackStr, 10, 64); err == nil && ack > sess.sendBufStart {
+			drop := ack - sess.sendBufStart
+			if drop >= int64(len(sess.sendBuf)) {
+				sess.sendBuf = nil
+			} else {
+				sess.sendBuf = append([]byte(nil), sess.sendBuf[drop:]...)
+			}
+			sess.sendBufStart = ack
+		}
+	}
+	replay := append([]byte(nil), sess.sendBuf...)
+	sess.clientConn = wsConn
+	sess.mu.Unlock()
+
+	if len(replay) > 0 {
+		if err := wsConn.WriteMessage(websocket.BinaryMessage, replay); err != nil {
+			s.logger.Error("failed to replay buffered output to resumed tunnel client", "error", err, "session_id", sess.id)
+			wsConn.Close()
+			return
+		}
+	}
+
+	s.conns.Store(wsConn, struct{}{})
+	s.logger.Info("tunnel websocket resumed", "remote", remoteAddr, "session_id", sess.id, "replayed_bytes", len(replay))
+
+	s.pumpClientToTarget(sess, wsConn, remoteAddr)
+}
+
+// newTunnelResumeSession wraps a freshly paired server connection in a
+// resumeSession so a dropped client WebSocket can reattach instead of
+// forcing the whole dispatcher-pairing dance (and whatever re-auth it costs
+// upstream) to run again. The assigned session ID is sent to the client as
+// one control message ahead of the raw relayed bytes, the same "one framed
+// message, then raw relay" shape the client's own Registration message
+// already established for the other direction.
+func (s *WSServer) newTunnelResumeSession(wsConn, serverConn *websocket.Conn) (*resumeSession, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, fmt.Errorf("generate session id: %w", err)
+	}
+
+	sess := &resumeSession{id: id, target: &wsConnAdapter{conn: serverConn}, clientConn: wsConn}
+
+	ack := &bridgev1.Message{ResumeSessionId: id}
+	data, err := proto.Marshal(ack)
+	if err != nil {
+		return nil, fmt.Errorf("marshal resume session id: %w", err)
+	}
+	if err := wsConn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+		return nil, fmt.Errorf("send resume session id: %w", err)
+	}
+
+	s.resumeSessions.Store(id, sess)
+	go s.pumpTargetToClient(sess)
+	return sess, nil
+}
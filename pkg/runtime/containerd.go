@@ -0,0 +1,94 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	criapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// containerdRuntime talks to containerd's CRI plugin over its gRPC socket,
+// the same interface kubelet uses, rather than shelling out to `ctr` or
+// `crictl`.
+type containerdRuntime struct {
+	socketPath string
+	conn       *grpc.ClientConn
+	runtime    criapi.RuntimeServiceClient
+}
+
+func newContainerdRuntime(ctx context.Context, socketPath string) (*containerdRuntime, error) {
+	conn, err := grpc.NewClient("unix://"+socketPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("containerd: dial %s: %w", socketPath, err)
+	}
+	return &containerdRuntime{
+		socketPath: socketPath,
+		conn:       conn,
+		runtime:    criapi.NewRuntimeServiceClient(conn),
+	}, nil
+}
+
+func (r *containerdRuntime) Name() string { return "containerd" }
+
+func (r *containerdRuntime) List(ctx context.Context, labelSelector map[string]string) ([]Container, error) {
+	resp, err := r.runtime.ListContainers(ctx, &criapi.ListContainersRequest{
+		Filter: &criapi.ContainerFilter{
+			LabelSelector: labelSelector,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("containerd: list containers: %w", err)
+	}
+
+	containers := make([]Container, 0, len(resp.Containers))
+	for _, c := range resp.Containers {
+		name := c.Id
+		if c.Metadata != nil && c.Metadata.Name != "" {
+			name = c.Metadata.Name
+		}
+		containers = append(containers, Container{
+			ID:     c.Id,
+			Name:   name,
+			Status: c.State.String(),
+			Labels: c.Labels,
+		})
+	}
+	return containers, nil
+}
+
+func (r *containerdRuntime) Exec(ctx context.Context, id string, argv ...string) ([]byte, error) {
+	resp, err := r.runtime.ExecSync(ctx, &criapi.ExecSyncRequest{
+		ContainerId: id,
+		Cmd:         argv,
+		Timeout:     10,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("containerd: exec: %w", err)
+	}
+	if resp.ExitCode != 0 {
+		return append(resp.Stdout, resp.Stderr...), fmt.Errorf("containerd: exec: exit code %d: %s", resp.ExitCode, resp.Stderr)
+	}
+	return append(resp.Stdout, resp.Stderr...), nil
+}
+
+func (r *containerdRuntime) Logs(ctx context.Context, id string) ([]byte, error) {
+	status, err := r.runtime.ContainerStatus(ctx, &criapi.ContainerStatusRequest{ContainerId: id})
+	if err != nil {
+		return nil, fmt.Errorf("containerd: container status: %w", err)
+	}
+	return r.Exec(ctx, id, "tail", "-n", "200", status.Status.LogPath)
+}
+
+func (r *containerdRuntime) Inspect(ctx context.Context, id string) (Info, error) {
+	resp, err := r.runtime.ContainerStatus(ctx, &criapi.ContainerStatusRequest{ContainerId: id, Verbose: true})
+	if err != nil {
+		return Info{}, fmt.Errorf("containerd: inspect: %w", err)
+	}
+	return Info{Labels: resp.Status.Labels, Raw: []byte(resp.Info["info"])}, nil
+}
+
+func (r *containerdRuntime) Close() error {
+	return r.conn.Close()
+}
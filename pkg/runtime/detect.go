@@ -0,0 +1,61 @@
+package runtime
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// candidateSockets lists the well-known socket paths for each engine, in
+// the order Detect probes them. $XDG_RUNTIME_DIR is resolved at call time
+// since it's only set for the invoking user's session.
+func candidateSockets() []struct {
+	name string
+	path string
+} {
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = filepath.Join("/run/user", os.Getenv("UID"))
+	}
+
+	return []struct {
+		name string
+		path string
+	}{
+		{"docker", "/var/run/docker.sock"},
+		{"podman", filepath.Join(runtimeDir, "podman", "podman.sock")},
+		{"podman", "/run/podman/podman.sock"},
+	}
+}
+
+const containerdSocket = "/run/containerd/containerd.sock"
+
+// Detect probes the well-known engine sockets on this host and returns a
+// Runtime for each one that's actually present, so a machine running both
+// Docker and Podman (or containerd alongside either) gets one Runtime per
+// engine instead of only the first found. containerd is probed only at its
+// rootful socket path (containerdSocket); a rootless containerd socket is
+// not detected.
+func Detect(ctx context.Context) []Runtime {
+	var runtimes []Runtime
+	seen := map[string]bool{}
+
+	for _, c := range candidateSockets() {
+		if seen[c.path] {
+			continue
+		}
+		if _, err := os.Stat(c.path); err != nil {
+			continue
+		}
+		seen[c.path] = true
+		runtimes = append(runtimes, newDockerRuntime(c.name, c.path))
+	}
+
+	if _, err := os.Stat(containerdSocket); err == nil {
+		if rt, err := newContainerdRuntime(ctx, containerdSocket); err == nil {
+			runtimes = append(runtimes, rt)
+		}
+	}
+
+	return runtimes
+}
@@ -0,0 +1,217 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// dockerRuntime talks to the Docker Engine API over a unix socket. Podman
+// exposes the same API on its own socket, so this implementation is reused
+// for both engines with only the name and socket path differing.
+type dockerRuntime struct {
+	name       string
+	socketPath string
+	client     *http.Client
+}
+
+// newDockerRuntime returns a Runtime backed by the Docker-compatible Engine
+// API served on socketPath, labeled as name in diagnostic output.
+func newDockerRuntime(name, socketPath string) *dockerRuntime {
+	return &dockerRuntime{
+		name:       name,
+		socketPath: socketPath,
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+}
+
+func (r *dockerRuntime) Name() string { return r.name }
+
+func (r *dockerRuntime) get(ctx context.Context, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://unix"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return r.client.Do(req)
+}
+
+type dockerContainerSummary struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Status string            `json:"Status"`
+	Labels map[string]string `json:"Labels"`
+}
+
+func (r *dockerRuntime) List(ctx context.Context, labelSelector map[string]string) ([]Container, error) {
+	filters := map[string][]string{}
+	for k, v := range labelSelector {
+		if v == "" {
+			filters["label"] = append(filters["label"], k)
+			continue
+		}
+		filters["label"] = append(filters["label"], fmt.Sprintf("%s=%s", k, v))
+	}
+	filterJSON, err := json.Marshal(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{"all": {"true"}, "filters": {string(filterJSON)}}
+	resp, err := r.get(ctx, "/containers/json?"+q.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("%s: list containers: %w", r.name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s: list containers: %s: %s", r.name, resp.Status, body)
+	}
+
+	var summaries []dockerContainerSummary
+	if err := json.NewDecoder(resp.Body).Decode(&summaries); err != nil {
+		return nil, fmt.Errorf("%s: decode container list: %w", r.name, err)
+	}
+
+	containers := make([]Container, 0, len(summaries))
+	for _, s := range summaries {
+		name := s.ID
+		if len(s.Names) > 0 {
+			name = strings.TrimPrefix(s.Names[0], "/")
+		}
+		containers = append(containers, Container{
+			ID:     s.ID,
+			Name:   name,
+			Status: s.Status,
+			Labels: s.Labels,
+		})
+	}
+	return containers, nil
+}
+
+func (r *dockerRuntime) Exec(ctx context.Context, id string, argv ...string) ([]byte, error) {
+	createBody, err := json.Marshal(map[string]any{
+		"Cmd":          argv,
+		"AttachStdout": true,
+		"AttachStderr": true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://unix/containers/"+id+"/exec", bytes.NewReader(createBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: create exec: %w", r.name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s: create exec: %s: %s", r.name, resp.Status, body)
+	}
+
+	var created struct {
+		ID string `json:"Id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, fmt.Errorf("%s: decode exec id: %w", r.name, err)
+	}
+
+	startReq, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://unix/exec/"+created.ID+"/start",
+		strings.NewReader(`{"Detach":false,"Tty":false}`))
+	if err != nil {
+		return nil, err
+	}
+	startReq.Header.Set("Content-Type", "application/json")
+	startResp, err := r.client.Do(startReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s: start exec: %w", r.name, err)
+	}
+	defer startResp.Body.Close()
+
+	out, err := io.ReadAll(startResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: read exec output: %w", r.name, err)
+	}
+	return demuxDockerStream(out), nil
+}
+
+func (r *dockerRuntime) Logs(ctx context.Context, id string) ([]byte, error) {
+	resp, err := r.get(ctx, "/containers/"+id+"/logs?stdout=true&stderr=true&tail=200")
+	if err != nil {
+		return nil, fmt.Errorf("%s: logs: %w", r.name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s: logs: %s: %s", r.name, resp.Status, body)
+	}
+
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return demuxDockerStream(out), nil
+}
+
+func (r *dockerRuntime) Inspect(ctx context.Context, id string) (Info, error) {
+	resp, err := r.get(ctx, "/containers/"+id+"/json")
+	if err != nil {
+		return Info{}, fmt.Errorf("%s: inspect: %w", r.name, err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Info{}, fmt.Errorf("%s: read inspect: %w", r.name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Info{}, fmt.Errorf("%s: inspect: %s: %s", r.name, resp.Status, raw)
+	}
+
+	var parsed struct {
+		Config struct {
+			Labels map[string]string `json:"Labels"`
+		} `json:"Config"`
+	}
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return Info{}, fmt.Errorf("%s: decode inspect: %w", r.name, err)
+	}
+	return Info{Labels: parsed.Config.Labels, Raw: raw}, nil
+}
+
+// demuxDockerStream strips the 8-byte stream-multiplexing header Docker
+// prepends to each frame when the exec/logs endpoint is attached without a
+// TTY, concatenating stdout and stderr in order.
+func demuxDockerStream(raw []byte) []byte {
+	var out bytes.Buffer
+	for len(raw) >= 8 {
+		size := int(raw[4])<<24 | int(raw[5])<<16 | int(raw[6])<<8 | int(raw[7])
+		raw = raw[8:]
+		if size > len(raw) {
+			size = len(raw)
+		}
+		out.Write(raw[:size])
+		raw = raw[size:]
+	}
+	if out.Len() == 0 && len(raw) > 0 {
+		// Not a multiplexed stream (e.g. TTY-attached); return as-is.
+		return raw
+	}
+	return out.Bytes()
+}
@@ -0,0 +1,46 @@
+// Package runtime abstracts over the container engines a developer might
+// have installed (Docker, Podman, containerd) so callers like
+// commands.Debug don't need to know which one is actually running on the
+// host.
+package runtime
+
+import "context"
+
+// Container is the minimal shape callers need out of a running container,
+// normalized across engines.
+type Container struct {
+	ID     string
+	Name   string
+	Status string
+	Labels map[string]string
+}
+
+// Info is engine-specific inspect output, kept as a label map so callers
+// that only need a handful of fields don't force every Runtime
+// implementation to normalize the entire inspect schema.
+type Info struct {
+	Labels map[string]string
+	Raw    []byte
+}
+
+// Runtime talks to one container engine over its native API rather than
+// shelling out to a CLI, so it works the same whether that engine is
+// rootful, rootless, or missing its CLI entirely.
+type Runtime interface {
+	// Name identifies the engine for diagnostic output, e.g. "docker",
+	// "podman", "containerd".
+	Name() string
+
+	// List returns containers whose labels match every key/value pair in
+	// labelSelector.
+	List(ctx context.Context, labelSelector map[string]string) ([]Container, error)
+
+	// Exec runs argv inside the container and returns its combined output.
+	Exec(ctx context.Context, id string, argv ...string) ([]byte, error)
+
+	// Logs returns the container's recent log output.
+	Logs(ctx context.Context, id string) ([]byte, error)
+
+	// Inspect returns engine-specific metadata about the container.
+	Inspect(ctx context.Context, id string) (Info, error)
+}
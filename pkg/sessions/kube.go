@@ -0,0 +1,129 @@
+package sessions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/vercel/bridge/pkg/k8s/meta"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// sessionConfigMapPrefix namespaces the ConfigMaps a kubeStore creates from
+// any other ConfigMaps in the namespace.
+const sessionConfigMapPrefix = "bridge-session-"
+
+// KubeStoreConfig configures a Kubernetes-backed Store.
+type KubeStoreConfig struct {
+	// Namespace is where session ConfigMaps are read and written. Defaults to "bridge".
+	Namespace string
+	// DeviceID is recorded on every session ConfigMap this store creates, via
+	// meta.LabelDeviceID, for operators filtering sessions by device.
+	DeviceID string
+}
+
+var _ Store = (*kubeStore)(nil)
+
+// kubeStore is a Store backed by Kubernetes ConfigMaps, so the administrator
+// can track sessions when running in-cluster across multiple replicas with
+// no shared filesystem.
+type kubeStore struct {
+	client    kubernetes.Interface
+	namespace string
+	deviceID  string
+}
+
+// NewKubeStore creates a Store that persists each session as a ConfigMap in
+// cfg.Namespace, labeled with meta.LabelManagedBy and meta.LabelDeviceID.
+func NewKubeStore(client kubernetes.Interface, cfg KubeStoreConfig) Store {
+	if cfg.Namespace == "" {
+		cfg.Namespace = "bridge"
+	}
+	return &kubeStore{
+		client:    client,
+		namespace: cfg.Namespace,
+		deviceID:  cfg.DeviceID,
+	}
+}
+
+func sessionConfigMapName(name string) string {
+	return sessionConfigMapPrefix + name
+}
+
+func (k *kubeStore) Add(name string, session Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      sessionConfigMapName(name),
+			Namespace: k.namespace,
+			Labels: map[string]string{
+				meta.LabelManagedBy: meta.ManagedByAdministrator,
+				meta.LabelDeviceID:  k.deviceID,
+			},
+		},
+		Data: map[string]string{"session": string(data)},
+	}
+
+	_, err = k.client.CoreV1().ConfigMaps(k.namespace).Create(context.Background(), cm, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("session %q already exists", name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create session configmap: %w", err)
+	}
+	return nil
+}
+
+func (k *kubeStore) Get(name string) (Session, bool) {
+	cm, err := k.client.CoreV1().ConfigMaps(k.namespace).Get(context.Background(), sessionConfigMapName(name), metav1.GetOptions{})
+	if err != nil {
+		return Session{}, false
+	}
+
+	var session Session
+	if err := json.Unmarshal([]byte(cm.Data["session"]), &session); err != nil {
+		return Session{}, false
+	}
+	return session, true
+}
+
+func (k *kubeStore) Remove(name string) error {
+	err := k.client.CoreV1().ConfigMaps(k.namespace).Delete(context.Background(), sessionConfigMapName(name), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete session configmap: %w", err)
+	}
+	return nil
+}
+
+func (k *kubeStore) Exists(name string) bool {
+	_, ok := k.Get(name)
+	return ok
+}
+
+func (k *kubeStore) List() []string {
+	list, err := k.client.CoreV1().ConfigMaps(k.namespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: meta.LabelManagedBy + "=" + meta.ManagedByAdministrator,
+	})
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(list.Items))
+	for _, cm := range list.Items {
+		names = append(names, strings.TrimPrefix(cm.Name, sessionConfigMapPrefix))
+	}
+	return names
+}
+
+func (k *kubeStore) GenerateName() string {
+	return generateName(k.Exists)
+}
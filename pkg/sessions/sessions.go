@@ -14,14 +14,35 @@ type Session struct {
 	URL string `json:"url"`
 }
 
-// Store manages local session storage.
-type Store struct {
+// Store persists sandbox sessions by name. NewFileStore backs it with
+// ~/.bridge/sessions.json for local CLI use; NewKubeStore backs it with
+// ConfigMaps for the administrator running in-cluster, where there's no
+// shared filesystem across replicas.
+type Store interface {
+	// Add adds a session with the given name. Returns an error if the name already exists.
+	Add(name string, session Session) error
+	// Get retrieves a session by name.
+	Get(name string) (Session, bool)
+	// Remove removes a session by name.
+	Remove(name string) error
+	// Exists checks if a session with the given name exists.
+	Exists(name string) bool
+	// List returns all session names.
+	List() []string
+	// GenerateName generates a unique RFC 1123 compliant name.
+	GenerateName() string
+}
+
+var _ Store = (*fileStore)(nil)
+
+// fileStore is a Store backed by a JSON file on the local filesystem.
+type fileStore struct {
 	path     string
 	sessions map[string]Session
 }
 
-// NewStore creates a new session store.
-func NewStore() (*Store, error) {
+// NewFileStore creates a Store backed by ~/.bridge/sessions.json.
+func NewFileStore() (Store, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get home directory: %w", err)
@@ -33,7 +54,7 @@ func NewStore() (*Store, error) {
 	}
 
 	path := filepath.Join(dir, "sessions.json")
-	s := &Store{
+	s := &fileStore{
 		path:     path,
 		sessions: make(map[string]Session),
 	}
@@ -51,7 +72,7 @@ func NewStore() (*Store, error) {
 	return s, nil
 }
 
-func (s *Store) load() error {
+func (s *fileStore) load() error {
 	data, err := os.ReadFile(s.path)
 	if err != nil {
 		return err
@@ -60,7 +81,7 @@ func (s *Store) load() error {
 	return json.Unmarshal(data, &s.sessions)
 }
 
-func (s *Store) save() error {
+func (s *fileStore) save() error {
 	data, err := json.MarshalIndent(s.sessions, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal sessions: %w", err)
@@ -69,8 +90,7 @@ func (s *Store) save() error {
 	return os.WriteFile(s.path, data, 0644)
 }
 
-// Add adds a session with the given name. Returns an error if the name already exists.
-func (s *Store) Add(name string, session Session) error {
+func (s *fileStore) Add(name string, session Session) error {
 	if _, exists := s.sessions[name]; exists {
 		return fmt.Errorf("session %q already exists", name)
 	}
@@ -79,26 +99,22 @@ func (s *Store) Add(name string, session Session) error {
 	return s.save()
 }
 
-// Get retrieves a session by name.
-func (s *Store) Get(name string) (Session, bool) {
+func (s *fileStore) Get(name string) (Session, bool) {
 	session, ok := s.sessions[name]
 	return session, ok
 }
 
-// Remove removes a session by name.
-func (s *Store) Remove(name string) error {
+func (s *fileStore) Remove(name string) error {
 	delete(s.sessions, name)
 	return s.save()
 }
 
-// Exists checks if a session with the given name exists.
-func (s *Store) Exists(name string) bool {
+func (s *fileStore) Exists(name string) bool {
 	_, ok := s.sessions[name]
 	return ok
 }
 
-// List returns all session names.
-func (s *Store) List() []string {
+func (s *fileStore) List() []string {
 	names := make([]string, 0, len(s.sessions))
 	for name := range s.sessions {
 		names = append(names, name)
@@ -106,6 +122,10 @@ func (s *Store) List() []string {
 	return names
 }
 
+func (s *fileStore) GenerateName() string {
+	return generateName(s.Exists)
+}
+
 // rfc1123Regex validates RFC 1123 compliant names.
 // Must be lowercase alphanumeric, may contain hyphens, must start and end with alphanumeric.
 var rfc1123Regex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
@@ -136,15 +156,18 @@ var nouns = []string{
 	"hare", "crow", "dove", "frog", "moth", "newt", "wren", "swan",
 }
 
-// GenerateName generates a unique RFC 1123 compliant name.
-func (s *Store) GenerateName() string {
+// generateName picks a random RFC 1123 compliant name, retrying until exists
+// reports it's free. Shared by every Store implementation so collision
+// checks always go through that store's own notion of "exists" (e.g. a live
+// ConfigMap lookup for kubeStore, rather than an in-memory map).
+func generateName(exists func(string) bool) string {
 	for {
 		adj := adjectives[randomInt(len(adjectives))]
 		noun := nouns[randomInt(len(nouns))]
 		suffix := randomSuffix()
 		name := fmt.Sprintf("%s-%s-%s", adj, noun, suffix)
 
-		if !s.Exists(name) {
+		if !exists(name) {
 			return name
 		}
 	}
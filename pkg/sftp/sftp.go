@@ -0,0 +1,212 @@
+// Package sftp implements the sandbox's SFTP subsystem, backed by
+// github.com/pkg/sftp's RequestServer. mutagen drives its sync
+// reconciliation almost entirely through repeated Setstat calls (mode,
+// ownership, mtime/atime), so getting those semantics right here is what
+// makes sync converge instead of thrashing.
+package sftp
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/pkg/sftp"
+)
+
+// Subsystem returns the ssh.SubsystemHandler to register for "sftp" via
+// wish.WithSubsystem.
+func Subsystem(logger *slog.Logger) ssh.SubsystemHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return func(s ssh.Session) {
+		h := &handler{logger: logger, uid: os.Getuid(), deferredModes: map[string]os.FileMode{}}
+
+		server := sftp.NewRequestServer(s, sftp.Handlers{
+			FileGet:  h,
+			FilePut:  h,
+			FileCmd:  h,
+			FileList: h,
+		})
+		defer server.Close()
+
+		if err := server.Serve(); err != nil && err != io.EOF {
+			logger.Error("sftp session ended with error", "error", err, "user", s.User())
+		}
+	}
+}
+
+// handler implements sftp.FileReader, FileWriter, FileCmder and FileLister
+// directly against the local filesystem - the sandbox's view of whatever
+// tree mutagen is syncing into it.
+type handler struct {
+	logger *slog.Logger
+	uid    int
+
+	mu sync.Mutex
+	// deferredModes holds a mode a Setstat asked for before the file it
+	// names existed yet. Filewrite's create (os.O_TRUNC|os.O_CREATE) always
+	// resets a new file to its default mode, so a Setstat that races ahead
+	// of the upload that creates the file would otherwise be silently lost.
+	// The mode is applied instead once that upload's Close happens.
+	deferredModes map[string]os.FileMode
+}
+
+// Fileread implements sftp.FileReader.
+func (h *handler) Fileread(r *sftp.Request) (io.ReaderAt, error) {
+	return os.Open(r.Filepath)
+}
+
+// Filewrite implements sftp.FileWriter.
+func (h *handler) Filewrite(r *sftp.Request) (io.WriterAt, error) {
+	f, err := os.OpenFile(r.Filepath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &deferredModeFile{File: f, handler: h, path: r.Filepath}, nil
+}
+
+// deferredModeFile applies any mode Setstat deferred for path once the
+// upload that created it finishes, rather than at create time.
+type deferredModeFile struct {
+	*os.File
+	handler *handler
+	path    string
+}
+
+func (f *deferredModeFile) Close() error {
+	err := f.File.Close()
+	f.handler.applyDeferredMode(f.path)
+	return err
+}
+
+func (h *handler) applyDeferredMode(path string) {
+	h.mu.Lock()
+	mode, ok := h.deferredModes[path]
+	if ok {
+		delete(h.deferredModes, path)
+	}
+	h.mu.Unlock()
+
+	if ok {
+		if err := os.Chmod(path, mode); err != nil {
+			h.logger.Error("failed to apply deferred mode after upload", "path", path, "mode", mode, "error", err)
+		}
+	}
+}
+
+// Filecmd implements sftp.FileCmder.
+func (h *handler) Filecmd(r *sftp.Request) error {
+	switch r.Method {
+	case "Setstat":
+		return h.setstat(r)
+	case "Rename":
+		return os.Rename(r.Filepath, r.Target)
+	case "Mkdir":
+		return os.MkdirAll(r.Filepath, 0755)
+	case "Remove", "Rmdir":
+		return os.Remove(r.Filepath)
+	case "Symlink":
+		return os.Symlink(r.Target, r.Filepath)
+	default:
+		return fmt.Errorf("sftp: unsupported command %q", r.Method)
+	}
+}
+
+// setstat applies whichever of mode, ownership and times the client's
+// SSH_FXP_SETSTAT actually set, coalescing no-op calls so mutagen's
+// stat-driven reconciliation doesn't generate a chmod/chown storm on every
+// pass over an already-converged tree.
+func (h *handler) setstat(r *sftp.Request) error {
+	attrs := r.Attributes()
+	flags := r.AttrFlags()
+
+	var firstErr error
+	setErr := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if flags.Permissions {
+		mode := os.FileMode(attrs.Mode) & os.ModePerm
+		switch info, err := os.Lstat(r.Filepath); {
+		case err == nil && info.Mode()&os.ModePerm == mode:
+			// Already at the requested mode - mutagen resends this on
+			// every reconcile even when nothing changed.
+		case os.IsNotExist(err):
+			h.mu.Lock()
+			h.deferredModes[r.Filepath] = mode
+			h.mu.Unlock()
+		default:
+			setErr(os.Chmod(r.Filepath, mode))
+		}
+	}
+
+	if flags.UidGid {
+		uid, gid := int(attrs.UID), int(attrs.GID)
+		if uid == h.uid {
+			// mutagen re-chowns to the running user on every reconcile;
+			// skip it instead of requiring root just to no-op.
+		} else {
+			setErr(os.Chown(r.Filepath, uid, gid))
+		}
+	}
+
+	if flags.Acmodtime {
+		atime := time.Unix(int64(attrs.Atime), 0)
+		mtime := time.Unix(int64(attrs.Mtime), 0)
+		setErr(os.Chtimes(r.Filepath, atime, mtime))
+	}
+
+	return firstErr
+}
+
+// Filelist implements sftp.FileLister.
+func (h *handler) Filelist(r *sftp.Request) (sftp.ListerAt, error) {
+	switch r.Method {
+	case "List":
+		entries, err := os.ReadDir(r.Filepath)
+		if err != nil {
+			return nil, err
+		}
+		infos := make([]os.FileInfo, 0, len(entries))
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			infos = append(infos, info)
+		}
+		return listerAt(infos), nil
+	case "Stat", "Readlink":
+		info, err := os.Lstat(r.Filepath)
+		if err != nil {
+			return nil, err
+		}
+		return listerAt([]os.FileInfo{info}), nil
+	default:
+		return nil, fmt.Errorf("sftp: unsupported list method %q", r.Method)
+	}
+}
+
+// listerAt implements sftp.ListerAt over an already-fetched slice of
+// os.FileInfo, the pattern the pkg/sftp RequestServer docs recommend for
+// FileLister implementations.
+type listerAt []os.FileInfo
+
+func (l listerAt) ListAt(dst []os.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+	n := copy(dst, l[offset:])
+	if n < len(dst) {
+		return n, io.EOF
+	}
+	return n, nil
+}
@@ -0,0 +1,165 @@
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// defaultRotateBytes is LocalSink's default RotateBytes: rotate to a fresh
+// .cast file once the current one passes 100 MiB.
+const defaultRotateBytes = 100 * 1024 * 1024
+
+// LocalSink writes each session's recording to its own asciinema v2 .cast
+// file under Dir, with a .meta.json sidecar and rotation to a fresh file
+// once RotateBytes is exceeded.
+type LocalSink struct {
+	Dir         string
+	RotateBytes int64
+}
+
+var _ SessionSink = (*LocalSink)(nil)
+
+// NewLocalSink creates a LocalSink rooted at dir, creating it if needed. An
+// empty dir defaults to ~/.bridge/sessions; a zero rotateBytes defaults to
+// 100 MiB.
+func NewLocalSink(dir string, rotateBytes int64) (*LocalSink, error) {
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("resolve default recording directory: %w", err)
+		}
+		dir = filepath.Join(home, ".bridge", "sessions")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create recording directory %s: %w", dir, err)
+	}
+	if rotateBytes == 0 {
+		rotateBytes = defaultRotateBytes
+	}
+
+	return &LocalSink{Dir: dir, RotateBytes: rotateBytes}, nil
+}
+
+// Open implements SessionSink.
+func (s *LocalSink) Open(meta Meta) (RecordingWriter, error) {
+	rec := &localRecording{sink: s, meta: meta}
+	if err := rec.openPart(); err != nil {
+		return nil, err
+	}
+	if err := rec.writeMetaFile(); err != nil {
+		rec.file.Close()
+		return nil, err
+	}
+	return rec, nil
+}
+
+// localRecording is the RecordingWriter behind LocalSink.Open: an
+// append-only .cast file that rotates to a new part (and rewrites the
+// .meta.json sidecar to list it) once RotateBytes is exceeded.
+type localRecording struct {
+	sink *LocalSink
+	meta Meta
+
+	mu      sync.Mutex
+	file    *os.File
+	part    int
+	written int64
+	parts   []string
+}
+
+func (r *localRecording) partPath(part int) string {
+	name := r.meta.SessionID + ".cast"
+	if part > 1 {
+		name = fmt.Sprintf("%s.%d.cast", r.meta.SessionID, part)
+	}
+	return filepath.Join(r.sink.Dir, name)
+}
+
+func (r *localRecording) metaPath() string {
+	return filepath.Join(r.sink.Dir, r.meta.SessionID+".meta.json")
+}
+
+// openPart creates the next .cast file (the first, or the next rotation)
+// and writes its asciinema v2 header line.
+func (r *localRecording) openPart() error {
+	r.part++
+	path := r.partPath(r.part)
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("open cast file %s: %w", path, err)
+	}
+
+	line, err := json.Marshal(castHeader{
+		Version:    2,
+		Width:      r.meta.Width,
+		Height:     r.meta.Height,
+		Timestamp:  r.meta.Timestamp,
+		Env:        r.meta.Env,
+		User:       r.meta.User,
+		RemoteAddr: r.meta.RemoteAddr,
+		RawCommand: r.meta.RawCommand,
+		SessionID:  r.meta.SessionID,
+	})
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("marshal cast header: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write cast header: %w", err)
+	}
+
+	r.file = f
+	r.written = 0
+	r.parts = append(r.parts, filepath.Base(path))
+	return nil
+}
+
+// Write implements RecordingWriter.
+func (r *localRecording) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sink.RotateBytes > 0 && r.written > 0 && r.written+int64(len(p)) > r.sink.RotateBytes {
+		if err := r.file.Close(); err != nil {
+			return 0, fmt.Errorf("close cast file before rotation: %w", err)
+		}
+		if err := r.openPart(); err != nil {
+			return 0, err
+		}
+		if err := r.writeMetaFile(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.written += int64(n)
+	return n, err
+}
+
+func (r *localRecording) writeMetaFile() error {
+	data, err := json.MarshalIndent(sidecarMeta{Meta: r.meta, Parts: r.parts}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal recording metadata: %w", err)
+	}
+	if err := os.WriteFile(r.metaPath(), data, 0600); err != nil {
+		return fmt.Errorf("write recording metadata: %w", err)
+	}
+	return nil
+}
+
+// Close implements RecordingWriter.
+func (r *localRecording) Close() error {
+	r.mu.Lock()
+	err := r.file.Close()
+	r.mu.Unlock()
+
+	if werr := r.writeMetaFile(); werr != nil && err == nil {
+		err = werr
+	}
+	return err
+}
@@ -0,0 +1,291 @@
+// Package recorder captures SSH sessions as asciinema v2 recordings. It
+// wires in as a wish.Middleware (sshserver.Config.Recorder), teeing every
+// session's PTY I/O to a pluggable SessionSink without ShellHandler itself
+// needing to know recording is happening.
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/segmentio/ksuid"
+)
+
+// Meta is everything about a session known before its first recorded byte:
+// the asciinema v2 header fields plus the bridge-specific ones the request
+// asked for (user, remote_addr, raw_command, session_id).
+type Meta struct {
+	SessionID  string
+	User       string
+	RemoteAddr string
+	RawCommand string
+	Width      int
+	Height     int
+	Timestamp  int64
+	Env        map[string]string
+}
+
+// castHeader is Meta marshaled as the first line of an asciinema v2 .cast
+// file: the spec's own fields plus bridge's additions alongside them.
+type castHeader struct {
+	Version    int               `json:"version"`
+	Width      int               `json:"width"`
+	Height     int               `json:"height"`
+	Timestamp  int64             `json:"timestamp"`
+	Env        map[string]string `json:"env,omitempty"`
+	User       string            `json:"user"`
+	RemoteAddr string            `json:"remote_addr"`
+	RawCommand string            `json:"raw_command"`
+	SessionID  string            `json:"session_id"`
+}
+
+// sidecarMeta is the .meta.json written alongside a recording, for tooling
+// that wants the session's metadata without parsing the (possibly large)
+// .cast file's first line.
+type sidecarMeta struct {
+	Meta
+	Parts []string `json:"parts,omitempty"`
+}
+
+// RecordingWriter receives one session's asciinema event lines as they're
+// produced and finalizes the recording (closing files, completing an
+// in-progress upload, ...) when the session ends.
+type RecordingWriter interface {
+	Write(p []byte) (int, error)
+	Close() error
+}
+
+// SessionSink persists recordings. Open is called once per session; the
+// returned RecordingWriter gets every event line for that session's
+// lifetime. NewLocalSink and NewS3SinkFromEnv are the built-in
+// implementations; callers can bring their own.
+type SessionSink interface {
+	Open(meta Meta) (RecordingWriter, error)
+}
+
+// Config configures a Recorder.
+type Config struct {
+	// Sink persists each session's recording. Defaults to a LocalSink
+	// rooted at ~/.bridge/sessions.
+	Sink SessionSink
+
+	// CaptureInput also records client keystrokes as "i" events. Off by
+	// default: most consumers only want to see what the shell printed, and
+	// input capture makes it easier to accidentally record pasted secrets.
+	CaptureInput bool
+
+	// Logger carries fixed contextual attributes onto every log line this
+	// package emits. Defaults to slog.Default() when nil.
+	Logger *slog.Logger
+}
+
+// Recorder builds the wish.Middleware that tees SSH sessions to a
+// SessionSink.
+type Recorder struct {
+	sink         SessionSink
+	captureInput bool
+	logger       *slog.Logger
+}
+
+// New creates a Recorder from cfg.
+func New(cfg Config) (*Recorder, error) {
+	sink := cfg.Sink
+	if sink == nil {
+		local, err := NewLocalSink("", 0)
+		if err != nil {
+			return nil, fmt.Errorf("create default recording sink: %w", err)
+		}
+		sink = local
+	}
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Recorder{
+		sink:         sink,
+		captureInput: cfg.CaptureInput,
+		logger:       logger,
+	}, nil
+}
+
+// Middleware returns the wish.Middleware that starts a recording for every
+// session it wraps and tees that session's I/O to it for the session's
+// lifetime.
+func (r *Recorder) Middleware() wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			rec, err := r.start(s)
+			if err != nil {
+				r.logger.Error("failed to start session recording", "error", err, "user", s.User())
+				next(s)
+				return
+			}
+			defer func() {
+				if err := rec.w.Close(); err != nil {
+					r.logger.Error("failed to finalize session recording", "error", err, "session_id", rec.meta.SessionID)
+				}
+			}()
+			next(rec)
+		}
+	}
+}
+
+// start opens a recording for s and returns the wrapped session ShellHandler
+// (or whatever SessionHandler/Middleware is next in the chain) should use in
+// its place.
+func (r *Recorder) start(s ssh.Session) (*recordingSession, error) {
+	id := strings.ToLower(ksuid.New().String())
+
+	width, height := 80, 24
+	var pty ssh.Pty
+	var winCh <-chan ssh.Window
+	var isPty bool
+	if p, ch, ok := s.Pty(); ok {
+		pty, winCh, isPty = p, ch, true
+		width, height = pty.Window.Width, pty.Window.Height
+	}
+
+	meta := Meta{
+		SessionID:  id,
+		User:       s.User(),
+		RemoteAddr: s.RemoteAddr().String(),
+		RawCommand: s.RawCommand(),
+		Width:      width,
+		Height:     height,
+		Timestamp:  time.Now().Unix(),
+		Env:        sessionEnv(s),
+	}
+
+	w, err := r.sink.Open(meta)
+	if err != nil {
+		return nil, fmt.Errorf("open recording for session %s: %w", id, err)
+	}
+
+	rec := &recordingSession{
+		Session:      s,
+		w:            w,
+		meta:         meta,
+		start:        time.Now(),
+		captureInput: r.captureInput,
+	}
+	if isPty {
+		rec.isPty = true
+		rec.pty = pty
+		rec.resizeCh = rec.teeResize(winCh)
+	}
+
+	r.logger.Info("session recording started", "session_id", id, "user", meta.User, "remote_addr", meta.RemoteAddr)
+	return rec, nil
+}
+
+// sessionEnv turns s.Environ()'s "KEY=VALUE" strings into a map for the cast
+// header's env field.
+func sessionEnv(s ssh.Session) map[string]string {
+	environ := s.Environ()
+	if len(environ) == 0 {
+		return nil
+	}
+	env := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		env[k] = v
+	}
+	return env
+}
+
+// recordingSession wraps an ssh.Session, teeing its Read/Write (the same
+// bytes ShellHandler copies to/from the session's PTY) into an asciinema v2
+// event stream, and its window-resize channel into "resize" markers.
+type recordingSession struct {
+	ssh.Session
+
+	w            RecordingWriter
+	meta         Meta
+	start        time.Time
+	captureInput bool
+
+	isPty    bool
+	pty      ssh.Pty
+	resizeCh <-chan ssh.Window
+
+	mu sync.Mutex
+}
+
+func (s *recordingSession) Read(p []byte) (int, error) {
+	n, err := s.Session.Read(p)
+	if n > 0 && s.captureInput {
+		s.writeEvent("i", p[:n])
+	}
+	return n, err
+}
+
+func (s *recordingSession) Write(p []byte) (int, error) {
+	n, err := s.Session.Write(p)
+	if n > 0 {
+		s.writeEvent("o", p[:n])
+	}
+	return n, err
+}
+
+// Pty overrides ssh.Session.Pty to hand back a tee'd window-change channel
+// instead of the original one: resizeCh was already set up to both forward
+// every event downstream and record it, so the only thing left to do here is
+// return it instead of calling through to the embedded Session (which would
+// hand out the untapped channel, a second consumer racing ours for events).
+func (s *recordingSession) Pty() (ssh.Pty, <-chan ssh.Window, bool) {
+	if !s.isPty {
+		return s.Session.Pty()
+	}
+	return s.pty, s.resizeCh, true
+}
+
+// teeResize forwards every window event on winCh to out (so the downstream
+// handler still sees them) while also recording a resize marker for each.
+func (s *recordingSession) teeResize(winCh <-chan ssh.Window) <-chan ssh.Window {
+	out := make(chan ssh.Window)
+	go func() {
+		defer close(out)
+		for win := range winCh {
+			s.writeResize(win)
+			out <- win
+		}
+	}()
+	return out
+}
+
+// writeEvent appends one asciinema v2 event line ([elapsed_seconds, kind,
+// data]) to the recording.
+func (s *recordingSession) writeEvent(kind string, data []byte) {
+	line, err := json.Marshal([]any{time.Since(s.start).Seconds(), kind, string(data)})
+	if err != nil {
+		return
+	}
+	s.writeLine(line)
+}
+
+// writeResize appends a ["resize", "rows cols"] marker so a player can
+// replay terminal size changes mid-session.
+func (s *recordingSession) writeResize(win ssh.Window) {
+	line, err := json.Marshal([]any{"resize", fmt.Sprintf("%d %d", win.Height, win.Width)})
+	if err != nil {
+		return
+	}
+	s.writeLine(line)
+}
+
+func (s *recordingSession) writeLine(line []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.w.Write(append(line, '\n'))
+}
@@ -0,0 +1,407 @@
+package recorder
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// s3DefaultPartSize is S3's minimum multipart upload part size (except for
+// the last part, which may be smaller).
+const s3DefaultPartSize = 5 * 1024 * 1024
+
+// S3Sink streams each session's recording to an S3-compatible bucket via a
+// multipart upload, uploading one part per PartSize bytes buffered and
+// completing the upload (plus a .meta.json sidecar object) when the session
+// closes. Requests are signed by hand (SigV4) rather than pulling in the AWS
+// SDK, matching how the rest of this package avoids third-party
+// dependencies for things the standard library can already do.
+type S3Sink struct {
+	Bucket          string
+	Region          string
+	Prefix          string
+	Endpoint        string // defaults to https://s3.<Region>.amazonaws.com; set for S3-compatible providers (MinIO, R2, ...)
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	PartSize        int64
+	HTTPClient      *http.Client
+}
+
+var _ SessionSink = (*S3Sink)(nil)
+
+// NewS3SinkFromEnv builds an S3Sink for bucket/region/prefix, reading AWS
+// credentials from AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY /
+// AWS_SESSION_TOKEN for any of cfg's credential fields left unset.
+func NewS3SinkFromEnv(cfg S3Sink) (*S3Sink, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("S3 sink requires a bucket")
+	}
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("S3 sink requires a region")
+	}
+	if cfg.AccessKeyID == "" {
+		cfg.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if cfg.SecretAccessKey == "" {
+		cfg.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	if cfg.SessionToken == "" {
+		cfg.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3 sink requires credentials (AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY)")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	if cfg.PartSize == 0 {
+		cfg.PartSize = s3DefaultPartSize
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	sink := cfg
+	return &sink, nil
+}
+
+func (s *S3Sink) key(meta Meta) string {
+	key := meta.SessionID + ".cast"
+	if s.Prefix != "" {
+		key = strings.TrimSuffix(s.Prefix, "/") + "/" + key
+	}
+	return key
+}
+
+// Open implements SessionSink.
+func (s *S3Sink) Open(meta Meta) (RecordingWriter, error) {
+	key := s.key(meta)
+
+	uploadID, err := s.createMultipartUpload(key)
+	if err != nil {
+		return nil, fmt.Errorf("start multipart upload for %s: %w", key, err)
+	}
+
+	line, err := json.Marshal(castHeader{
+		Version:    2,
+		Width:      meta.Width,
+		Height:     meta.Height,
+		Timestamp:  meta.Timestamp,
+		Env:        meta.Env,
+		User:       meta.User,
+		RemoteAddr: meta.RemoteAddr,
+		RawCommand: meta.RawCommand,
+		SessionID:  meta.SessionID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal cast header: %w", err)
+	}
+
+	rec := &s3Recording{sink: s, meta: meta, key: key, uploadID: uploadID}
+	rec.buf = append(line, '\n')
+	return rec, nil
+}
+
+// s3Recording is the RecordingWriter behind S3Sink.Open: it buffers event
+// lines and uploads them as multipart parts once PartSize bytes accumulate,
+// completing the upload (and uploading a .meta.json sidecar object) on
+// Close.
+type s3Recording struct {
+	sink     *S3Sink
+	meta     Meta
+	key      string
+	uploadID string
+
+	mu      sync.Mutex
+	buf     []byte
+	partNum int
+	etags   []string
+}
+
+// Write implements RecordingWriter.
+func (r *s3Recording) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.buf = append(r.buf, p...)
+	for int64(len(r.buf)) >= r.sink.PartSize {
+		if err := r.uploadPart(r.buf[:r.sink.PartSize]); err != nil {
+			return 0, err
+		}
+		r.buf = append([]byte(nil), r.buf[r.sink.PartSize:]...)
+	}
+	return len(p), nil
+}
+
+func (r *s3Recording) uploadPart(data []byte) error {
+	r.partNum++
+	etag, err := r.sink.uploadPart(r.key, r.uploadID, r.partNum, data)
+	if err != nil {
+		return fmt.Errorf("upload part %d of %s: %w", r.partNum, r.key, err)
+	}
+	r.etags = append(r.etags, etag)
+	return nil
+}
+
+// Close implements RecordingWriter.
+func (r *s3Recording) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.buf) > 0 || r.partNum == 0 {
+		if err := r.uploadPart(r.buf); err != nil {
+			return err
+		}
+		r.buf = nil
+	}
+
+	if err := r.sink.completeMultipartUpload(r.key, r.uploadID, r.etags); err != nil {
+		return fmt.Errorf("complete multipart upload for %s: %w", r.key, err)
+	}
+
+	metaData, err := json.MarshalIndent(sidecarMeta{Meta: r.meta}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal recording metadata: %w", err)
+	}
+	metaKey := strings.TrimSuffix(r.key, ".cast") + ".meta.json"
+	if err := r.sink.putObject(metaKey, metaData); err != nil {
+		return fmt.Errorf("upload recording metadata: %w", err)
+	}
+	return nil
+}
+
+// --- S3 REST calls -------------------------------------------------------
+
+type initiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type completeMultipartUploadRequest struct {
+	XMLName xml.Name                      `xml:"CompleteMultipartUpload"`
+	Parts   []completeMultipartUploadPart `xml:"Part"`
+}
+
+type completeMultipartUploadPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+func (s *S3Sink) createMultipartUpload(key string) (string, error) {
+	resp, err := s.do("POST", key, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result initiateMultipartUploadResult
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("decode InitiateMultipartUpload response: %w", err)
+	}
+	return result.UploadID, nil
+}
+
+func (s *S3Sink) uploadPart(key, uploadID string, partNumber int, data []byte) (string, error) {
+	query := url.Values{
+		"partNumber": {strconv.Itoa(partNumber)},
+		"uploadId":   {uploadID},
+	}
+	resp, err := s.do("PUT", key, query, data)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return "", fmt.Errorf("upload part response missing ETag")
+	}
+	return etag, nil
+}
+
+func (s *S3Sink) completeMultipartUpload(key, uploadID string, etags []string) error {
+	req := completeMultipartUploadRequest{}
+	for i, etag := range etags {
+		req.Parts = append(req.Parts, completeMultipartUploadPart{PartNumber: i + 1, ETag: etag})
+	}
+	body, err := xml.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("marshal CompleteMultipartUpload request: %w", err)
+	}
+
+	resp, err := s.do("POST", key, url.Values{"uploadId": {uploadID}}, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+func (s *S3Sink) putObject(key string, data []byte) error {
+	resp, err := s.do("PUT", key, nil, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// do signs and executes an S3 request for key, returning the response if
+// the status was 2xx.
+func (s *S3Sink) do(method, key string, query url.Values, body []byte) (*http.Response, error) {
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parse S3 endpoint %q: %w", s.Endpoint, err)
+	}
+
+	reqURL := *endpoint
+	reqURL.Path = "/" + s.Bucket + "/" + key
+	if len(query) > 0 {
+		reqURL.RawQuery = query.Encode()
+	}
+
+	req, err := http.NewRequest(method, reqURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+
+	s.sign(req, body)
+
+	resp, err := s.client().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, string(respBody))
+	}
+	return resp, nil
+}
+
+func (s *S3Sink) client() *http.Client {
+	if s.HTTPClient != nil {
+		return s.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// sign adds SigV4 Authorization, x-amz-date, x-amz-content-sha256 (and
+// x-amz-security-token, for temporary credentials) headers to req.
+func (s *S3Sink) sign(req *http.Request, body []byte) {
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	if s.SessionToken != "" {
+		req.Header.Set("x-amz-security-token", s.SessionToken)
+	}
+	req.Header.Set("Host", req.URL.Host)
+
+	signedHeaders, canonicalHeaders := canonicalizeHeaders(req.Header, []string{"host", "x-amz-content-sha256", "x-amz-date", "x-amz-security-token"})
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncodePath(req.URL.Path),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	credentialScope := fmt.Sprintf("%s/%s/s3/aws4_request", dateStamp, s.Region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		credentialScope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := s.signingKey(dateStamp)
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKeyID, credentialScope, signedHeaders, signature,
+	))
+}
+
+func (s *S3Sink) signingKey(dateStamp string) []byte {
+	kDate := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), dateStamp)
+	kRegion := hmacSHA256(kDate, s.Region)
+	kService := hmacSHA256(kRegion, "s3")
+	return hmacSHA256(kService, "aws4_request")
+}
+
+// canonicalizeHeaders builds SigV4's SignedHeaders and CanonicalHeaders from
+// whichever of wanted are actually set on h.
+func canonicalizeHeaders(h http.Header, wanted []string) (signedHeaders, canonicalHeaders string) {
+	var names []string
+	for _, name := range wanted {
+		if h.Get(name) != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var canon strings.Builder
+	for _, name := range names {
+		canon.WriteString(name)
+		canon.WriteByte(':')
+		canon.WriteString(strings.TrimSpace(h.Get(name)))
+		canon.WriteByte('\n')
+	}
+	return strings.Join(names, ";"), canon.String()
+}
+
+// uriEncodePath percent-encodes each path segment the way SigV4 requires
+// (RFC 3986 unreserved characters plus "/" left alone), without touching the
+// "/" separators themselves.
+func uriEncodePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		segments[i] = uriEncodeSegment(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+func uriEncodeSegment(seg string) string {
+	var b strings.Builder
+	for i := 0; i < len(seg); i++ {
+		c := seg[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
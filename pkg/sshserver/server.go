@@ -11,17 +11,22 @@ import (
 	"os/exec"
 	"os/user"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/charmbracelet/ssh"
 	"github.com/charmbracelet/wish"
 	"github.com/creack/pty"
 	gossh "golang.org/x/crypto/ssh"
+
+	sftppkg "github.com/vercel-eddie/bridge/pkg/sftp"
+	"github.com/vercel-eddie/bridge/pkg/sshserver/recorder"
 )
 
 type Server struct {
-	srv  *ssh.Server
-	addr string
+	srv    *ssh.Server
+	addr   string
+	logger *slog.Logger
 }
 
 type Config struct {
@@ -33,6 +38,15 @@ type Config struct {
 	AgentForwarding bool
 	SessionHandler  ssh.Handler
 	Middleware      []wish.Middleware
+
+	// Recorder, when set, wraps SessionHandler in asciinema v2 recording,
+	// inside the rest of Middleware, so it records the session as
+	// SessionHandler itself sees it.
+	Recorder *recorder.Recorder
+
+	// Logger carries fixed contextual attributes onto every log line this
+	// server emits. Defaults to slog.Default() when nil.
+	Logger *slog.Logger
 }
 
 func (c Config) Validate() error {
@@ -82,6 +96,11 @@ func New(cfg Config) (*Server, error) {
 
 	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
 
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
 	// Create basic server options
 	opts := []ssh.Option{
 		wish.WithAddress(addr),
@@ -94,8 +113,7 @@ func New(cfg Config) (*Server, error) {
 	}
 
 	// SFTP subsystem support (needed for mutagen file sync)
-	// Note: Disabled temporarily to debug chmod issue
-	// opts = append(opts, wish.WithSubsystem("sftp", sftpSubsystem))
+	opts = append(opts, wish.WithSubsystem("sftp", sftppkg.Subsystem(logger)))
 
 	// Create server first
 	srv, err := wish.NewServer(opts...)
@@ -117,6 +135,13 @@ func New(cfg Config) (*Server, error) {
 
 	// Note: SCP middleware removed - mutagen uses SFTP for file transfers
 
+	// Wrap the session handler in recording first, innermost, so it sees
+	// exactly the session bytes SessionHandler itself would have gotten
+	// rather than whatever cfg.Middleware below transforms them into.
+	if cfg.Recorder != nil {
+		handler = cfg.Recorder.Middleware()(handler)
+	}
+
 	// Add custom middleware if any
 	for i := len(cfg.Middleware) - 1; i >= 0; i-- {
 		handler = cfg.Middleware[i](handler)
@@ -125,7 +150,7 @@ func New(cfg Config) (*Server, error) {
 	// Add tracing middleware as the outermost layer
 	tracingHandler := handler
 	handler = func(s ssh.Session) {
-		slog.Info("SSH session",
+		logger.Info("SSH session",
 			"user", s.User(),
 			"command", s.Command(),
 			"raw_command", s.RawCommand(),
@@ -149,18 +174,19 @@ func New(cfg Config) (*Server, error) {
 	}
 
 	return &Server{
-		srv:  srv,
-		addr: addr,
+		srv:    srv,
+		addr:   addr,
+		logger: logger,
 	}, nil
 }
 
 func (s *Server) Start() error {
-	slog.Info("starting ssh server", "addr", s.addr)
+	s.logger.Info("starting ssh server", "addr", s.addr)
 	return s.srv.ListenAndServe()
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
-	slog.Info("shutting down ssh server")
+	s.logger.Info("shutting down ssh server")
 	return s.srv.Shutdown(ctx)
 }
 
@@ -194,6 +220,7 @@ func ShellHandler() ssh.Handler {
 
 			shell.Env = append(os.Environ(), fmt.Sprintf("TERM=%s", ptyReq.Term))
 			shell.Dir = currentUser.HomeDir
+			shell.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 
 			ptmx, err := pty.Start(shell)
 			if err != nil {
@@ -213,11 +240,18 @@ func ShellHandler() ssh.Handler {
 				}
 			}()
 
+			// Forward SIGINT/SIGTERM/etc. from the client to the whole
+			// process group, so Ctrl-C actually reaches the child instead of
+			// just closing the PTY.
+			done := make(chan struct{})
+			go forwardSignals(s, shell.Process.Pid, done)
+
 			// Copy data between session and PTY
 			go io.Copy(ptmx, s)
 			io.Copy(s, ptmx)
 
 			shell.Wait()
+			close(done)
 		} else {
 			// Non-interactive command execution
 			rawCmd := s.RawCommand()
@@ -255,12 +289,32 @@ func ShellHandler() ssh.Handler {
 			shell.Stderr = s.Stderr()
 			shell.Dir = currentUser.HomeDir
 			shell.Env = os.Environ()
+			shell.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 
 			slog.Info("SSH command executing", "dir", currentUser.HomeDir)
 
-			if err := shell.Run(); err != nil {
+			if err := shell.Start(); err != nil {
+				slog.Error("failed to start command", "error", err)
+				s.Exit(1)
+				return
+			}
+
+			// Forward SIGINT/SIGTERM/etc. from the client to the whole
+			// process group, same as the PTY branch above.
+			done := make(chan struct{})
+			go forwardSignals(s, shell.Process.Pid, done)
+
+			err := shell.Wait()
+			close(done)
+
+			if err != nil {
 				var exitErr *exec.ExitError
 				if errors.As(err, &exitErr) {
+					if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+						slog.Info("SSH command terminated by signal", "signal", status.Signal())
+						s.Exit(128 + int(status.Signal()))
+						return
+					}
 					slog.Info("SSH command exited", "exit_code", exitErr.ExitCode())
 					s.Exit(exitErr.ExitCode())
 					return
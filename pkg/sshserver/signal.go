@@ -0,0 +1,54 @@
+package sshserver
+
+import (
+	"syscall"
+
+	"github.com/charmbracelet/ssh"
+)
+
+// unixSignals maps the SSH signal names defined by RFC 4254 section 6.10
+// (as exposed by ssh.Signal) to the syscall.Signal values we actually
+// deliver to the child process group.
+var unixSignals = map[ssh.Signal]syscall.Signal{
+	ssh.SIGABRT: syscall.SIGABRT,
+	ssh.SIGALRM: syscall.SIGALRM,
+	ssh.SIGFPE:  syscall.SIGFPE,
+	ssh.SIGHUP:  syscall.SIGHUP,
+	ssh.SIGILL:  syscall.SIGILL,
+	ssh.SIGINT:  syscall.SIGINT,
+	ssh.SIGKILL: syscall.SIGKILL,
+	ssh.SIGPIPE: syscall.SIGPIPE,
+	ssh.SIGQUIT: syscall.SIGQUIT,
+	ssh.SIGSEGV: syscall.SIGSEGV,
+	ssh.SIGTERM: syscall.SIGTERM,
+	ssh.SIGUSR1: syscall.SIGUSR1,
+	ssh.SIGUSR2: syscall.SIGUSR2,
+}
+
+// forwardSignals relays every signal s's client sends on sig to pid's entire
+// process group (so pipelines like `make -j4` get the signal, not just the
+// immediate child) until done is closed. pid's process must have been
+// started with SysProcAttr.Setpgid so it leads its own group.
+func forwardSignals(s ssh.Session, pid int, done <-chan struct{}) {
+	sigCh := make(chan ssh.Signal, 1)
+	s.Signals(sigCh)
+	defer s.Signals(nil)
+
+	pgid, err := syscall.Getpgid(pid)
+	if err != nil {
+		pgid = pid
+	}
+
+	for {
+		select {
+		case sig := <-sigCh:
+			unixSig, ok := unixSignals[sig]
+			if !ok {
+				continue
+			}
+			syscall.Kill(-pgid, unixSig)
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vercel-eddie/bridge/pkg/mutagen"
+)
+
+// MutagenEngine syncs via the mutagen daemon, installing the mutagen binary
+// on first Start if it isn't already present.
+type MutagenEngine struct {
+	// Ignore lists additional gitignore-style patterns to ignore
+	// (--mutagen-ignore), on top of Config.IgnoreVCS.
+	Ignore []string
+}
+
+var _ Engine = (*MutagenEngine)(nil)
+
+// Start implements Engine.
+func (e *MutagenEngine) Start(ctx context.Context, cfg Config) (Session, error) {
+	if err := mutagen.Install(); err != nil {
+		return nil, fmt.Errorf("install mutagen: %w", err)
+	}
+
+	client, err := mutagen.NewClient()
+	if err != nil {
+		return nil, fmt.Errorf("create mutagen client: %w", err)
+	}
+
+	if err := client.CreateSyncSession(mutagen.SyncConfig{
+		Name:      cfg.Name,
+		Source:    cfg.Source,
+		Target:    cfg.Target,
+		IgnoreVCS: cfg.IgnoreVCS,
+		Ignore:    e.Ignore,
+		SyncMode:  "two-way-resolved",
+	}); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("create mutagen sync session %q: %w", cfg.Name, err)
+	}
+
+	return &mutagenSession{client: client, name: cfg.Name}, nil
+}
+
+type mutagenSession struct {
+	client *mutagen.Client
+	name   string
+}
+
+func (s *mutagenSession) Terminate() error {
+	defer s.client.Close()
+	return s.client.TerminateSyncSession(s.name)
+}
+
+func (s *mutagenSession) Status() (string, error) {
+	status, err := s.client.GetSyncStatus(s.name)
+	if err != nil {
+		return "", err
+	}
+	return status.Status, nil
+}
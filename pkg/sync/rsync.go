@@ -0,0 +1,255 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const rsyncDebounce = 500 * time.Millisecond
+
+// RsyncEngine syncs via periodic (and change-triggered) `rsync -az --delete`
+// runs over the SSH proxy intercept already stands up, for environments
+// where installing mutagen isn't an option.
+type RsyncEngine struct {
+	// Interval reruns rsync even without a detected change, as a backstop
+	// against missed fsnotify events. Defaults to 30s.
+	Interval time.Duration
+
+	// ExtraArgs are appended to the rsync invocation (--rsync-args).
+	ExtraArgs []string
+
+	// SSHConfigPath is passed to rsync via `-e "ssh -F <path>"`. Defaults to
+	// ~/.bridge/ssh_config, the file the SSH proxy's intercept already
+	// writes aliases into.
+	SSHConfigPath string
+
+	Logger *slog.Logger
+}
+
+var _ Engine = (*RsyncEngine)(nil)
+
+// Start implements Engine. It runs one synchronous rsync pass before
+// returning, then keeps syncing in the background: every Interval, and
+// within rsyncDebounce of any fsnotify change under cfg.Source.
+func (e *RsyncEngine) Start(ctx context.Context, cfg Config) (Session, error) {
+	if _, err := exec.LookPath("rsync"); err != nil {
+		return nil, fmt.Errorf("rsync not found on PATH: %w", err)
+	}
+
+	logger := e.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	interval := e.Interval
+	if interval == 0 {
+		interval = 30 * time.Second
+	}
+
+	sshConfigPath := e.SSHConfigPath
+	if sshConfigPath == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			sshConfigPath = filepath.Join(home, ".bridge", "ssh_config")
+		}
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("create fsnotify watcher: %w", err)
+	}
+	if err := watchRecursive(watcher, cfg.Source); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("watch sync source: %w", err)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	sess := &rsyncSession{
+		cfg:           cfg,
+		extraArgs:     e.ExtraArgs,
+		sshConfigPath: sshConfigPath,
+		logger:        logger,
+		watcher:       watcher,
+		cancel:        cancel,
+		done:          make(chan struct{}),
+	}
+
+	if err := sess.run(runCtx); err != nil {
+		logger.Warn("initial rsync failed", "name", cfg.Name, "error", err)
+		sess.recordErr(err)
+	}
+
+	go sess.loop(runCtx, interval)
+
+	return sess, nil
+}
+
+// watchRecursive adds dir and every directory beneath it to watcher:
+// fsnotify only watches the directories it's explicitly told about, not
+// their descendants.
+func watchRecursive(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
+
+// rsyncSession is the Session RsyncEngine.Start returns.
+type rsyncSession struct {
+	cfg           Config
+	extraArgs     []string
+	sshConfigPath string
+	logger        *slog.Logger
+	watcher       *fsnotify.Watcher
+	cancel        context.CancelFunc
+	done          chan struct{}
+
+	mu      sync.Mutex
+	lastErr error
+	running bool
+}
+
+// loop rereads cfg.Source on a fixed interval and within rsyncDebounce of
+// any fsnotify event, until ctx is canceled.
+func (s *rsyncSession) loop(ctx context.Context, interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var debounce *time.Timer
+	debounceC := func() <-chan time.Time {
+		if debounce == nil {
+			return nil
+		}
+		return debounce.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event, ok := <-s.watcher.Events:
+			if !ok {
+				return
+			}
+			// New directories need their own watch, same as the initial
+			// recursive walk.
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					s.watcher.Add(event.Name)
+				}
+			}
+			if debounce == nil {
+				debounce = time.NewTimer(rsyncDebounce)
+			} else {
+				debounce.Reset(rsyncDebounce)
+			}
+
+		case err, ok := <-s.watcher.Errors:
+			if !ok {
+				return
+			}
+			s.logger.Warn("sync watcher error", "name", s.cfg.Name, "error", err)
+
+		case <-debounceC():
+			if err := s.run(ctx); err != nil {
+				s.logger.Warn("rsync run failed", "name", s.cfg.Name, "error", err)
+				s.recordErr(err)
+			}
+			ticker.Reset(interval)
+
+		case <-ticker.C:
+			if err := s.run(ctx); err != nil {
+				s.logger.Warn("rsync run failed", "name", s.cfg.Name, "error", err)
+				s.recordErr(err)
+			}
+		}
+	}
+}
+
+// run executes one rsync pass from cfg.Source to cfg.Target.
+func (s *rsyncSession) run(ctx context.Context) error {
+	s.mu.Lock()
+	s.running = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	sshCmd := "ssh"
+	if s.sshConfigPath != "" {
+		sshCmd = fmt.Sprintf("ssh -F %s", s.sshConfigPath)
+	}
+
+	args := []string{"-az", "--delete", "-e", sshCmd}
+	if s.cfg.IgnoreVCS {
+		args = append(args, "--exclude", ".git")
+	}
+	args = append(args, s.extraArgs...)
+
+	source := s.cfg.Source
+	if !strings.HasSuffix(source, string(filepath.Separator)) {
+		source += string(filepath.Separator)
+	}
+	args = append(args, source, s.cfg.Target)
+
+	cmd := exec.CommandContext(ctx, "rsync", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		s.recordErr(err)
+		return fmt.Errorf("rsync: %w: %s", err, output)
+	}
+
+	s.mu.Lock()
+	s.lastErr = nil
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *rsyncSession) recordErr(err error) {
+	s.mu.Lock()
+	s.lastErr = err
+	s.mu.Unlock()
+}
+
+// Terminate implements Session.
+func (s *rsyncSession) Terminate() error {
+	s.cancel()
+	<-s.done
+	return s.watcher.Close()
+}
+
+// Status implements Session.
+func (s *rsyncSession) Status() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lastErr != nil {
+		return "error", s.lastErr
+	}
+	if s.running {
+		return "syncing", nil
+	}
+	return "watching", nil
+}
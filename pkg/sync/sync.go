@@ -0,0 +1,39 @@
+// Package sync defines the pluggable file-sync backend `bridge intercept`
+// uses to keep a devcontainer's working tree mirrored onto the sandbox, plus
+// the two built-in implementations: MutagenEngine (the daemon-based sync
+// bridge has always used) and RsyncEngine (a plain rsync-over-SSH fallback
+// for environments where installing mutagen isn't an option).
+package sync
+
+import "context"
+
+// Config describes one sync relationship: a local source directory and a
+// remote target reachable over the SSH proxy intercept already stands up.
+type Config struct {
+	// Name identifies the sync session for status/termination.
+	Name string
+
+	// Source is the local directory to sync from.
+	Source string
+
+	// Target is the remote side, e.g. "vercel-sandbox@host:/vercel/sandbox".
+	Target string
+
+	// IgnoreVCS skips version control directories (.git, etc).
+	IgnoreVCS bool
+}
+
+// Session is a running sync started by Engine.Start.
+type Session interface {
+	// Terminate stops the sync and releases any resources it holds.
+	Terminate() error
+
+	// Status reports the sync's current state for diagnostics/logging.
+	Status() (string, error)
+}
+
+// Engine starts sync sessions. MutagenEngine and RsyncEngine are the
+// built-in implementations.
+type Engine interface {
+	Start(ctx context.Context, cfg Config) (Session, error)
+}
@@ -1,6 +1,7 @@
 package tunnel
 
 import (
+	"errors"
 	"io"
 	"log/slog"
 	"net"
@@ -10,6 +11,29 @@ import (
 	"time"
 )
 
+// initialWindowSize is the number of bytes of unacknowledged data a Conn may
+// have in flight before Write blocks waiting for the peer to report it has
+// drained its buffer; the same size as yamux's default stream window.
+const initialWindowSize = 256 * 1024
+
+// maxTunnelWriteChunk caps how much of a single Write call goes out in one
+// sendDataWithAddresses call, so a large write doesn't have to wait for the
+// full send window before making any progress.
+const maxTunnelWriteChunk = 32 * 1024
+
+// reconnectRetryInterval is how long Write waits before retrying a send
+// after ErrReconnecting, rather than busy-looping while the client
+// re-establishes its connection to the dispatcher.
+const reconnectRetryInterval = 100 * time.Millisecond
+
+// ErrReconnecting is returned by Client's send path to indicate the
+// underlying transport is momentarily down but expected to recover (e.g.
+// the client is re-dialing the dispatcher after a dropped connection).
+// Write treats it as transient and retries until the write deadline
+// elapses, instead of failing the caller for what's usually a sub-second
+// blip.
+var ErrReconnecting = errors.New("tunnel: connection is reconnecting")
+
 // Conn represents a single logical connection multiplexed over the tunnel.
 type Conn struct {
 	id        string
@@ -19,55 +43,173 @@ type Conn struct {
 	readBuf   chan []byte
 	closed    atomic.Bool
 	closeOnce sync.Once
+	closeCh   chan struct{}
+
+	mu            sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+	recvWindow    int64 // bytes read since the last WINDOW_UPDATE was sent to the peer
+
+	sendWindow    atomic.Int64  // credit available to Write, replenished by GrantCredit
+	windowUpdated chan struct{} // signaled (non-blocking) whenever sendWindow increases
+
+	logger *slog.Logger
 }
 
-// newConn creates a new tunnel connection.
+// newConn creates a new tunnel connection. The returned Conn's logger
+// carries client.Logger (defaulting to slog.Default() when unset) plus this
+// connection's id/source/dest, so every log line it emits is already
+// scoped to the connection that produced it.
 func newConn(id, source, dest string, client *Client) *Conn {
-	return &Conn{
-		id:      id,
-		source:  source,
-		dest:    dest,
-		client:  client,
-		readBuf: make(chan []byte, 100),
+	c := &Conn{
+		id:            id,
+		source:        source,
+		dest:          dest,
+		client:        client,
+		readBuf:       make(chan []byte, 100),
+		closeCh:       make(chan struct{}),
+		windowUpdated: make(chan struct{}, 1),
+		logger:        loggerOrDefault(client.Logger).With("connection_id", id, "source", source, "dest", dest),
+	}
+	c.sendWindow.Store(initialWindowSize)
+	return c
+}
+
+// loggerOrDefault returns l, or slog.Default() if l is nil.
+func loggerOrDefault(l *slog.Logger) *slog.Logger {
+	if l != nil {
+		return l
 	}
+	return slog.Default()
 }
 
-// Read reads data from the tunnel connection.
+// GrantCredit increases the connection's send window by n bytes. The demux
+// read loop calls this when a WINDOW_UPDATE frame arrives for this
+// connection's ID, unblocking any Write waiting for room.
+func (c *Conn) GrantCredit(n int64) {
+	if n <= 0 {
+		return
+	}
+	c.sendWindow.Add(n)
+	select {
+	case c.windowUpdated <- struct{}{}:
+	default:
+	}
+}
+
+// Read reads data from the tunnel connection, returning a timeoutError if
+// the read deadline set by SetReadDeadline/SetDeadline elapses first.
 func (c *Conn) Read(b []byte) (int, error) {
 	if c.closed.Load() {
 		return 0, io.EOF
 	}
 
-	data, ok := <-c.readBuf
-	if !ok {
+	timeoutCh, stop := c.timerFor(c.readDeadlineValue())
+	if stop != nil {
+		defer stop()
+	}
+
+	select {
+	case data, ok := <-c.readBuf:
+		if !ok {
+			return 0, io.EOF
+		}
+		n := copy(b, data)
+		c.grantReadCredit(n)
+		return n, nil
+	case <-timeoutCh:
+		return 0, timeoutError{}
+	case <-c.closeCh:
 		return 0, io.EOF
 	}
+}
 
-	n := copy(b, data)
-	return n, nil
+// grantReadCredit accumulates bytes consumed by Read and, once they cross
+// half of initialWindowSize, sends a WINDOW_UPDATE back to the peer so its
+// Write can keep making progress — the same "ack in batches, not per-byte"
+// approach HTTP/2 and yamux use to avoid a WINDOW_UPDATE storm.
+func (c *Conn) grantReadCredit(n int) {
+	c.mu.Lock()
+	c.recvWindow += int64(n)
+	var credit int64
+	if c.recvWindow >= initialWindowSize/2 {
+		credit = c.recvWindow
+		c.recvWindow = 0
+	}
+	c.mu.Unlock()
+
+	if credit > 0 {
+		if err := c.client.sendWindowUpdate(c.id, credit); err != nil {
+			c.logger.Debug("Failed to send window update", "error", err)
+		}
+	}
 }
 
-// Write writes data to the tunnel connection.
-// Every write includes source/dest addresses because the dispatcher is a
-// serverless function that may be replaced between writes.
+// Write writes data to the tunnel connection, blocking until the peer has
+// granted enough send-window credit (see GrantCredit) or the write deadline
+// elapses. Every write includes source/dest addresses because the
+// dispatcher is a serverless function that may be replaced between writes.
 func (c *Conn) Write(b []byte) (int, error) {
 	if c.closed.Load() {
 		return 0, io.ErrClosedPipe
 	}
 
-	if err := c.client.sendDataWithAddresses(c.id, b, c.source, c.dest); err != nil {
-		return 0, err
+	timeoutCh, stop := c.timerFor(c.writeDeadlineValue())
+	if stop != nil {
+		defer stop()
 	}
 
-	return len(b), nil
+	written := 0
+	for written < len(b) {
+		chunk := b[written:]
+		if len(chunk) > maxTunnelWriteChunk {
+			chunk = chunk[:maxTunnelWriteChunk]
+		}
+
+		for c.sendWindow.Load() <= 0 {
+			select {
+			case <-c.windowUpdated:
+			case <-timeoutCh:
+				return written, timeoutError{}
+			case <-c.closeCh:
+				return written, io.ErrClosedPipe
+			}
+		}
+
+		if avail := c.sendWindow.Load(); int64(len(chunk)) > avail {
+			chunk = chunk[:avail]
+		}
+
+		for {
+			err := c.client.sendDataWithAddresses(c.id, chunk, c.source, c.dest)
+			if err == nil {
+				break
+			}
+			if !errors.Is(err, ErrReconnecting) {
+				return written, err
+			}
+			select {
+			case <-time.After(reconnectRetryInterval):
+			case <-timeoutCh:
+				return written, timeoutError{}
+			case <-c.closeCh:
+				return written, io.ErrClosedPipe
+			}
+		}
+		c.sendWindow.Add(-int64(len(chunk)))
+		written += len(chunk)
+	}
+
+	return written, nil
 }
 
 // Close closes the tunnel connection.
 func (c *Conn) Close() error {
 	c.closeOnce.Do(func() {
 		c.closed.Store(true)
+		close(c.closeCh)
 		if err := c.client.sendClose(c.id); err != nil {
-			slog.Debug("Failed to send close message", "connection_id", c.id, "error", err)
+			c.logger.Debug("Failed to send close message", "error", err)
 		}
 		close(c.readBuf)
 	})
@@ -93,15 +235,64 @@ func (c *Conn) RemoteAddr() net.Addr {
 
 // SetDeadline sets the read and write deadlines.
 func (c *Conn) SetDeadline(t time.Time) error {
-	return nil // Not implemented for tunnel connections
+	c.mu.Lock()
+	c.readDeadline = t
+	c.writeDeadline = t
+	c.mu.Unlock()
+	return nil
 }
 
 // SetReadDeadline sets the read deadline.
 func (c *Conn) SetReadDeadline(t time.Time) error {
-	return nil // Not implemented for tunnel connections
+	c.mu.Lock()
+	c.readDeadline = t
+	c.mu.Unlock()
+	return nil
 }
 
 // SetWriteDeadline sets the write deadline.
 func (c *Conn) SetWriteDeadline(t time.Time) error {
-	return nil // Not implemented for tunnel connections
+	c.mu.Lock()
+	c.writeDeadline = t
+	c.mu.Unlock()
+	return nil
 }
+
+func (c *Conn) readDeadlineValue() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.readDeadline
+}
+
+func (c *Conn) writeDeadlineValue() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.writeDeadline
+}
+
+// timerFor returns a channel that fires when deadline elapses, along with a
+// stop function to release the timer. Both are nil/no-op when deadline is
+// the zero value, meaning no deadline is set.
+func (c *Conn) timerFor(deadline time.Time) (<-chan time.Time, func()) {
+	if deadline.IsZero() {
+		return nil, nil
+	}
+	d := time.Until(deadline)
+	if d <= 0 {
+		fired := make(chan time.Time, 1)
+		fired <- time.Now()
+		return fired, func() {}
+	}
+	timer := time.NewTimer(d)
+	return timer.C, func() { timer.Stop() }
+}
+
+// timeoutError implements net.Error for deadline expiry on Conn and
+// plumbing.TunnelConn.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "tunnel: i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+var _ net.Error = timeoutError{}
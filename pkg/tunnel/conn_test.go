@@ -0,0 +1,117 @@
+package tunnel
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+// These tests exercise Conn's deadline and credit-based backpressure logic
+// directly against zero-value/partially-populated Conns rather than through
+// newConn, since Client (the type behind Conn.client) isn't part of this
+// package — only the paths that never reach c.client are covered here.
+
+func TestConnTimerForZeroDeadlineNeverFires(t *testing.T) {
+	c := &Conn{}
+
+	ch, stop := c.timerFor(time.Time{})
+	if ch != nil || stop != nil {
+		t.Fatalf("timerFor(zero) = (%v, %v), want (nil, nil)", ch, stop)
+	}
+}
+
+func TestConnTimerForPastDeadlineFiresImmediately(t *testing.T) {
+	c := &Conn{}
+
+	ch, stop := c.timerFor(time.Now().Add(-time.Second))
+	defer stop()
+
+	select {
+	case <-ch:
+	default:
+		t.Fatal("timerFor(past) channel did not have a value ready")
+	}
+}
+
+func TestConnGrantCreditIncreasesSendWindowAndSignals(t *testing.T) {
+	c := &Conn{windowUpdated: make(chan struct{}, 1)}
+
+	c.GrantCredit(100)
+	if got := c.sendWindow.Load(); got != 100 {
+		t.Errorf("sendWindow = %d, want 100", got)
+	}
+	select {
+	case <-c.windowUpdated:
+	default:
+		t.Error("GrantCredit did not signal windowUpdated")
+	}
+
+	c.GrantCredit(50)
+	if got := c.sendWindow.Load(); got != 150 {
+		t.Errorf("sendWindow = %d, want 150", got)
+	}
+}
+
+func TestConnGrantCreditIgnoresNonPositive(t *testing.T) {
+	c := &Conn{windowUpdated: make(chan struct{}, 1)}
+
+	c.GrantCredit(0)
+	c.GrantCredit(-10)
+	if got := c.sendWindow.Load(); got != 0 {
+		t.Errorf("sendWindow = %d, want 0 after non-positive grants", got)
+	}
+	select {
+	case <-c.windowUpdated:
+		t.Error("GrantCredit(non-positive) signaled windowUpdated")
+	default:
+	}
+}
+
+func TestConnReadReturnsEOFOnClose(t *testing.T) {
+	c := &Conn{closeCh: make(chan struct{})}
+	close(c.closeCh)
+
+	_, err := c.Read(make([]byte, 16))
+	if err != io.EOF {
+		t.Errorf("Read() error = %v, want io.EOF", err)
+	}
+}
+
+func TestConnReadDeadlineExpires(t *testing.T) {
+	c := &Conn{closeCh: make(chan struct{})}
+	if err := c.SetReadDeadline(time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("SetReadDeadline() error: %v", err)
+	}
+
+	_, err := c.Read(make([]byte, 16))
+	te, ok := err.(timeoutError)
+	if !ok || !te.Timeout() {
+		t.Errorf("Read() error = %v, want a timeoutError", err)
+	}
+}
+
+func TestConnWriteDeadlineExpiresWhileSendWindowExhausted(t *testing.T) {
+	c := &Conn{closeCh: make(chan struct{}), windowUpdated: make(chan struct{}, 1)}
+	// sendWindow defaults to zero, so Write must block on windowUpdated
+	// until either credit arrives or the deadline (set in the past here)
+	// expires — it must never reach c.client in this state.
+	if err := c.SetWriteDeadline(time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("SetWriteDeadline() error: %v", err)
+	}
+
+	_, err := c.Write([]byte("hello"))
+	te, ok := err.(timeoutError)
+	if !ok || !te.Timeout() {
+		t.Errorf("Write() error = %v, want a timeoutError", err)
+	}
+}
+
+func TestConnWriteReturnsClosedPipeAfterClose(t *testing.T) {
+	c := &Conn{}
+	c.closed.Store(true)
+
+	_, err := c.Write([]byte("hello"))
+	if err != io.ErrClosedPipe {
+		t.Errorf("Write() error = %v, want io.ErrClosedPipe", err)
+	}
+}
@@ -17,5 +17,6 @@ type DNSResolveResult struct {
 type TunnelDialer interface {
 	ResolveDNS(ctx context.Context, hostname string) (*DNSResolveResult, error)
 	DialThroughTunnel(sourceAddr, destination string) (net.Conn, error)
+	DialUDPThroughTunnel(sourceAddr, destination string) (net.PacketConn, error)
 	Close() error
 }
@@ -0,0 +1,201 @@
+package tunnel
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxUDPDatagramSize is the largest payload WriteTo will frame: the most one
+// IPv4 UDP datagram can carry (65535 minus the 20-byte IP and 8-byte UDP
+// headers).
+const maxUDPDatagramSize = 65507
+
+// udpDatagram is one length-prefixed frame the demux read loop delivers to
+// a PacketConn's readBuf, tagged with the address it's reported as coming
+// from so ReadFrom has something to hand back even though every datagram on
+// a flow in practice comes from the single destination it was dialed to.
+type udpDatagram struct {
+	data []byte
+	addr *net.UDPAddr
+}
+
+// PacketConn represents a single logical UDP flow multiplexed over the
+// tunnel, the datagram-oriented counterpart to Conn. Datagrams are sent and
+// received as length-prefixed frames over the same multiplexed stream Conn
+// uses for TCP, tagged with this flow's id so the demux loop can route them
+// back here. Unlike Conn it carries no flow-control window: UDP has no
+// delivery guarantee to begin with, so a slow reader just drops datagrams
+// instead of applying backpressure to the sender.
+type PacketConn struct {
+	id     string
+	source string
+	dest   string
+	client *Client
+
+	readBuf   chan udpDatagram
+	closed    atomic.Bool
+	closeOnce sync.Once
+	closeCh   chan struct{}
+
+	mu            sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+
+	logger *slog.Logger
+}
+
+// newPacketConn creates a new tunnel UDP flow.
+func newPacketConn(id, source, dest string, client *Client) *PacketConn {
+	return &PacketConn{
+		id:      id,
+		source:  source,
+		dest:    dest,
+		client:  client,
+		readBuf: make(chan udpDatagram, 100),
+		closeCh: make(chan struct{}),
+		logger:  loggerOrDefault(client.Logger).With("connection_id", id, "source", source, "dest", dest),
+	}
+}
+
+// ReadFrom implements net.PacketConn.
+func (c *PacketConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	if c.closed.Load() {
+		return 0, nil, io.EOF
+	}
+
+	timeoutCh, stop := c.timerFor(c.readDeadlineValue())
+	if stop != nil {
+		defer stop()
+	}
+
+	select {
+	case dg, ok := <-c.readBuf:
+		if !ok {
+			return 0, nil, io.EOF
+		}
+		n := copy(b, dg.data)
+		return n, dg.addr, nil
+	case <-timeoutCh:
+		return 0, nil, timeoutError{}
+	case <-c.closeCh:
+		return 0, nil, io.EOF
+	}
+}
+
+// WriteTo implements net.PacketConn. addr is accepted for interface
+// compliance but ignored: every datagram written on this flow goes to the
+// single destination DialUDPThroughTunnel dialed, the same way a connected
+// UDP socket behaves.
+func (c *PacketConn) WriteTo(b []byte, _ net.Addr) (int, error) {
+	if c.closed.Load() {
+		return 0, io.ErrClosedPipe
+	}
+	if len(b) > maxUDPDatagramSize {
+		return 0, fmt.Errorf("tunnel: datagram of %d bytes exceeds %d byte limit", len(b), maxUDPDatagramSize)
+	}
+
+	timeoutCh, stop := c.timerFor(c.writeDeadlineValue())
+	if stop != nil {
+		defer stop()
+	}
+
+	for {
+		err := c.client.sendDatagramWithAddresses(c.id, b, c.source, c.dest)
+		if err == nil {
+			return len(b), nil
+		}
+		if !errors.Is(err, ErrReconnecting) {
+			return 0, err
+		}
+		select {
+		case <-time.After(reconnectRetryInterval):
+		case <-timeoutCh:
+			return 0, timeoutError{}
+		case <-c.closeCh:
+			return 0, io.ErrClosedPipe
+		}
+	}
+}
+
+// Close implements net.PacketConn.
+func (c *PacketConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.closed.Store(true)
+		close(c.closeCh)
+		if err := c.client.sendClose(c.id); err != nil {
+			c.logger.Debug("Failed to send close message", "error", err)
+		}
+		close(c.readBuf)
+	})
+	return nil
+}
+
+// LocalAddr implements net.PacketConn.
+func (c *PacketConn) LocalAddr() net.Addr {
+	host, portStr, _ := net.SplitHostPort(c.source)
+	port, _ := strconv.Atoi(portStr)
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	return &net.UDPAddr{IP: net.ParseIP(host), Port: port}
+}
+
+// SetDeadline implements net.PacketConn.
+func (c *PacketConn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.readDeadline = t
+	c.writeDeadline = t
+	c.mu.Unlock()
+	return nil
+}
+
+// SetReadDeadline implements net.PacketConn.
+func (c *PacketConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.readDeadline = t
+	c.mu.Unlock()
+	return nil
+}
+
+// SetWriteDeadline implements net.PacketConn.
+func (c *PacketConn) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.writeDeadline = t
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *PacketConn) readDeadlineValue() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.readDeadline
+}
+
+func (c *PacketConn) writeDeadlineValue() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.writeDeadline
+}
+
+func (c *PacketConn) timerFor(deadline time.Time) (<-chan time.Time, func()) {
+	if deadline.IsZero() {
+		return nil, nil
+	}
+	d := time.Until(deadline)
+	if d <= 0 {
+		fired := make(chan time.Time, 1)
+		fired <- time.Now()
+		return fired, func() {}
+	}
+	timer := time.NewTimer(d)
+	return timer.C, func() { timer.Stop() }
+}
+
+var _ net.PacketConn = (*PacketConn)(nil)